@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
+	_ "time/tzdata" // часовые пояса импорта нужны и там, где нет системной базы zoneinfo (Windows)
 
 	archiverService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/export/archiver"
 	minio2 "github.com/lifedaemon-kill/burovichok-desktop/internal/service/export/minioExporter"
@@ -16,12 +19,16 @@ import (
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/config"
 	converterService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/convertor"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/database"
+	filterService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/filter"
 	importerService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/importer"
+	periodsService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/periods"
+	projectService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/project"
+	resampleService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/resample"
 	uiService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/ui"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/storage/inmemory"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/storage/postgres"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/storage/sqlite"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/pressly/goose/v3"
 
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/logger"
@@ -58,19 +65,19 @@ func bootstrap(ctx context.Context) error {
 	}
 	zLog.Infow("Logger and config initialized successfully")
 
-	// 4. Подключение к PostgreSQL и миграции
-	pg, err := postgres.New(ctx, conf.DB, zLog)
+	// 4. Подключение к БД (PostgreSQL или локальный SQLite) и миграции
+	repo, sqlDB, dialect, err := openDatabase(ctx, conf.DB, zLog)
 	if err != nil {
-		zLog.Errorw("postgres.New", "error", err)
+		zLog.Errorw("openDatabase", "driver", conf.DB.Driver, "error", err)
 		return err
 	}
-	zLog.Infow("Database initialized successfully")
+	zLog.Infow("Database initialized successfully", "driver", conf.DB.Driver)
 
-	if err = goose.SetDialect("postgres"); err != nil {
+	if err = goose.SetDialect(dialect); err != nil {
 		zLog.Errorw("goose.SetDialect", "error", err)
 		return err
 	}
-	if err = goose.RunContext(ctx, "up", pg.GetSqlDB(), conf.DB.MigrationsPath); err != nil {
+	if err = goose.RunContext(ctx, "up", sqlDB, filepath.Join(conf.DB.MigrationsPath, conf.DB.Driver)); err != nil {
 		zLog.Errorw("goose.Run up", "error", err)
 		return err
 	}
@@ -80,12 +87,16 @@ func bootstrap(ctx context.Context) error {
 	exporter, err := minio2.NewClient(ctx, conf.Minio, zLog)
 
 	// 5. Создание сервиса работы с БД
-	dbService := database.NewService(pg, zLog)
+	dbService := database.NewService(repo, zLog)
 
 	// 6. Инициализация доменных сервисов
 	converter := converterService.NewService()
-	importer := importerService.NewService(converter)
+	importer := importerService.NewService(converter, conf.Importer)
 	chartSvc := chartService.NewService()
+	periodsSvc := periodsService.NewService()
+	resampleSvc := resampleService.NewService()
+	filterSvc := filterService.NewService()
+	projectSvc := projectService.NewService()
 	inMemoryStorage := inmemory.NewInMemoryBlocksStorage()
 
 	archiver := archiverService.NewService(zLog)
@@ -101,6 +112,10 @@ func bootstrap(ctx context.Context) error {
 		zLog,
 		importer,
 		converter,
+		periodsSvc,
+		resampleSvc,
+		filterSvc,
+		projectSvc,
 		inMemoryStorage,
 		dbService,
 		chartSvc,
@@ -115,10 +130,31 @@ func bootstrap(ctx context.Context) error {
 
 	// 8. Грейсфул-шатдаун
 	zLog.Infow("Application shutting down...")
-	if err = pg.DB.Close(); err != nil {
-		return errors.Wrap(err, "pg.DB.Close")
+	if err = sqlDB.Close(); err != nil {
+		return errors.Wrap(err, "sqlDB.Close")
 	}
 	zLog.Infow("Shutdown completed")
 
 	return nil
 }
+
+// openDatabase подключается к БД, выбранной в conf.Driver, и возвращает хранилище,
+// соединение для миграций и диалект goose.
+func openDatabase(ctx context.Context, conf config.DBConf, zLog logger.Logger) (database.Repository, *sql.DB, string, error) {
+	switch conf.Driver {
+	case config.DriverPostgres:
+		pg, err := postgres.New(ctx, conf, zLog)
+		if err != nil {
+			return nil, nil, "", errors.Wrap(err, "postgres.New")
+		}
+		return pg, pg.GetSqlDB(), "postgres", nil
+	case config.DriverSQLite:
+		lite, err := sqlite.New(ctx, conf, zLog)
+		if err != nil {
+			return nil, nil, "", errors.Wrap(err, "sqlite.New")
+		}
+		return lite, lite.GetSqlDB(), "sqlite3", nil
+	default:
+		return nil, nil, "", errors.Newf("unknown db driver %q, expected %q or %q", conf.Driver, config.DriverPostgres, config.DriverSQLite)
+	}
+}
@@ -14,11 +14,12 @@ const (
 )
 
 type Config struct {
-	ENV    string     `yaml:"env" env-required:"true"`
-	DB     DBConf     `yaml:"db" env-required:"true"`
-	Logger LoggerConf `yaml:"logger" env-required:"true"`
-	UI     UI         `yaml:"ui" env-required:"true"`
-	Minio  MinioConf  `yaml:"minio" env-required:"true"` 
+	ENV      string       `yaml:"env" env-required:"true"`
+	DB       DBConf       `yaml:"db" env-required:"true"`
+	Logger   LoggerConf   `yaml:"logger" env-required:"true"`
+	UI       UI           `yaml:"ui" env-required:"true"`
+	Minio    MinioConf    `yaml:"minio" env-required:"true"`
+	Importer ImporterConf `yaml:"importer"`
 }
 
 func Load(configPath string) (*Config, error) {
@@ -35,7 +36,16 @@ func Load(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Драйверы БД, между которыми переключается DBConf.Driver.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type DBConf struct {
+	// Driver — postgres (сервер, многопользовательский режим) или sqlite (локальный файл SQLitePath)
+	Driver          string `yaml:"driver" env-default:"postgres"`
+	SQLitePath      string `yaml:"sqlite_path" env-default:"data/burovichok.db"`
 	DSN             string `yaml:"dsn"`
 	MigrationsPath  string `yaml:"confmigration_path"`
 	MaxOpenConns    int    `yaml:"max_open_conns"`
@@ -48,7 +58,6 @@ type LoggerConf struct {
 	Env string `yaml:"env" env-required:"true"`
 }
 
-
 type MinioConf struct {
 	Endpoint   string `yaml:"endpoint" env-required:"true"`
 	AccessKey  string `yaml:"access_key" env-required:"true"`
@@ -57,10 +66,19 @@ type MinioConf struct {
 	BucketName string `yaml:"bucket_name" env-required:"true"`
 }
 
+// ImporterConf настраивает импорт: поиск колонок по заголовкам и потоковую запись в хранилище.
+type ImporterConf struct {
+	HeaderScanRows int                 `yaml:"header_scan_rows" env-default:"15"` // сколько строк просматривать в поисках шапки
+	MinMatchScore  float64             `yaml:"min_match_score" env-default:"0.6"` // минимальная степень совпадения заголовка
+	Aliases        map[string][]string `yaml:"aliases"`                           // дополнительные заголовки по имени поля модели
+	ChunkSize      int                 `yaml:"chunk_size" env-default:"10000"`    // строк в одной порции при записи в хранилище
+}
+
 type UI struct {
 	Name     string `yaml:"name" env-required:"true"`
 	Width    int    `yaml:"width" env-required:"true"`
 	Height   int    `yaml:"height" env-required:"true"`
 	IconPath string `yaml:"icon_path" env-required:"true"`
+	// AutosaveMinutes — период автосохранения сессии в файл восстановления; 0 отключает автосохранение
+	AutosaveMinutes int `yaml:"autosave_minutes" env-default:"5"`
 }
-
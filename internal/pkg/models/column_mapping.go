@@ -0,0 +1,29 @@
+package models
+
+// MappedColumn описывает, какая колонка файла сопоставлена полю модели.
+type MappedColumn struct {
+	Field  string  // имя поля модели, например PressureDepth
+	Tag    string  // ожидаемый заголовок из xlsx-тега
+	Header string  // заголовок, найденный в файле (склеенный из всех строк шапки)
+	Column string  // буквенное обозначение колонки: A, B, AA...
+	Index  int     // индекс колонки с нуля
+	Score  float64 // степень совпадения заголовка, от 0 до 1
+}
+
+// ColumnMapping — результат разбора шапки импортируемого файла.
+type ColumnMapping struct {
+	HeaderRows   []int          // номера строк (с единицы), признанных шапкой
+	DataStartRow int            // номер первой строки с данными
+	Columns      []MappedColumn // сопоставленные колонки в порядке полей модели
+	Ignored      []string       // заголовки колонок файла, которые не понадобились
+}
+
+// Index возвращает индекс колонки, сопоставленной полю field.
+func (m ColumnMapping) Index(field string) (int, bool) {
+	for _, c := range m.Columns {
+		if c.Field == field {
+			return c.Index, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,34 @@
+package models
+
+// DataBlocks — набор частей данных исследования. Хранилище сообщает им, что изменилось, а подписчик
+// выбирает им, о чём хочет знать.
+type DataBlocks uint
+
+const (
+	DataBlockOne   DataBlocks = 1 << iota // Блок 1
+	DataBlockTwo                          // Блок 2
+	DataBlockThree                        // Блок 3
+	DataBlockFour                         // Блок 4
+	DataBlockFive                         // шапка отчёта (Блок 5)
+	DataSettings                          // график работы, гидростатика, очистка сигнала, единицы отображения
+	DataSources                           // импортированные файлы и пометки строк
+	DataWorkspace                         // список исследований и активное исследование
+
+	// DataRows — строки блоков 1–4.
+	DataRows = DataBlockOne | DataBlockTwo | DataBlockThree | DataBlockFour
+	// DataResearch — всё, что хранится в одном исследовании.
+	DataResearch = DataRows | DataBlockFive | DataSettings | DataSources
+	// DataAll — любые изменения.
+	DataAll = DataResearch | DataWorkspace
+)
+
+// Has сообщает, что в наборе есть хотя бы одна часть из other.
+func (b DataBlocks) Has(other DataBlocks) bool {
+	return b&other != 0
+}
+
+// DataChange — уведомление хранилища об изменении данных.
+type DataChange struct {
+	Research int // ID исследования; 0 — изменение общее для всех исследований (единицы отображения)
+	Blocks   DataBlocks
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// DespikeMethod — способ удаления выбросов.
+type DespikeMethod string
+
+const (
+	DespikeNone   DespikeMethod = ""       // выбросы не удаляются
+	DespikeMedian DespikeMethod = "median" // скользящая медиана заменяет каждый замер
+	DespikeHampel DespikeMethod = "hampel" // медианой заменяются только замеры дальше Threshold·σ от неё
+)
+
+// Title возвращает название способа для интерфейса.
+func (m DespikeMethod) Title() string {
+	switch m {
+	case DespikeNone:
+		return "Нет"
+	case DespikeMedian:
+		return "Медианный фильтр"
+	case DespikeHampel:
+		return "Фильтр Хампеля"
+	default:
+		return string(m)
+	}
+}
+
+// SmoothMethod — способ сглаживания.
+type SmoothMethod string
+
+const (
+	SmoothNone          SmoothMethod = ""               // без сглаживания
+	SmoothMovingAverage SmoothMethod = "moving_average" // скользящее среднее
+	SmoothSavitzkyGolay SmoothMethod = "savitzky_golay" // фильтр Савицкого — Голея
+)
+
+// Title возвращает название способа для интерфейса.
+func (m SmoothMethod) Title() string {
+	switch m {
+	case SmoothNone:
+		return "Нет"
+	case SmoothMovingAverage:
+		return "Скользящее среднее"
+	case SmoothSavitzkyGolay:
+		return "Савицкий — Голей"
+	default:
+		return string(m)
+	}
+}
+
+// FilterOptions — шаги очистки ряда; выполняются в порядке объявления полей.
+// Окна задаются в замерах и должны быть нечётными: замер в центре окна.
+type FilterOptions struct {
+	DropNonPositive bool // убрать замеры с нулевым или отрицательным давлением — провалы записи датчика
+
+	Despike       DespikeMethod
+	DespikeWindow int
+	Threshold     float64 // порог фильтра Хампеля в σ, σ = 1,4826·MAD
+
+	Smooth       SmoothMethod
+	SmoothWindow int
+	PolyOrder    int // степень полинома фильтра Савицкого — Голея
+
+	// Прореживание: замер остаётся, если давление изменилось хотя бы на MinDeltaP
+	// или прошло не меньше MaxDeltaT с последнего оставленного. Нули отключают критерий,
+	// оба нуля — прореживания нет. Первый и последний замеры остаются всегда.
+	MinDeltaP float64
+	MaxDeltaT time.Duration
+}
+
+// Decimates сообщает, включено ли прореживание.
+func (o FilterOptions) Decimates() bool {
+	return o.MinDeltaP > 0 || o.MaxDeltaT > 0
+}
+
+// FilterConfig — параметры очистки блоков 1 и 2. Исходные замеры в хранилище не меняются:
+// очищенный ряд строится по ним при каждом обращении.
+type FilterConfig struct {
+	Options  FilterOptions
+	UseClean bool // графики, расчёты и экспорт берут очищенные ряды; false — исходные
+}
+
+// FilterReport — итог очистки ряда.
+type FilterReport struct {
+	Input     int // замеров до очистки
+	Dropouts  int // убрано провалов записи
+	Spikes    int // заменено выбросов
+	Decimated int // убрано прореживанием
+	Output    int // замеров после очистки
+}
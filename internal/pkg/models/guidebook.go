@@ -79,3 +79,64 @@ func (ResearchType) Columns() []string {
 func (r ResearchType) Map() map[string]interface{} {
 	return map[string]interface{}{"name": r.Name}
 }
+
+// Guidebook — справочник значений шапки отчёта.
+type Guidebook string
+
+const (
+	GuidebookOilField          Guidebook = "oilfield"
+	GuidebookProductiveHorizon Guidebook = "productive_horizon"
+	GuidebookInstrumentType    Guidebook = "instrument_type"
+	GuidebookResearchType      Guidebook = "research_type"
+)
+
+// Guidebooks — все справочники в порядке показа.
+var Guidebooks = []Guidebook{GuidebookOilField, GuidebookProductiveHorizon, GuidebookInstrumentType, GuidebookResearchType}
+
+// Title возвращает название справочника для интерфейса.
+func (g Guidebook) Title() string {
+	switch g {
+	case GuidebookOilField:
+		return "Месторождение"
+	case GuidebookProductiveHorizon:
+		return "Продуктивный горизонт"
+	case GuidebookInstrumentType:
+		return "Тип прибора"
+	case GuidebookResearchType:
+		return "Вид исследования"
+	default:
+		return string(g)
+	}
+}
+
+// Valid сообщает, что справочник известен: только такие имена подставляются в запросы.
+func (g Guidebook) Valid() bool {
+	switch g {
+	case GuidebookOilField, GuidebookProductiveHorizon, GuidebookInstrumentType, GuidebookResearchType:
+		return true
+	}
+	return false
+}
+
+// TableName возвращает имя таблицы справочника; совпадает с TableName его модели.
+func (g Guidebook) TableName() string {
+	return string(g)
+}
+
+// ReportColumn возвращает колонку таблицы reports, в которой хранится значение справочника.
+func (g Guidebook) ReportColumn() string {
+	switch g {
+	case GuidebookOilField:
+		return "field_name"
+	case GuidebookProductiveHorizon:
+		return "horizon"
+	default:
+		return string(g)
+	}
+}
+
+// GuidebookEntry — значение справочника и число отчётов, в которых оно указано.
+type GuidebookEntry struct {
+	Name    string `db:"name"`
+	Reports int    `db:"reports"`
+}
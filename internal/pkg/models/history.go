@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// ChangeKind — вид операции в истории изменений исследования.
+type ChangeKind string
+
+const (
+	ChangeImport       ChangeKind = "import"        // оставленный импорт файла
+	ChangeRemoveSource ChangeKind = "remove_source" // удаление строк одного файла
+	ChangeReimport     ChangeKind = "reimport"      // повторный импорт файла вместо его прежних строк
+	ChangeEdit         ChangeKind = "edit"          // правка или удаление строк в таблице
+	ChangeClear        ChangeKind = "clear"         // очистка исследования
+	ChangeOpen         ChangeKind = "open"          // открытие проекта или исследования из БД
+	ChangeReport       ChangeKind = "report"        // шапка отчёта (Блок 5)
+	ChangeSettings     ChangeKind = "settings"      // график работы, гидростатика, очистка сигнала
+)
+
+// Title возвращает название вида операции для интерфейса.
+func (k ChangeKind) Title() string {
+	switch k {
+	case ChangeImport:
+		return "Импорт"
+	case ChangeRemoveSource:
+		return "Удаление файла"
+	case ChangeReimport:
+		return "Повторный импорт"
+	case ChangeEdit:
+		return "Правка"
+	case ChangeClear:
+		return "Очистка"
+	case ChangeOpen:
+		return "Открытие"
+	case ChangeReport:
+		return "Отчёт"
+	case ChangeSettings:
+		return "Настройки"
+	default:
+		return string(k)
+	}
+}
+
+// Change — операция в истории изменений исследования.
+type Change struct {
+	ID     int
+	Kind   ChangeKind
+	Title  string
+	Source int    // ID импорта для операций с файлом; 0 — операция не связана с файлом
+	File   string // файл импорта
+	Rows   [4]int // изменение числа строк блоков 1–4: больше нуля — добавлено, меньше — удалено
+	At     time.Time
+	Undone bool // операция отменена и может быть повторена
+}
+
+// RowsDelta возвращает общее изменение числа строк блоков 1–4.
+func (c Change) RowsDelta() int {
+	return c.Rows[0] + c.Rows[1] + c.Rows[2] + c.Rows[3]
+}
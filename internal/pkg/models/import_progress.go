@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ImportOptions — параметры потокового импорта файла.
+type ImportOptions struct {
+	Mode       ImportMode
+	ChunkSize  int                  // строк в одной порции для хранилища; 0 — значение из конфигурации
+	OnProgress func(ImportProgress) // вызывается периодически во время разбора и один раз в конце
+	Time       *TimeCorrection      // поправка меток времени файла; nil — метки без пояса читаются как UTC
+	Units      UnitSystem           // единицы колонок, в заголовке которых единица не указана; нулевое — единицы хранения
+}
+
+// ImportProgress — состояние потокового импорта для отображения пользователю.
+type ImportProgress struct {
+	Rows     int           // разобрано строк данных
+	Accepted int           // из них принято
+	Fraction float64       // прочитанная доля файла от 0 до 1, -1 — неизвестна
+	Elapsed  time.Duration // время с начала импорта
+}
+
+// RowsPerSecond возвращает среднюю скорость разбора.
+func (p ImportProgress) RowsPerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Rows) / p.Elapsed.Seconds()
+}
@@ -0,0 +1,133 @@
+package models
+
+import "fmt"
+
+// ImportMode определяет реакцию импорта на ошибочные строки.
+type ImportMode int
+
+const (
+	// ImportModeStrict прерывает импорт на первой ошибочной строке.
+	ImportModeStrict ImportMode = iota
+	// ImportModeLenient пропускает ошибочные строки и собирает их в отчёт.
+	ImportModeLenient
+)
+
+// IssueKind — вид проблемы в строке импортируемого файла.
+type IssueKind string
+
+const (
+	IssueBadTimestamp  IssueKind = "bad_timestamp"  // не удалось разобрать дату/время
+	IssueNotNumeric    IssueKind = "not_numeric"    // ожидалось число
+	IssueOutOfRange    IssueKind = "out_of_range"   // значение вне допустимого диапазона
+	IssueDuplicateTime IssueKind = "duplicate_time" // метка времени уже встречалась
+	IssueNonMonotonic  IssueKind = "non_monotonic"  // MD не возрастает
+)
+
+// Title возвращает человекочитаемое название вида проблемы.
+func (k IssueKind) Title() string {
+	switch k {
+	case IssueBadTimestamp:
+		return "неверная дата/время"
+	case IssueNotNumeric:
+		return "нечисловое значение"
+	case IssueOutOfRange:
+		return "значение вне диапазона"
+	case IssueDuplicateTime:
+		return "повтор метки времени"
+	case IssueNonMonotonic:
+		return "MD не возрастает"
+	default:
+		return string(k)
+	}
+}
+
+// MaxReportIssues ограничивает число подробных записей в отчёте,
+// чтобы файл из сотен тысяч битых строк не раздувал память. Счётчики ведутся всегда.
+const MaxReportIssues = 1000
+
+// ImportIssue — проблема в конкретной строке файла. Строка с проблемой в хранилище не попадает.
+type ImportIssue struct {
+	Row     int       // номер строки в файле, с единицы
+	Column  string    // буквенное обозначение колонки, если проблема в ячейке
+	Field   string    // имя поля модели
+	Kind    IssueKind // вид проблемы
+	Value   string    // исходное значение ячейки
+	Message string    // пояснение
+}
+
+// ImportReport — итог разбора файла: сопоставление колонок, принятые строки и найденные проблемы.
+type ImportReport struct {
+	File         string
+	Format       string // формат исходного файла: XLSX или параметры текстового файла
+	Mode         ImportMode
+	Mapping      ColumnMapping
+	TotalRows    int               // строк данных в файле
+	AcceptedRows int               // строк, прошедших проверку
+	Issues       []ImportIssue     // не более MaxReportIssues записей
+	IssueCounts  map[IssueKind]int // число проблем каждого вида
+	Truncated    bool              // часть проблем не попала в Issues
+	// TimeCorrection — поправка, применённая к меткам времени; nil — метки взяты как есть
+	TimeCorrection *TimeCorrection
+	// Units — единицы колонок файла; значения переведены из них в StorageUnits
+	Units []FieldUnit
+	// UnorderedTime — поля, метки времени которых шли не по порядку. Повторы ищутся среди соседних
+	// меток, поэтому в таких колонках повтор более ранней метки может остаться незамеченным
+	UnorderedTime []string
+}
+
+// FieldUnit — единица, в которой записана колонка файла.
+type FieldUnit struct {
+	Field      string // имя поля модели
+	Unit       Unit
+	FromHeader bool // единица распознана в заголовке, а не взята из параметров импорта
+}
+
+// Converted возвращает колонки, значения которых переведены в единицы хранения.
+func (r ImportReport) Converted() []FieldUnit {
+	var out []FieldUnit
+	for _, u := range r.Units {
+		if q, ok := FieldQuantities[u.Field]; ok && u.Unit != StorageUnits.Unit(q) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// AddIssue регистрирует проблему в отчёте.
+func (r *ImportReport) AddIssue(issue ImportIssue) {
+	if r.IssueCounts == nil {
+		r.IssueCounts = make(map[IssueKind]int)
+	}
+	r.IssueCounts[issue.Kind]++
+	if len(r.Issues) >= MaxReportIssues {
+		r.Truncated = true
+		return
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// RejectedRows возвращает число отброшенных строк.
+func (r ImportReport) RejectedRows() int {
+	return r.TotalRows - r.AcceptedRows
+}
+
+// HasIssues сообщает, были ли найдены проблемы.
+func (r ImportReport) HasIssues() bool {
+	return len(r.IssueCounts) > 0
+}
+
+// String форматирует проблему для вывода в списке.
+func (i ImportIssue) String() string {
+	s := fmt.Sprintf("строка %d", i.Row)
+	if i.Column != "" {
+		s += ", колонка " + i.Column
+	}
+	s += ": " + i.Kind.Title()
+	if i.Value != "" {
+		s += fmt.Sprintf(" «%s»", i.Value)
+	}
+	if i.Message != "" {
+		s += " — " + i.Message
+	}
+	return s
+}
@@ -1,17 +1,115 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"time"
 
-// OperationConfig хранит параметры гидростатики и контекст парсинга блока 1.
+	"github.com/cockroachdb/errors"
+)
+
+// PeriodKind — режим скважины в периоде графика работы.
+type PeriodKind string
+
+const (
+	PeriodWork PeriodKind = "work" // скважина в работе
+	PeriodIdle PeriodKind = "idle" // скважина в простое
+)
+
+// Title возвращает название режима для интерфейса.
+func (k PeriodKind) Title() string {
+	switch k {
+	case PeriodWork:
+		return "Работа"
+	case PeriodIdle:
+		return "Простой"
+	default:
+		return string(k)
+	}
+}
+
+// GapPolicy определяет, как считать Рзаб на ВДП для замеров между периодами графика.
+type GapPolicy string
+
+const (
+	GapNaN          GapPolicy = "nan"           // не считать: Рзаб на ВДП = NaN (по умолчанию)
+	GapCarryForward GapPolicy = "carry_forward" // плотность предыдущего периода
+	GapInterpolate  GapPolicy = "interpolate"   // плотность линейно меняется от конца предыдущего периода к началу следующего
+)
+
+// Title возвращает название способа для интерфейса.
+func (p GapPolicy) Title() string {
+	switch p {
+	case GapCarryForward:
+		return "Плотность предыдущего периода"
+	case GapInterpolate:
+		return "Интерполяция плотности"
+	default:
+		return "Не считать (NaN)"
+	}
+}
+
+// OperationPeriod — период графика работы скважины со своей плотностью жидкости.
+type OperationPeriod struct {
+	Kind    PeriodKind `db:"kind"`
+	Label   string     `db:"label"`      // подпись периода, например «Отработка 2» или «КВД»
+	Start   time.Time  `db:"start_time"` // начало периода, включительно
+	End     time.Time  `db:"end_time"`   // конец периода, включительно
+	Density float64    `db:"density"`    // плотность жидкости в стволе, кг/м³
+}
+
+// TableName возвращает имя таблицы периодов графика работы в БД
+func (OperationPeriod) TableName() string {
+	return "operation_periods"
+}
+
+// Columns возвращает колонки периода в порядке INSERT/SELECT
+func (OperationPeriod) Columns() []string {
+	return []string{"kind", "label", "start_time", "end_time", "density"}
+}
+
+// OperationConfig хранит параметры гидростатики и график работы скважины для блока 1.
 type OperationConfig struct {
-	PressureUnit string  // "kgf/cm2", "bar" или "atm"
-	DepthDiff    float64 // Δh между замером и ВДП (в метрах)
+	DepthDiff float64 `db:"depth_diff"` // Δh между замером и ВДП (в метрах); давления — в единицах хранения, см. StorageUnits
+
+	Periods   []OperationPeriod `db:"-"`          // упорядочены по времени и не пересекаются, см. Normalize
+	GapPolicy GapPolicy         `db:"gap_policy"` // что делать с замерами между периодами
+}
 
-	WorkStart   time.Time // начало рабочего периода
-	WorkEnd     time.Time // конец рабочего периода
-	WorkDensity float64   // плотность в рабочем периоде
+// TableName возвращает имя таблицы параметров гидростатики в БД
+func (OperationConfig) TableName() string {
+	return "operation_config"
+}
+
+// Columns возвращает колонки параметров гидростатики в порядке INSERT/SELECT; периоды хранятся отдельно
+func (OperationConfig) Columns() []string {
+	return []string{"depth_diff", "gap_policy"}
+}
+
+// Normalize сортирует периоды по началу и проверяет, что они корректны и не пересекаются.
+func (c *OperationConfig) Normalize() error {
+	if len(c.Periods) == 0 {
+		return errors.New("не задан ни один период работы или простоя")
+	}
+	sort.SliceStable(c.Periods, func(i, j int) bool { return c.Periods[i].Start.Before(c.Periods[j].Start) })
+	for i, p := range c.Periods {
+		if !p.End.After(p.Start) {
+			return errors.Newf("период %d (%s): конец должен быть позже начала", i+1, p.Label)
+		}
+		if p.Density <= 0 {
+			return errors.Newf("период %d (%s): плотность должна быть больше нуля", i+1, p.Label)
+		}
+		// общая граница допустима: замер на ней относится к более раннему периоду
+		if i > 0 && p.Start.Before(c.Periods[i-1].End) {
+			return errors.Newf("периоды %d (%s) и %d (%s) пересекаются", i, c.Periods[i-1].Label, i+1, p.Label)
+		}
+	}
+	return nil
+}
 
-	IdleStart   time.Time // начало простоя
-	IdleEnd     time.Time // конец простоя
-	IdleDensity float64   // плотность при простое
+// PeriodCandidate — период графика работы, предложенный автоматическим определением.
+// Плотность заполняется из уже заданных периодов того же режима, если они есть.
+type PeriodCandidate struct {
+	OperationPeriod
+	Confidence float64 // уверенность от 0 до 1
+	Reason     string  // чем подтверждён режим: скачок давления, дебиты блока 3
 }
@@ -0,0 +1,117 @@
+package models
+
+import "time"
+
+// TransientTime — функция времени, по которой дифференцируется Δp при анализе КВД.
+type TransientTime string
+
+const (
+	TimeElapsed       TransientTime = "elapsed"       // ln Δt — время с момента остановки
+	TimeSuperposition TransientTime = "superposition" // время суперпозиции по истории дебитов блока 3
+)
+
+// Title возвращает название функции времени для интерфейса.
+func (t TransientTime) Title() string {
+	switch t {
+	case TimeSuperposition:
+		return "Время суперпозиции"
+	default:
+		return "Время с момента остановки"
+	}
+}
+
+// BuildUpOptions — параметры расчёта производной Бурде.
+type BuildUpOptions struct {
+	Smoothing float64       // окно сглаживания L в единицах натурального логарифма времени, обычно 0.1–0.5; 0 — соседние точки
+	Time      TransientTime // по какому времени дифференцировать
+}
+
+// BuildUpPoint — точка диагностического графика КВД.
+type BuildUpPoint struct {
+	Timestamp  time.Time
+	Elapsed    float64 // Δt, часы с момента остановки
+	TimeFunc   float64 // значение функции времени, по которой взята производная
+	DeltaP     float64 // Δp = p(Δt) − p(Δt=0), в единицах давления блока 1
+	Derivative float64 // производная Бурде dΔp/dTimeFunc; NaN, если не определена
+}
+
+// BuildUp — результат анализа одного периода простоя.
+type BuildUp struct {
+	Period       OperationPeriod
+	Options      BuildUpOptions
+	ShutInTime   time.Time // момент остановки, от которого отсчитывается Δt
+	ShutInP      float64   // давление на момент остановки
+	ProducedTime float64   // время работы до остановки по блоку 3, часы; 0 — дебиты не использовались
+	Points       []BuildUpPoint
+}
+
+// SemilogMethod — метод полулогарифмической интерпретации КВД.
+type SemilogMethod string
+
+const (
+	MethodHorner SemilogMethod = "horner" // p от lg((tp+Δt)/Δt), даёт p*
+	MethodMDH    SemilogMethod = "mdh"    // Миллера–Дайеса–Хатчинсона: p от lg Δt, для коротких КВД при Δt ≪ tp
+)
+
+// Title возвращает название метода для интерфейса.
+func (m SemilogMethod) Title() string {
+	switch m {
+	case MethodMDH:
+		return "MDH"
+	default:
+		return "Хорнер"
+	}
+}
+
+// ReservoirParams — свойства пласта и флюида для расчёта проницаемости и скина.
+type ReservoirParams struct {
+	Thickness       float64 // эффективная толщина h, м
+	Porosity        float64 // пористость φ, доли ед.
+	Viscosity       float64 // вязкость μ, мПа·с
+	VolumeFactor    float64 // объёмный коэффициент Bo, м³/м³
+	Compressibility float64 // общая сжимаемость ct, 1/МПа
+	WellRadius      float64 // радиус скважины rw, м
+}
+
+// SemilogOptions — параметры полулогарифмической интерпретации.
+type SemilogOptions struct {
+	Method    SemilogMethod
+	FromHours float64 // начало участка радиального притока, Δt в часах; 0 и 0 — выбрать по производной
+	ToHours   float64
+	Reservoir ReservoirParams
+}
+
+// SemilogPoint — точка полулогарифмического графика.
+type SemilogPoint struct {
+	Elapsed   float64 // Δt, часы
+	X         float64 // (tp+Δt)/Δt для Хорнера, Δt для MDH — ось откладывается в логарифмическом масштабе
+	Pressure  float64 // давление на глубине замера
+	InSegment bool    // точка участвует в аппроксимации прямой
+}
+
+// SemilogResult — результат интерпретации КВД по прямой на полулогарифмическом графике.
+// Давления — в единицах блока 1 на глубине замера, кроме PStarVDP.
+type SemilogResult struct {
+	Method    SemilogMethod
+	Period    OperationPeriod
+	Reservoir ReservoirParams
+
+	Rate          float64 // дебит жидкости перед остановкой q, м³/сут
+	ProducingTime float64 // эквивалентное время работы tp = Qнакоп/q, часы
+	ShutInP       float64 // давление на момент остановки p(Δt=0)
+
+	FromHours, ToHours float64 // участок радиального притока
+	Slope              float64 // наклон прямой m на логарифмический цикл, по модулю
+	Intercept          float64 // давление на прямой при lg X = 0
+	R2                 float64 // коэффициент детерминации аппроксимации
+
+	P1h      float64 // давление на прямой при Δt = 1 ч
+	PStar    float64 // экстраполированное пластовое давление p*; NaN для MDH
+	PStarVDP float64 // p*, приведённое к ВДП по плотности жидкости в простое из блока 5; NaN, если не рассчитано
+
+	PermeabilityThickness float64 // kh, мД·м
+	Permeability          float64 // k, мД
+	Skin                  float64
+
+	Points []SemilogPoint
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// IPROptions — исходные данные для расчёта продуктивности и индикаторной кривой.
+type IPROptions struct {
+	ReservoirPressure   float64 // пластовое давление Pпл на ВДП, в единицах блока 1
+	BubblePointPressure float64 // давление насыщения Pнас; 0 или ≥ Pпл — чистая кривая Вогеля
+}
+
+// ProductivityStep — режим работы с постоянным дебитом и соответствующее ему забойное давление.
+type ProductivityStep struct {
+	Start, End time.Time
+	Rate       float64 // Qж, м³/сут
+	Pwf        float64 // Рзаб на ВДП, среднее по стабилизированной части режима
+	Drawdown   float64 // депрессия Pпл − Рзаб
+	PI         float64 // коэффициент продуктивности Q/(Pпл − Рзаб), м³/сут на единицу давления; NaN при нулевой депрессии
+	Samples    int     // замеров блока 1, по которым усреднено Рзаб
+}
+
+// IPRPoint — точка индикаторной кривой.
+type IPRPoint struct {
+	Pwf  float64
+	Rate float64
+}
+
+// IPR — коэффициенты продуктивности по режимам и подобранная индикаторная кривая Вогеля
+// (композитная, если Pнас ниже Pпл).
+type IPR struct {
+	Options IPROptions
+	Steps   []ProductivityStep
+	J       float64 // коэффициент продуктивности прямолинейного участка, подобранный по всем режимам
+	QMax    float64 // потенциальный дебит при Рзаб = 0
+	Curve   []IPRPoint
+}
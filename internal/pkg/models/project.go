@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	// ProjectFormat — метка файла проекта, по ней отличаем его от произвольного zip.
+	ProjectFormat = "burovichok-project"
+	// ProjectVersion — версия формата файла проекта; файлы более новых версий не открываются.
+	ProjectVersion = 1
+	// ProjectExtension — расширение файла проекта.
+	ProjectExtension = ".burproj"
+)
+
+// ImportSource — импортированный файл: откуда и с какими параметрами получены строки блока.
+// Параметров достаточно, чтобы повторить импорт того же файла.
+type ImportSource struct {
+	ID             int    // номер импорта в исследовании, им помечены строки блоков; 0 — не назначен
+	Block          string // тип документа: TableOne…TableFour или инклинометрия
+	File           string // путь к файлу на момент импорта
+	Hash           string // SHA-256 содержимого файла: по нему узнаётся повторный импорт того же файла
+	Format         string // формат файла, как в ImportReport
+	Mode           ImportMode
+	Rows           int       // принято строк
+	ImportedAt     time.Time // когда выполнен импорт
+	TimeCorrection *TimeCorrection
+	Units          []FieldUnit // единицы колонок, из которых переведены значения
+	Elevation      *float64    // альтитуда стола ротора, м, для инклинометрии по углам
+}
+
+// FileUnits возвращает единицы колонок, заданные параметрами импорта, а не заголовком файла:
+// с ними файл импортируется повторно.
+func (s ImportSource) FileUnits() UnitSystem {
+	var out UnitSystem
+	for _, u := range s.Units {
+		if !u.FromHeader {
+			out = out.With(FieldQuantities[u.Field], u.Unit)
+		}
+	}
+	return out
+}
+
+// Project — сессия исследования целиком: данные блоков 1–5 и настройки, с которыми
+// их обрабатывали. Сохраняется в локальный файл, чтобы работать без БД и передавать исследование коллеге.
+type Project struct {
+	Format  string
+	Version int
+	SavedAt time.Time
+
+	One   []TableOne
+	Two   []TableTwo
+	Three []TableThree
+	Four  []TableFour
+	Five  TableFive
+
+	OperationConfig *OperationConfig // nil — график работы не задан
+	FilterConfig    *FilterConfig    // nil — очистка не настроена
+	DisplayUnits    UnitSystem
+	Sources         []ImportSource
+	// RowSources — ID импорта (ImportSource.ID) каждой строки блоков 1–4; 0 — файл неизвестен.
+	// В файлах, сохранённых до появления пометок, пусто.
+	RowSources [4][]int
+}
+
+// Rows возвращает общее число строк блоков 1–4.
+func (p Project) Rows() int {
+	return len(p.One) + len(p.Two) + len(p.Three) + len(p.Four)
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Channel — величина из блоков 1–3, которую можно вывести на общую временную сетку.
+type Channel string
+
+const (
+	ChannelPressureDepth    Channel = "pressure_depth"    // Рзаб на глубине замера, блок 1
+	ChannelTemperatureDepth Channel = "temperature_depth" // Tзаб на глубине замера, блок 1
+	ChannelPressureAtVDP    Channel = "pressure_at_vdp"   // Рзаб на ВДП, блок 1
+	ChannelPressureTubing   Channel = "pressure_tubing"   // Ртр, блок 2
+	ChannelPressureAnnulus  Channel = "pressure_annulus"  // Рзтр, блок 2
+	ChannelPressureLinear   Channel = "pressure_linear"   // Рлин, блок 2
+	ChannelLiquidFlowRate   Channel = "flow_liquid"       // Qж, блок 3
+	ChannelWaterCut         Channel = "water_cut"         // W, блок 3
+	ChannelGasFlowRate      Channel = "flow_gas"          // Qг, блок 3
+	ChannelOilFlowRate      Channel = "oil_flow_rate"     // Qн, блок 3, расчётное
+	ChannelWaterFlowRate    Channel = "water_flow_rate"   // Qв, блок 3, расчётное
+	ChannelGasFactor        Channel = "gas_oil_ratio"     // ГФ, блок 3, расчётное
+)
+
+// Title возвращает подпись величины без единицы измерения, см. Label.
+func (c Channel) Title() string {
+	switch c {
+	case ChannelPressureDepth:
+		return "Рзаб на глубине"
+	case ChannelTemperatureDepth:
+		return "Tзаб на глубине"
+	case ChannelPressureAtVDP:
+		return "Рзаб на ВДП"
+	case ChannelPressureTubing:
+		return "Ртр"
+	case ChannelPressureAnnulus:
+		return "Рзтр"
+	case ChannelPressureLinear:
+		return "Рлин"
+	case ChannelLiquidFlowRate:
+		return "Qж"
+	case ChannelWaterCut:
+		return "W, %"
+	case ChannelGasFlowRate:
+		return "Qг"
+	case ChannelOilFlowRate:
+		return "Qн"
+	case ChannelWaterFlowRate:
+		return "Qв"
+	case ChannelGasFactor:
+		return "ГФ, м³/м³"
+	default:
+		return string(c)
+	}
+}
+
+// Quantity возвращает величину канала; false — у канала нет выбираемой единицы (W, ГФ).
+func (c Channel) Quantity() (Quantity, bool) {
+	switch c {
+	case ChannelPressureDepth, ChannelPressureAtVDP, ChannelPressureTubing, ChannelPressureAnnulus, ChannelPressureLinear:
+		return QuantityPressure, true
+	case ChannelTemperatureDepth:
+		return QuantityTemperature, true
+	case ChannelLiquidFlowRate, ChannelOilFlowRate, ChannelWaterFlowRate:
+		return QuantityLiquidRate, true
+	case ChannelGasFlowRate:
+		return QuantityGasRate, true
+	default:
+		return "", false
+	}
+}
+
+// Label возвращает подпись величины с единицей системы units для таблиц, графиков и экспорта.
+func (c Channel) Label(units UnitSystem) string {
+	if q, ok := c.Quantity(); ok {
+		return c.Title() + ", " + units.Symbol(q)
+	}
+	return c.Title()
+}
+
+// IsPressure сообщает, что величина — давление (общая ось на графиках).
+func (c Channel) IsPressure() bool {
+	q, _ := c.Quantity()
+	return q == QuantityPressure
+}
+
+// Series — ряд одной величины со своими метками времени. NaN — пропуск замера.
+type Series struct {
+	Channel Channel
+	Times   []time.Time
+	Values  []float64
+}
+
+// GridMode задаёт, из каких моментов времени состоит общая сетка.
+type GridMode string
+
+const (
+	GridFixedStep GridMode = "fixed" // равномерная сетка с шагом Step
+	GridUnion     GridMode = "union" // объединение меток времени всех рядов
+)
+
+// Title возвращает название способа для интерфейса.
+func (m GridMode) Title() string {
+	switch m {
+	case GridFixedStep:
+		return "Постоянный шаг"
+	case GridUnion:
+		return "Все метки времени"
+	default:
+		return string(m)
+	}
+}
+
+// Aggregation задаёт, как значение ряда переносится на узел сетки.
+//
+// Узел tₖ отвечает интервалу [tₖ, tₖ₊₁): среднее и последнее берутся по замерам внутри интервала,
+// интерполяция и удержание — по замерам вокруг самого узла.
+type Aggregation string
+
+const (
+	AggregationMean     Aggregation = "mean"      // среднее замеров интервала
+	AggregationLast     Aggregation = "last"      // последний замер интервала
+	AggregationLinear   Aggregation = "linear"    // линейная интерполяция между соседними замерами
+	AggregationStepHold Aggregation = "step_hold" // последний замер не позже узла действует до следующего
+)
+
+// Title возвращает название способа для интерфейса.
+func (a Aggregation) Title() string {
+	switch a {
+	case AggregationMean:
+		return "Среднее"
+	case AggregationLast:
+		return "Последнее значение"
+	case AggregationLinear:
+		return "Линейная интерполяция"
+	case AggregationStepHold:
+		return "Удержание значения"
+	default:
+		return string(a)
+	}
+}
+
+// ResampleOptions — параметры вывода рядов на общую сетку.
+type ResampleOptions struct {
+	Grid GridMode
+	Step time.Duration // шаг сетки для GridFixedStep
+	// Start и End ограничивают сетку; нулевое значение — от первого до последнего замера всех рядов.
+	Start, End  time.Time
+	Aggregation Aggregation
+	// Overrides задаёт способ для отдельных величин, например удержание для дебитов блока 3.
+	Overrides map[Channel]Aggregation
+	// MaxGap — наибольший промежуток между замерами, через который ещё можно интерполировать
+	// или удерживать значение; дальше узел остаётся пустым (NaN). 0 — без ограничения.
+	MaxGap time.Duration
+	// DropEmpty убирает узлы, в которых нет значения ни у одной величины.
+	DropEmpty bool
+}
+
+// AggregationFor возвращает способ переноса для величины с учётом Overrides.
+func (o ResampleOptions) AggregationFor(c Channel) Aggregation {
+	if a, ok := o.Overrides[c]; ok {
+		return a
+	}
+	return o.Aggregation
+}
+
+// Frame — величины блоков 1–3 на общей временной сетке. Пустой узел — NaN.
+type Frame struct {
+	Times    []time.Time
+	Channels []Channel
+	Values   [][]float64 // Values[j][i] — величина Channels[j] в узле Times[i]
+}
+
+// Len возвращает число узлов сетки.
+func (f Frame) Len() int {
+	return len(f.Times)
+}
+
+// Column возвращает значения величины по узлам сетки или nil, если её нет в кадре.
+func (f Frame) Column(c Channel) []float64 {
+	for j, ch := range f.Channels {
+		if ch == c {
+			return f.Values[j]
+		}
+	}
+	return nil
+}
+
+// Filled возвращает число непустых узлов величины.
+func (f Frame) Filled(c Channel) int {
+	n := 0
+	for _, v := range f.Column(c) {
+		if !math.IsNaN(v) {
+			n++
+		}
+	}
+	return n
+}
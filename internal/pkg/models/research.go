@@ -0,0 +1,18 @@
+package models
+
+// Research — сохранённое исследование: шапка отчёта (Блок 5), импортированные блоки 1–4 и график работы.
+// Блоки хранятся в БД как импортированы, без очистки сигнала: очищенный ряд строится заново по параметрам,
+// Рзаб на ВДП — по графику работы.
+type Research struct {
+	Report    TableFive
+	One       []TableOne
+	Two       []TableTwo
+	Three     []TableThree
+	Four      []TableFour
+	Operation *OperationConfig // nil — график работы не задан
+}
+
+// Rows возвращает общее число строк блоков 1–4.
+func (r Research) Rows() int {
+	return len(r.One) + len(r.Two) + len(r.Three) + len(r.Four)
+}
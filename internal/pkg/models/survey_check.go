@@ -0,0 +1,70 @@
+package models
+
+import "fmt"
+
+// SurveyIssueKind — вид проблемы инклинометрии, найденной при расчёте шапки отчёта.
+type SurveyIssueKind string
+
+const (
+	SurveyExtrapolated  SurveyIssueKind = "extrapolated"   // глубина прибора или ВДП вне диапазона замеров
+	SurveyUnsorted      SurveyIssueKind = "unsorted"       // замеры идут не по возрастанию MD
+	SurveyDuplicateMD   SurveyIssueKind = "duplicate_md"   // несколько замеров на одной MD
+	SurveyTVDDecreasing SurveyIssueKind = "tvd_decreasing" // TVD уменьшается с ростом MD
+	SurveyTVDExceedsMD  SurveyIssueKind = "tvd_exceeds_md" // TVD больше MD
+)
+
+// Title возвращает человекочитаемое название вида проблемы.
+func (k SurveyIssueKind) Title() string {
+	switch k {
+	case SurveyExtrapolated:
+		return "экстраполяция за пределы инклинометрии"
+	case SurveyUnsorted:
+		return "замеры не упорядочены по MD"
+	case SurveyDuplicateMD:
+		return "повтор MD"
+	case SurveyTVDDecreasing:
+		return "TVD уменьшается с глубиной"
+	case SurveyTVDExceedsMD:
+		return "TVD больше MD"
+	default:
+		return string(k)
+	}
+}
+
+// SurveyIssue — проблема инклинометрии или расчёта отметок по ней.
+// Ошибка означает, что отметки недостоверны и отчёт сохранять нельзя; предупреждение требует подтверждения.
+type SurveyIssue struct {
+	Kind    SurveyIssueKind
+	Error   bool    // true — ошибка, false — предупреждение
+	Station int     // номер замера в блоке 4, с единицы; 0 — проблема не привязана к замеру
+	MD      float64 // глубина по стволу, к которой относится проблема
+	Message string  // пояснение
+}
+
+func (i SurveyIssue) String() string {
+	level := "предупреждение"
+	if i.Error {
+		level = "ошибка"
+	}
+	if i.Station > 0 {
+		return fmt.Sprintf("%s, замер %d (MD %g): %s — %s", level, i.Station, i.MD, i.Kind.Title(), i.Message)
+	}
+	return fmt.Sprintf("%s, MD %g: %s — %s", level, i.MD, i.Kind.Title(), i.Message)
+}
+
+// HasSurveyErrors сообщает, есть ли среди проблем хотя бы одна ошибка.
+func HasSurveyErrors(issues []SurveyIssue) bool {
+	for _, i := range issues {
+		if i.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// SurveyOptions управляет расчётом отметок по инклинометрии.
+type SurveyOptions struct {
+	// Extrapolate разрешает продолжить ствол по касательной за первый или последний замер.
+	// Без него глубина вне диапазона замеров — ошибка, отметки не рассчитываются.
+	Extrapolate bool
+}
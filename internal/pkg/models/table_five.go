@@ -27,6 +27,21 @@ type TableFive struct {
 	DensityLiquidWorking    float64   `db:"density_liquid_working"`      // Плотность жидкости в работе, кг/м3
 	PressureDiffStopped     *float64  `db:"pressure_diff_stopped"`       // ΔP простоя, расчётное
 	PressureDiffWorking     *float64  `db:"pressure_diff_working"`       // ΔP работы, расчётное
+
+	// Свойства пласта для интерпретации КВД, необязательные
+	Thickness            *float64 `db:"thickness"`             // Эффективная толщина h, м
+	Porosity             *float64 `db:"porosity"`              // Пористость φ, доли ед.
+	Viscosity            *float64 `db:"viscosity"`             // Вязкость μ, мПа·с
+	VolumeFactor         *float64 `db:"volume_factor"`         // Объёмный коэффициент Bo, м³/м³
+	TotalCompressibility *float64 `db:"total_compressibility"` // Общая сжимаемость ct, 1/МПа
+	WellRadius           *float64 `db:"well_radius"`           // Радиус скважины rw, м
+
+	// Результаты интерпретации КВД, расчётные
+	AnalysisMethod        *string  `db:"analysis_method"`        // horner или mdh
+	ExtrapolatedPressure  *float64 `db:"extrapolated_pressure"`  // p* на ВДП
+	PermeabilityThickness *float64 `db:"permeability_thickness"` // kh, мД·м
+	Permeability          *float64 `db:"permeability"`           // k, мД
+	Skin                  *float64 `db:"skin"`                   // Скин-фактор
 }
 
 // TableName имя таблицы.
@@ -60,6 +75,17 @@ func (TableFive) Columns() []string {
 		"density_liquid_working",
 		"pressure_diff_stopped",
 		"pressure_diff_working",
+		"thickness",
+		"porosity",
+		"viscosity",
+		"volume_factor",
+		"total_compressibility",
+		"well_radius",
+		"analysis_method",
+		"extrapolated_pressure",
+		"permeability_thickness",
+		"permeability",
+		"skin",
 	}
 }
 
@@ -87,5 +113,50 @@ func (t TableFive) Map() map[string]any {
 		"density_liquid_working":      t.DensityLiquidWorking,
 		"pressure_diff_stopped":       t.PressureDiffStopped,
 		"pressure_diff_working":       t.PressureDiffWorking,
+		"thickness":                   t.Thickness,
+		"porosity":                    t.Porosity,
+		"viscosity":                   t.Viscosity,
+		"volume_factor":               t.VolumeFactor,
+		"total_compressibility":       t.TotalCompressibility,
+		"well_radius":                 t.WellRadius,
+		"analysis_method":             t.AnalysisMethod,
+		"extrapolated_pressure":       t.ExtrapolatedPressure,
+		"permeability_thickness":      t.PermeabilityThickness,
+		"permeability":                t.Permeability,
+		"skin":                        t.Skin,
+	}
+}
+
+// Reservoir возвращает свойства пласта из отчёта; незаполненные поля равны нулю.
+func (t TableFive) Reservoir() ReservoirParams {
+	val := func(p *float64) float64 {
+		if p == nil {
+			return 0
+		}
+		return *p
+	}
+	return ReservoirParams{
+		Thickness:       val(t.Thickness),
+		Porosity:        val(t.Porosity),
+		Viscosity:       val(t.Viscosity),
+		VolumeFactor:    val(t.VolumeFactor),
+		Compressibility: val(t.TotalCompressibility),
+		WellRadius:      val(t.WellRadius),
+	}
+}
+
+// SetReservoir записывает свойства пласта в отчёт; нулевые значения считаются незаполненными.
+func (t *TableFive) SetReservoir(r ReservoirParams) {
+	ptr := func(v float64) *float64 {
+		if v == 0 {
+			return nil
+		}
+		return &v
 	}
+	t.Thickness = ptr(r.Thickness)
+	t.Porosity = ptr(r.Porosity)
+	t.Viscosity = ptr(r.Viscosity)
+	t.VolumeFactor = ptr(r.VolumeFactor)
+	t.TotalCompressibility = ptr(r.Compressibility)
+	t.WellRadius = ptr(r.WellRadius)
 }
@@ -3,11 +3,26 @@ package models
 import "github.com/google/uuid"
 
 // TableFour — Блок 4. Инклинометрия (MD, TVD, TVDSS)
+// Углы, смещения и интенсивность заполнены, только если траектория рассчитана по замерам углов
+// (см. SurveyStation), для готовых таблиц MD/TVD/TVDSS они nil.
 type TableFour struct {
 	ResearchID              uuid.UUID `db:"research_id"`
 	MeasuredDepth           float64   `xlsx:"Глубина по стволу, м" db:"measure_depth"`                // MD
 	TrueVerticalDepth       float64   `xlsx:"Глубина по вертикали, м" db:"true_vertical_depth"`       // TVD
 	TrueVerticalDepthSubSea float64   `xlsx:"Абсолютная глубина, м" db:"true_vertical_depth_sub_sea"` // TVDSS
+	Inclination             *float64  `db:"inclination"`                                              // Зенитный угол, °
+	Azimuth                 *float64  `db:"azimuth"`                                                  // Азимут, °
+	Northing                *float64  `db:"northing"`                                                 // Смещение на север от устья, м
+	Easting                 *float64  `db:"easting"`                                                  // Смещение на восток от устья, м
+	DoglegSeverity          *float64  `db:"dogleg_severity"`                                          // Интенсивность искривления, °/10 м
+}
+
+// SurveyStation — точка замера инклинометрии: глубина по стволу и углы.
+// Из последовательности точек рассчитывается траектория TableFour.
+type SurveyStation struct {
+	MeasuredDepth float64 `xlsx:"Глубина по стволу, м"` // MD
+	Inclination   float64 `xlsx:"Зенитный угол, °"`     // от вертикали, 0–180
+	Azimuth       float64 `xlsx:"Азимут, °"`            // от севера по часовой стрелке, 0–360
 }
 
 // TableName возвращает имя таблицы в БД для TableFour
@@ -23,6 +38,11 @@ func (TableFour) Columns() []string {
 		"measure_depth",
 		"true_vertical_depth",
 		"true_vertical_depth_sub_sea",
+		"inclination",
+		"azimuth",
+		"northing",
+		"easting",
+		"dogleg_severity",
 	}
 }
 
@@ -34,5 +54,10 @@ func (t TableFour) Map() map[string]interface{} {
 		"measure_depth":               t.MeasuredDepth,
 		"true_vertical_depth":         t.TrueVerticalDepth,
 		"true_vertical_depth_sub_sea": t.TrueVerticalDepthSubSea,
+		"inclination":                 t.Inclination,
+		"azimuth":                     t.Azimuth,
+		"northing":                    t.Northing,
+		"easting":                     t.Easting,
+		"dogleg_severity":             t.DoglegSeverity,
 	}
 }
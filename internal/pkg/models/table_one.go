@@ -5,10 +5,10 @@ import "time"
 // TableOne — Блок 1. Загрузка забойного давления и температуры
 // Поля соответствуют колонкам Excel через теги xlsx
 type TableOne struct {
-	Timestamp        time.Time `xlsx:"Дата, время"`                     // метка времени
-	PressureDepth    float64   `xlsx:"Рзаб на глубине замера, кгс/см2"` // забойное давление
-	TemperatureDepth float64   `xlsx:"Tзаб на глубине замера, °C"`      // забойная температура
-	PressureAtVDP    float64   // расчётное поле
+	Timestamp        time.Time `xlsx:"Дата, время" db:"timestamp"`                          // метка времени
+	PressureDepth    float64   `xlsx:"Рзаб на глубине замера, кгс/см2" db:"pressure_depth"` // забойное давление
+	TemperatureDepth float64   `xlsx:"Tзаб на глубине замера, °C" db:"temperature_depth"`   // забойная температура
+	PressureAtVDP    float64   `db:"-"`                                                     // расчётное поле, в БД не хранится
 }
 
 // TableName возвращает имя таблицы в БД для TableOne
@@ -23,7 +23,6 @@ func (TableOne) Columns() []string {
 		"timestamp",
 		"pressure_depth",
 		"temperature_depth",
-		"pressure_at_vdp",
 	}
 }
 
@@ -33,6 +32,5 @@ func (t TableOne) Map() map[string]interface{} {
 		"timestamp":         t.Timestamp,
 		"pressure_depth":    t.PressureDepth,
 		"temperature_depth": t.TemperatureDepth,
-		"pressure_at_vdp":   t.PressureAtVDP,
 	}
 }
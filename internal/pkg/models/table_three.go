@@ -4,13 +4,13 @@ import "time"
 
 // TableThree — Блок 3. Дебиты жидкости, воды, газа и расчётные поля
 type TableThree struct {
-	Timestamp      time.Time `xlsx:"Дата, время"`
-	LiquidFlowRate float64   `xlsx:"Qж, м3/сут"`
-	WaterCut       float64   `xlsx:"W, %"`
-	GasFlowRate    float64   `xlsx:"Qг, тыс.м3/сут"`
-	OilFlowRate    *float64  // Qн, расчётное поле
-	WaterFlowRate  *float64  // Qв, расчётное поле
-	GasFactor      *float64  // ГФ, расчётное поле
+	Timestamp      time.Time `xlsx:"Дата, время" db:"timestamp"`
+	LiquidFlowRate float64   `xlsx:"Qж, м3/сут" db:"flow_liquid"`
+	WaterCut       float64   `xlsx:"W, %" db:"water_cut"`
+	GasFlowRate    float64   `xlsx:"Qг, тыс.м3/сут" db:"flow_gas"`
+	OilFlowRate    *float64  `db:"oil_flow_rate"`   // Qн, расчётное поле
+	WaterFlowRate  *float64  `db:"water_flow_rate"` // Qв, расчётное поле
+	GasFactor      *float64  `db:"gas_oil_ratio"`   // ГФ, расчётное поле
 }
 
 // TableName возвращает имя таблицы в БД для TableThree
@@ -4,12 +4,12 @@ import "time"
 
 // TableTwo — Блок 2. Замеры трубного, затрубного и линейного давления
 type TableTwo struct {
-	TimestampTubing  time.Time `xlsx:"Дата трубного замера, Дата, время"`
-	PressureTubing   float64   `xlsx:"Ртр, кгс/см2"`
-	TimestampAnnulus time.Time `xlsx:"Дата затрубного замера, Дата, время"`
-	PressureAnnulus  float64   `xlsx:"Рзтр, кгс/см2"`
-	TimestampLinear  time.Time `xlsx:"Дата линейного замера, Дата, время"`
-	PressureLinear   float64   `xlsx:"Рлин, кгс/см2"`
+	TimestampTubing  time.Time `xlsx:"Дата трубного замера, Дата, время" db:"timestamp_tubing"`
+	PressureTubing   float64   `xlsx:"Ртр, кгс/см2" db:"pressure_tubing"`
+	TimestampAnnulus time.Time `xlsx:"Дата затрубного замера, Дата, время" db:"timestamp_annulus"`
+	PressureAnnulus  float64   `xlsx:"Рзтр, кгс/см2" db:"pressure_annulus"`
+	TimestampLinear  time.Time `xlsx:"Дата линейного замера, Дата, время" db:"timestamp_linear"`
+	PressureLinear   float64   `xlsx:"Рлин, кгс/см2" db:"pressure_linear"`
 }
 
 // TableName возвращает имя таблицы в БД для TableTwo
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// TimeReference — опорная точка для поправки дрейфа: показание часов прибора и время
+// по эталонным часам (SCADA, по которым записаны блоки 2 и 3) в один и тот же момент.
+type TimeReference struct {
+	Device    time.Time // показание часов прибора; вводится в поясе файла
+	Reference time.Time // эталонное время того же момента; вводится в поясе файла
+}
+
+// TimeCorrection — поправка меток времени файла при импорте.
+//
+// Метка без явного пояса читается как местное время пояса Zone и переводится в UTC.
+// Затем применяется либо постоянный сдвиг Offset, либо линейная поправка дрейфа по двум
+// опорным точкам: t' = R₁ + (t − D₁)·(R₂ − R₁)/(D₂ − D₁). Дрейф уже учитывает постоянный
+// сдвиг, поэтому одновременно их задавать нельзя.
+type TimeCorrection struct {
+	Zone   string          // часовой пояс IANA, например Europe/Moscow; пусто — UTC
+	Offset time.Duration   // прибавляется к каждой метке
+	Drift  []TimeReference // две опорные точки поправки дрейфа; пусто — без неё
+}
+
+// Location возвращает часовой пояс файла.
+func (c TimeCorrection) Location() (*time.Location, error) {
+	if c.Zone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(c.Zone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "неизвестный часовой пояс %q", c.Zone)
+	}
+	return loc, nil
+}
+
+// Validate проверяет поправку: пояс должен существовать, опорные точки — различаться.
+func (c TimeCorrection) Validate() error {
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	if len(c.Drift) == 0 {
+		return nil
+	}
+	if len(c.Drift) != 2 {
+		return errors.Newf("для поправки дрейфа нужны две опорные точки, задано %d", len(c.Drift))
+	}
+	if c.Offset != 0 {
+		return errors.New("задайте либо постоянный сдвиг, либо опорные точки дрейфа")
+	}
+	d := c.Drift
+	if !d[1].Device.After(d[0].Device) {
+		return errors.New("вторая опорная точка по часам прибора должна быть позже первой")
+	}
+	if !d[1].Reference.After(d[0].Reference) {
+		return errors.New("вторая опорная точка по эталонным часам должна быть позже первой")
+	}
+	return nil
+}
+
+// IsZero сообщает, что поправка ничего не меняет.
+func (c TimeCorrection) IsZero() bool {
+	return (c.Zone == "" || c.Zone == "UTC") && c.Offset == 0 && len(c.Drift) == 0
+}
+
+// Apply применяет сдвиг или поправку дрейфа к метке, уже переведённой в UTC.
+func (c TimeCorrection) Apply(t time.Time) time.Time {
+	if len(c.Drift) != 2 {
+		return t.Add(c.Offset)
+	}
+	d := c.Drift
+	rate := float64(d[1].Reference.Sub(d[0].Reference)) / float64(d[1].Device.Sub(d[0].Device))
+	return d[0].Reference.Add(time.Duration(float64(t.Sub(d[0].Device)) * rate)).UTC()
+}
+
+// DriftRate возвращает уход часов прибора в секундах за сутки (положительный — спешат); 0 без опорных точек.
+func (c TimeCorrection) DriftRate() float64 {
+	if len(c.Drift) != 2 {
+		return 0
+	}
+	d := c.Drift
+	device, reference := d[1].Device.Sub(d[0].Device), d[1].Reference.Sub(d[0].Reference)
+	return (device - reference).Seconds() / reference.Hours() * 24
+}
+
+// String описывает поправку для отчёта импорта.
+func (c TimeCorrection) String() string {
+	zone := c.Zone
+	if zone == "" {
+		zone = "UTC"
+	}
+	parts := []string{"пояс " + zone}
+	if c.Offset != 0 {
+		parts = append(parts, fmt.Sprintf("сдвиг %s", c.Offset))
+	}
+	if len(c.Drift) == 2 {
+		d := c.Drift
+		parts = append(parts, fmt.Sprintf("дрейф %.2f с/сут по точкам %s → %s и %s → %s",
+			c.DriftRate(),
+			d[0].Device.Format(time.DateTime), d[0].Reference.Format(time.DateTime),
+			d[1].Device.Format(time.DateTime), d[1].Reference.Format(time.DateTime)))
+	}
+	return strings.Join(parts, ", ")
+}
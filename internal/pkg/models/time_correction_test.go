@@ -0,0 +1,94 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTimeCorrectionApply(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse(time.DateTime, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	// часы прибора уходят вперёд на 60 с за сутки и в начале отставали на 10 с
+	drift := []TimeReference{
+		{Device: at("2025-03-01 00:00:00"), Reference: at("2025-03-01 00:00:10")},
+		{Device: at("2025-03-02 00:01:00"), Reference: at("2025-03-02 00:00:10")},
+	}
+
+	tests := []struct {
+		name string
+		c    TimeCorrection
+		in   string
+		want string
+	}{
+		{name: "без поправки", in: "2025-03-01 12:00:00", want: "2025-03-01 12:00:00"},
+		{name: "сдвиг вперёд", c: TimeCorrection{Offset: 90 * time.Second}, in: "2025-03-01 12:00:00", want: "2025-03-01 12:01:30"},
+		{name: "сдвиг назад", c: TimeCorrection{Offset: -time.Hour}, in: "2025-03-01 00:30:00", want: "2025-02-28 23:30:00"},
+		{name: "первая опорная точка", c: TimeCorrection{Drift: drift}, in: "2025-03-01 00:00:00", want: "2025-03-01 00:00:10"},
+		{name: "вторая опорная точка", c: TimeCorrection{Drift: drift}, in: "2025-03-02 00:01:00", want: "2025-03-02 00:00:10"},
+		{name: "середина интервала", c: TimeCorrection{Drift: drift}, in: "2025-03-01 12:00:30", want: "2025-03-01 12:00:10"},
+		{name: "до первой точки", c: TimeCorrection{Drift: drift}, in: "2025-02-27 23:59:00", want: "2025-02-28 00:00:10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.c.Apply(at(tt.in))
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Apply(%s) = %s; ожидалось %s", tt.in, got.Format(time.DateTime), tt.want)
+			}
+		})
+	}
+
+	rate := TimeCorrection{Drift: drift}.DriftRate()
+	if math.Abs(rate-60) > 1e-9 {
+		t.Errorf("DriftRate() = %v; ожидалось 60", rate)
+	}
+	if rate := (TimeCorrection{Offset: time.Minute}).DriftRate(); rate != 0 {
+		t.Errorf("DriftRate() без опорных точек = %v; ожидалось 0", rate)
+	}
+}
+
+func TestTimeCorrectionValidate(t *testing.T) {
+	t0 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		c    TimeCorrection
+		ok   bool
+	}{
+		{name: "пусто", ok: true},
+		{name: "пояс", c: TimeCorrection{Zone: "Europe/Moscow"}, ok: true},
+		{name: "неизвестный пояс", c: TimeCorrection{Zone: "Europe/Nowhere"}},
+		{name: "одна опорная точка", c: TimeCorrection{Drift: []TimeReference{{Device: t0, Reference: t0}}}},
+		{name: "дрейф со сдвигом", c: TimeCorrection{Offset: time.Second, Drift: []TimeReference{
+			{Device: t0, Reference: t0}, {Device: t0.Add(time.Hour), Reference: t0.Add(time.Hour)},
+		}}},
+		{name: "точки прибора не по порядку", c: TimeCorrection{Drift: []TimeReference{
+			{Device: t0.Add(time.Hour), Reference: t0}, {Device: t0, Reference: t0.Add(time.Hour)},
+		}}},
+		{name: "эталонные точки совпадают", c: TimeCorrection{Drift: []TimeReference{
+			{Device: t0, Reference: t0}, {Device: t0.Add(time.Hour), Reference: t0},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.c.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v; ожидалась ошибка: %v", err, !tt.ok)
+			}
+		})
+	}
+}
+
+func TestTimeCorrectionLocation(t *testing.T) {
+	loc, err := TimeCorrection{Zone: "Asia/Yekaterinburg"}.Location()
+	if err != nil {
+		t.Fatalf("Location: %v", err)
+	}
+	local := time.Date(2025, 3, 1, 12, 0, 0, 0, loc)
+	if want := time.Date(2025, 3, 1, 7, 0, 0, 0, time.UTC); !local.Equal(want) {
+		t.Errorf("12:00 по Екатеринбургу = %s UTC; ожидалось 07:00", local.UTC().Format(time.TimeOnly))
+	}
+}
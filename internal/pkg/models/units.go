@@ -0,0 +1,343 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Quantity — величина, для которой пользователь выбирает единицу измерения.
+// Дебиты жидкости и газа — одна физическая величина, но единицы у них выбираются отдельно.
+type Quantity string
+
+const (
+	QuantityPressure    Quantity = "pressure"
+	QuantityTemperature Quantity = "temperature"
+	QuantityLiquidRate  Quantity = "liquid_rate"
+	QuantityGasRate     Quantity = "gas_rate"
+	QuantityDensity     Quantity = "density"
+)
+
+// Quantities перечисляет величины в порядке вывода в формах.
+var Quantities = []Quantity{QuantityPressure, QuantityTemperature, QuantityLiquidRate, QuantityGasRate, QuantityDensity}
+
+// Title возвращает название величины для интерфейса.
+func (q Quantity) Title() string {
+	switch q {
+	case QuantityPressure:
+		return "Давление"
+	case QuantityTemperature:
+		return "Температура"
+	case QuantityLiquidRate:
+		return "Дебит жидкости"
+	case QuantityGasRate:
+		return "Дебит газа"
+	case QuantityDensity:
+		return "Плотность"
+	default:
+		return string(q)
+	}
+}
+
+// Unit — единица измерения. Значения давления совпадают с прежними строками "kgf/cm2", "bar", "atm".
+type Unit string
+
+const (
+	UnitPa     Unit = "Pa"
+	UnitKPa    Unit = "kPa"
+	UnitMPa    Unit = "MPa"
+	UnitKgfCm2 Unit = "kgf/cm2"
+	UnitBar    Unit = "bar"
+	UnitAtm    Unit = "atm"
+	UnitPsi    Unit = "psi"
+
+	UnitCelsius    Unit = "degC"
+	UnitFahrenheit Unit = "degF"
+	UnitKelvin     Unit = "K"
+
+	UnitM3PerDay         Unit = "m3/d"
+	UnitThousandM3PerDay Unit = "1000m3/d"
+	UnitBblPerDay        Unit = "bbl/d"
+	UnitMscfPerDay       Unit = "Mscf/d"
+
+	UnitKgPerM3 Unit = "kg/m3"
+	UnitGPerCm3 Unit = "g/cm3"
+)
+
+// dimension — физическая размерность: переводить можно только единицы одной размерности.
+type dimension int
+
+const (
+	dimPressure dimension = iota + 1
+	dimTemperature
+	dimRate
+	dimDensity
+)
+
+// unitInfo описывает перевод в базовую единицу размерности: base = v·scale + offset.
+// Базовые единицы: Па, К, м³/сут, кг/м³.
+type unitInfo struct {
+	dim     dimension
+	symbol  string
+	scale   float64
+	offset  float64
+	aliases []string // как единица пишется в заголовках колонок, после normalizeUnitText
+	words   []string // короткие обозначения, которые засчитываются только отдельным словом
+}
+
+var unitRegistry = map[Unit]unitInfo{
+	UnitPa:     {dim: dimPressure, symbol: "Па", scale: 1},
+	UnitKPa:    {dim: dimPressure, symbol: "кПа", scale: 1e3, aliases: []string{"кпа", "kpa"}},
+	UnitMPa:    {dim: dimPressure, symbol: "МПа", scale: 1e6, aliases: []string{"мпа", "mpa"}},
+	UnitKgfCm2: {dim: dimPressure, symbol: "кгс/см²", scale: 98066.5, aliases: []string{"кгс/см", "kgf/cm"}},
+	UnitBar:    {dim: dimPressure, symbol: "бар", scale: 1e5, aliases: []string{"бар", "bar"}},
+	UnitAtm:    {dim: dimPressure, symbol: "атм", scale: 101325, aliases: []string{"атм", "atm"}},
+	UnitPsi:    {dim: dimPressure, symbol: "psi", scale: 6894.757293168, aliases: []string{"psi", "фунт/дюйм"}},
+
+	UnitCelsius:    {dim: dimTemperature, symbol: "°C", scale: 1, offset: 273.15, aliases: []string{"°c", "degc", "цельс"}},
+	UnitFahrenheit: {dim: dimTemperature, symbol: "°F", scale: 5.0 / 9, offset: 273.15 - 32*5.0/9, aliases: []string{"°f", "degf", "фаренг"}},
+	UnitKelvin:     {dim: dimTemperature, symbol: "K", scale: 1, aliases: []string{"°k", "кельв", "kelvin"}, words: []string{"k", "к"}},
+
+	UnitM3PerDay:         {dim: dimRate, symbol: "м³/сут", scale: 1, aliases: []string{"м3/сут", "m3/d", "м3/д"}},
+	UnitThousandM3PerDay: {dim: dimRate, symbol: "тыс. м³/сут", scale: 1e3, aliases: []string{"тыс.м3", "тысм3", "1000м3", "1000m3"}},
+	UnitBblPerDay:        {dim: dimRate, symbol: "bbl/сут", scale: 0.158987294928, aliases: []string{"bbl", "барр", "stb"}},
+	UnitMscfPerDay:       {dim: dimRate, symbol: "Mscf/сут", scale: 28.316846592, aliases: []string{"mscf", "тыс.фут3"}},
+
+	UnitKgPerM3: {dim: dimDensity, symbol: "кг/м³", scale: 1, aliases: []string{"кг/м3", "kg/m3"}},
+	UnitGPerCm3: {dim: dimDensity, symbol: "г/см³", scale: 1e3, aliases: []string{"г/см3", "g/cm3", "г/cм3"}},
+}
+
+// quantityUnits — единицы, из которых выбирают для величины; порядок важен и для
+// распознавания в заголовках: «тыс. м³/сут» проверяется раньше «м³/сут».
+var quantityUnits = map[Quantity][]Unit{
+	QuantityPressure:    {UnitKgfCm2, UnitMPa, UnitKPa, UnitBar, UnitAtm, UnitPsi},
+	QuantityTemperature: {UnitCelsius, UnitFahrenheit, UnitKelvin},
+	QuantityLiquidRate:  {UnitM3PerDay, UnitBblPerDay},
+	QuantityGasRate:     {UnitThousandM3PerDay, UnitMscfPerDay, UnitM3PerDay},
+	QuantityDensity:     {UnitKgPerM3, UnitGPerCm3},
+}
+
+// UnitsOf возвращает единицы, допустимые для величины.
+func UnitsOf(q Quantity) []Unit {
+	return quantityUnits[q]
+}
+
+// Symbol возвращает обозначение единицы для подписей.
+func (u Unit) Symbol() string {
+	if info, ok := unitRegistry[u]; ok {
+		return info.symbol
+	}
+	return string(u)
+}
+
+// Valid сообщает, что единица есть в реестре.
+func (u Unit) Valid() bool {
+	_, ok := unitRegistry[u]
+	return ok
+}
+
+// Convert переводит значение из одной единицы в другую той же размерности.
+// Для температуры учитывается сдвиг шкалы, поэтому разности температур так переводить нельзя.
+func Convert(v float64, from, to Unit) (float64, error) {
+	if from == to {
+		return v, nil
+	}
+	f, ok := unitRegistry[from]
+	if !ok {
+		return v, errors.Newf("неизвестная единица измерения %q", from)
+	}
+	t, ok := unitRegistry[to]
+	if !ok {
+		return v, errors.Newf("неизвестная единица измерения %q", to)
+	}
+	if f.dim != t.dim {
+		return v, errors.Newf("нельзя перевести %s в %s", f.symbol, t.symbol)
+	}
+	return (v*f.scale + f.offset - t.offset) / t.scale, nil
+}
+
+// DetectUnit ищет в заголовке колонки обозначение единицы величины q, например «Рзаб, бар».
+// Единица ищется только после названия — за первой запятой или скобкой, чтобы «Ратм» не читалось как атмосферы.
+// Однобуквенные обозначения вроде «К» засчитываются только отдельным словом: «Т (датчик)» — не кельвины.
+func DetectUnit(header string, q Quantity) (Unit, bool) {
+	i := strings.IndexAny(header, ",([")
+	if i < 0 {
+		return "", false
+	}
+	tail := header[i+1:]
+	text := normalizeUnitText(tail)
+	words := strings.FieldsFunc(strings.ToLower(tail), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, u := range quantityUnits[q] {
+		info := unitRegistry[u]
+		for _, alias := range info.aliases {
+			if strings.Contains(text, alias) {
+				return u, true
+			}
+		}
+		for _, w := range info.words {
+			if slices.Contains(words, w) {
+				return u, true
+			}
+		}
+	}
+	return "", false
+}
+
+var unitTextReplacer = strings.NewReplacer(" ", "", "\u00a0", "", "³", "3", "²", "2", "°с", "°c", "º", "°")
+
+// normalizeUnitText приводит заголовок к виду, в котором записаны псевдонимы единиц:
+// строчные буквы без пробелов, «³» и «²» — цифрами, кириллическая «с» после градуса — латинской.
+func normalizeUnitText(s string) string {
+	return unitTextReplacer.Replace(strings.ToLower(s))
+}
+
+// FieldQuantities — поля блоков, значения которых имеют единицу измерения.
+var FieldQuantities = map[string]Quantity{
+	"PressureDepth":    QuantityPressure,
+	"TemperatureDepth": QuantityTemperature,
+	"PressureTubing":   QuantityPressure,
+	"PressureAnnulus":  QuantityPressure,
+	"PressureLinear":   QuantityPressure,
+	"LiquidFlowRate":   QuantityLiquidRate,
+	"GasFlowRate":      QuantityGasRate,
+}
+
+// UnitSystem — единицы, в которых показываются и выгружаются величины.
+// Пустое поле означает единицу хранения, поэтому нулевое значение совпадает с StorageUnits.
+type UnitSystem struct {
+	Pressure    Unit
+	Temperature Unit
+	LiquidRate  Unit
+	GasRate     Unit
+	Density     Unit
+}
+
+// StorageUnits — единицы, в которых данные лежат в хранилище и с которыми работают расчёты.
+// Импорт переводит значения в них из единиц файла.
+var StorageUnits = UnitSystem{
+	Pressure:    UnitKgfCm2,
+	Temperature: UnitCelsius,
+	LiquidRate:  UnitM3PerDay,
+	GasRate:     UnitThousandM3PerDay,
+	Density:     UnitKgPerM3,
+}
+
+// UnitPreset — готовый набор единиц.
+type UnitPreset struct {
+	Name  string
+	Units UnitSystem
+}
+
+// UnitPresets — наборы единиц, из которых пользователь выбирает систему отображения.
+var UnitPresets = []UnitPreset{
+	{Name: "Промысловая (кгс/см², °C, м³/сут)", Units: StorageUnits},
+	{Name: "СИ (МПа, °C, м³/сут)", Units: UnitSystem{
+		Pressure: UnitMPa, Temperature: UnitCelsius, LiquidRate: UnitM3PerDay, GasRate: UnitThousandM3PerDay, Density: UnitKgPerM3,
+	}},
+	{Name: "Бары (бар, °C, м³/сут, г/см³)", Units: UnitSystem{
+		Pressure: UnitBar, Temperature: UnitCelsius, LiquidRate: UnitM3PerDay, GasRate: UnitThousandM3PerDay, Density: UnitGPerCm3,
+	}},
+	{Name: "Американская (psi, °F, bbl/сут)", Units: UnitSystem{
+		Pressure: UnitPsi, Temperature: UnitFahrenheit, LiquidRate: UnitBblPerDay, GasRate: UnitMscfPerDay, Density: UnitGPerCm3,
+	}},
+}
+
+// Unit возвращает единицу величины q; для незаданной — единицу хранения.
+func (s UnitSystem) Unit(q Quantity) Unit {
+	var u Unit
+	switch q {
+	case QuantityPressure:
+		u = s.Pressure
+	case QuantityTemperature:
+		u = s.Temperature
+	case QuantityLiquidRate:
+		u = s.LiquidRate
+	case QuantityGasRate:
+		u = s.GasRate
+	case QuantityDensity:
+		u = s.Density
+	}
+	if u == "" {
+		return StorageUnits.Unit(q) // у StorageUnits заданы все поля
+	}
+	return u
+}
+
+// With возвращает копию системы, в которой у величины q единица u.
+func (s UnitSystem) With(q Quantity, u Unit) UnitSystem {
+	switch q {
+	case QuantityPressure:
+		s.Pressure = u
+	case QuantityTemperature:
+		s.Temperature = u
+	case QuantityLiquidRate:
+		s.LiquidRate = u
+	case QuantityGasRate:
+		s.GasRate = u
+	case QuantityDensity:
+		s.Density = u
+	}
+	return s
+}
+
+// Resolved возвращает систему, в которой незаданные единицы заменены единицами хранения.
+func (s UnitSystem) Resolved() UnitSystem {
+	for _, q := range Quantities {
+		s = s.With(q, s.Unit(q))
+	}
+	return s
+}
+
+// Symbol возвращает обозначение единицы величины q.
+func (s UnitSystem) Symbol(q Quantity) string {
+	return s.Unit(q).Symbol()
+}
+
+// Validate проверяет, что каждая заданная единица подходит своей величине.
+func (s UnitSystem) Validate() error {
+	for _, q := range Quantities {
+		u := s.Unit(q)
+		allowed := false
+		for _, a := range quantityUnits[q] {
+			allowed = allowed || a == u
+		}
+		if !allowed {
+			return errors.Newf("%s: единица %q не подходит", q.Title(), u)
+		}
+	}
+	return nil
+}
+
+// FromStorage переводит значение величины q из единицы хранения в единицу системы.
+// Система проверяется заранее (Validate), поэтому ошибка перевода здесь невозможна.
+func (s UnitSystem) FromStorage(q Quantity, v float64) float64 {
+	out, _ := Convert(v, StorageUnits.Unit(q), s.Unit(q))
+	return out
+}
+
+// ToStorage переводит значение величины q из единицы системы в единицу хранения.
+func (s UnitSystem) ToStorage(q Quantity, v float64) float64 {
+	out, _ := Convert(v, s.Unit(q), StorageUnits.Unit(q))
+	return out
+}
+
+// Scale возвращает множитель перевода разностей величины q из единицы хранения в единицу системы:
+// для давления совпадает с FromStorage, для температуры не учитывает сдвиг шкалы.
+func (s UnitSystem) Scale(q Quantity) float64 {
+	from, to := unitRegistry[StorageUnits.Unit(q)], unitRegistry[s.Unit(q)]
+	if to.scale == 0 {
+		return 1
+	}
+	return from.scale / to.scale
+}
+
+// String перечисляет единицы системы для журналов и отчётов.
+func (s UnitSystem) String() string {
+	parts := make([]string, 0, len(Quantities))
+	for _, q := range Quantities {
+		parts = append(parts, strings.ToLower(q.Title())+" "+s.Symbol(q))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDetectUnit(t *testing.T) {
+	tests := []struct {
+		header string
+		q      Quantity
+		want   Unit
+		ok     bool
+	}{
+		{header: "Рзаб, бар", q: QuantityPressure, want: UnitBar, ok: true},
+		{header: "Давление (устье), кгс/см2", q: QuantityPressure, want: UnitKgfCm2, ok: true},
+		{header: "Рзаб (МПа)", q: QuantityPressure, want: UnitMPa, ok: true},
+		{header: "Ратм", q: QuantityPressure},
+		{header: "Давление (устье), кгс/см2", q: QuantityTemperature},
+		{header: "Т, °С", q: QuantityTemperature, want: UnitCelsius, ok: true},
+		{header: "T (degF)", q: QuantityTemperature, want: UnitFahrenheit, ok: true},
+		{header: "Т, К", q: QuantityTemperature, want: UnitKelvin, ok: true},
+		{header: "T [K]", q: QuantityTemperature, want: UnitKelvin, ok: true},
+		{header: "Т, кельвин", q: QuantityTemperature, want: UnitKelvin, ok: true},
+		{header: "Т (датчик)", q: QuantityTemperature},
+		{header: "Tзаб (скв.)", q: QuantityTemperature},
+		{header: "Т (датчик), °C", q: QuantityTemperature, want: UnitCelsius, ok: true},
+		{header: "Qг, тыс. м3/сут", q: QuantityGasRate, want: UnitThousandM3PerDay, ok: true},
+		{header: "Qг, м3/сут", q: QuantityGasRate, want: UnitM3PerDay, ok: true},
+		{header: "Qж, bbl/d", q: QuantityLiquidRate, want: UnitBblPerDay, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := DetectUnit(tt.header, tt.q)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("DetectUnit(%q, %s) = %q, %v; ожидалось %q, %v", tt.header, tt.q, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        float64
+		from, to Unit
+		want     float64
+	}{
+		{name: "та же единица", v: 12.5, from: UnitBar, to: UnitBar, want: 12.5},
+		{name: "бар в МПа", v: 10, from: UnitBar, to: UnitMPa, want: 1},
+		{name: "кгс/см² в Па", v: 1, from: UnitKgfCm2, to: UnitPa, want: 98066.5},
+		{name: "атм в бар", v: 1, from: UnitAtm, to: UnitBar, want: 1.01325},
+		{name: "psi в кПа", v: 100, from: UnitPsi, to: UnitKPa, want: 689.4757293168},
+		{name: "°C в K", v: 20, from: UnitCelsius, to: UnitKelvin, want: 293.15},
+		{name: "°F в °C", v: 212, from: UnitFahrenheit, to: UnitCelsius, want: 100},
+		{name: "K в °F", v: 273.15, from: UnitKelvin, to: UnitFahrenheit, want: 32},
+		{name: "тыс. м³/сут в м³/сут", v: 2.5, from: UnitThousandM3PerDay, to: UnitM3PerDay, want: 2500},
+		{name: "г/см³ в кг/м³", v: 0.85, from: UnitGPerCm3, to: UnitKgPerM3, want: 850},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.v, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9*math.Max(1, math.Abs(tt.want)) {
+				t.Errorf("Convert(%v, %s, %s) = %v; ожидалось %v", tt.v, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to Unit
+	}{
+		{name: "разные размерности", from: UnitBar, to: UnitCelsius},
+		{name: "неизвестная исходная", from: "furlong", to: UnitBar},
+		{name: "неизвестная целевая", from: UnitBar, to: "furlong"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Convert(1, tt.from, tt.to); err == nil {
+				t.Errorf("Convert(1, %s, %s): ожидалась ошибка", tt.from, tt.to)
+			}
+		})
+	}
+}
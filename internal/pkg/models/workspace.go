@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ResearchSummary — исследование рабочего пространства для списка: название, шапка отчёта и размеры блоков.
+type ResearchSummary struct {
+	ID      int
+	Name    string
+	Report  TableFive // шапка отчёта (Блок 5); нулевая, пока не заполнена
+	Rows    [4]int    // строк в блоках 1–4
+	Active  bool
+	Pending bool // есть незаписанные в историю изменения: идёт импорт или его отчёт ещё не принят
+	// Revision растёт при каждом изменении данных и настроек исследования, включая правку значений,
+	// отмену и повтор: по нему видно, что сессию пора сохранить
+	Revision int
+}
+
+// Total возвращает общее число строк блоков 1–4.
+func (r ResearchSummary) Total() int {
+	return r.Rows[0] + r.Rows[1] + r.Rows[2] + r.Rows[3]
+}
+
+// Title возвращает название исследования со скважиной из шапки отчёта, если она заполнена.
+func (r ResearchSummary) Title() string {
+	if r.Report.FieldName == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s (%s, скв. %d)", r.Name, r.Report.FieldName, r.Report.FieldNumber)
+}
+
+// ComparisonSeries — ряд величины одного исследования. Values — в единицах хранения, NaN — пропуск.
+type ComparisonSeries struct {
+	Research string // подпись исследования на графике и в заголовке колонки
+	Times    []time.Time
+	Values   []float64
+}
+
+// Elapsed возвращает часы от первого замера ряда до замера i.
+func (s ComparisonSeries) Elapsed(i int) float64 {
+	return s.Times[i].Sub(s.Times[0]).Hours()
+}
+
+// Comparison — одна величина нескольких исследований на общем графике, например Рзаб соседних скважин.
+type Comparison struct {
+	Channel Channel
+	// Relative — ось времени в часах от первого замера каждого исследования, чтобы совместить
+	// исследования разных дат; false — календарное время.
+	Relative bool
+	Series   []ComparisonSeries
+}
+
+// Len возвращает наибольшее число замеров среди рядов.
+func (c Comparison) Len() int {
+	n := 0
+	for _, s := range c.Series {
+		n = max(n, len(s.Times))
+	}
+	return n
+}
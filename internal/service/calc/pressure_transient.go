@@ -0,0 +1,393 @@
+package calc
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// BuildUp рассчитывает Δp и производную Бурде для периода простоя (КВД).
+//
+// Δp отсчитывается от давления на момент остановки — последнего замера не позже period.Start.
+// Берётся давление на глубине замера: в пределах одного периода плотность постоянна,
+// поэтому Δp на ВДП совпадает. Для времени суперпозиции нужны дебиты жидкости блока 3
+// до остановки: запись действует от своей метки времени до следующей.
+func BuildUp(data []models.TableOne, period models.OperationPeriod, rates []models.TableThree,
+	opts models.BuildUpOptions) (models.BuildUp, error) {
+	res := models.BuildUp{Period: period, Options: opts, ShutInTime: period.Start}
+	if period.Kind != models.PeriodIdle {
+		return res, errors.Newf("период %s — не простой, КВД не строится", period.Label)
+	}
+	if opts.Smoothing < 0 {
+		return res, errors.New("окно сглаживания L не может быть отрицательным")
+	}
+
+	sorted := make([]models.TableOne, len(data))
+	copy(sorted, data)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	// первый замер после остановки; замер перед ним даёт давление на момент остановки
+	from := sort.Search(len(sorted), func(i int) bool { return sorted[i].Timestamp.After(period.Start) })
+	switch {
+	case from > 0:
+		res.ShutInP = sorted[from-1].PressureDepth
+	case from < len(sorted):
+		res.ShutInP = sorted[from].PressureDepth
+	default:
+		return res, errors.Newf("в периоде %s нет замеров блока 1", period.Label)
+	}
+
+	timeFunc := func(dt float64) float64 { return math.Log(dt) }
+	if opts.Time == models.TimeSuperposition {
+		f, produced, err := superposition(rates, period)
+		if err != nil {
+			return res, err
+		}
+		timeFunc, res.ProducedTime = f, produced
+	}
+
+	for _, rec := range sorted[from:] {
+		if rec.Timestamp.After(period.End) {
+			break
+		}
+		dt := rec.Timestamp.Sub(period.Start).Hours()
+		res.Points = append(res.Points, models.BuildUpPoint{
+			Timestamp: rec.Timestamp,
+			Elapsed:   dt,
+			TimeFunc:  timeFunc(dt),
+			DeltaP:    rec.PressureDepth - res.ShutInP,
+		})
+	}
+	if len(res.Points) < 3 {
+		return res, errors.Newf("в периоде %s меньше трёх замеров блока 1", period.Label)
+	}
+
+	bourdet(res.Points, opts.Smoothing)
+	return res, nil
+}
+
+// superposition возвращает функцию времени суперпозиции для КВД после истории дебитов:
+//
+//	X(Δt) = ln Δt − Σ (qᵢ − qᵢ₋₁)/q_N · ln(t_N − tᵢ₋₁ + Δt),
+//
+// где q_N — дебит перед остановкой, t_N − tᵢ₋₁ — время от начала i-го режима до остановки.
+// Для одного режима это ln(Δt/(tp + Δt)) — логарифм эквивалентного времени Агарвала без константы.
+func superposition(rates []models.TableThree, period models.OperationPeriod) (func(float64) float64, float64, error) {
+	sorted := make([]models.TableThree, 0, len(rates))
+	for _, r := range rates {
+		if r.Timestamp.Before(period.Start) {
+			sorted = append(sorted, r)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil, 0, errors.Newf("нет дебитов блока 3 до начала периода %s: время суперпозиции не рассчитать", period.Label)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	qN := sorted[len(sorted)-1].LiquidFlowRate
+	if qN == 0 {
+		return nil, 0, errors.Newf("дебит жидкости перед периодом %s равен нулю: время суперпозиции не рассчитать", period.Label)
+	}
+
+	weights := make([]float64, len(sorted)) // (qᵢ − qᵢ₋₁)/q_N
+	offsets := make([]float64, len(sorted)) // t_N − tᵢ₋₁, часы
+	prev := 0.0
+	for i, r := range sorted {
+		weights[i] = (r.LiquidFlowRate - prev) / qN
+		offsets[i] = period.Start.Sub(r.Timestamp).Hours()
+		prev = r.LiquidFlowRate
+	}
+
+	f := func(dt float64) float64 {
+		x := math.Log(dt)
+		for i, w := range weights {
+			if w != 0 {
+				x -= w * math.Log(offsets[i]+dt)
+			}
+		}
+		return x
+	}
+	return f, offsets[0], nil
+}
+
+// bourdet заполняет производную Бурде. Для каждой точки берутся ближайшие соседи слева и справа,
+// отстоящие по функции времени не меньше чем на L; если такого нет, берётся крайняя точка.
+// Производная — взвешенное среднее наклонов слева и справа:
+//
+//	p' = (Δp_л/ΔX_л·ΔX_п + Δp_п/ΔX_п·ΔX_л) / (ΔX_л + ΔX_п).
+//
+// На краях остаётся односторонний наклон. Функция времени должна возрастать.
+func bourdet(points []models.BuildUpPoint, l float64) {
+	n := len(points)
+	left, right := 0, 0
+	for i := range points {
+		x := points[i].TimeFunc
+		// left — самая правая точка, отстоящая от i не меньше чем на L
+		for left+1 < i && x-points[left+1].TimeFunc >= l {
+			left++
+		}
+		// right — самая левая точка, отстоящая от i не меньше чем на L; при L = 0 — следующая
+		right = max(right, min(i+1, n-1))
+		for right < n-1 && points[right].TimeFunc-x < l {
+			right++
+		}
+
+		var (
+			slopeL, slopeR float64
+			dxL, dxR       float64
+			hasL, hasR     bool
+		)
+		if left < i {
+			dxL = x - points[left].TimeFunc
+			if dxL > 0 {
+				slopeL, hasL = (points[i].DeltaP-points[left].DeltaP)/dxL, true
+			}
+		}
+		if right > i {
+			dxR = points[right].TimeFunc - x
+			if dxR > 0 {
+				slopeR, hasR = (points[right].DeltaP-points[i].DeltaP)/dxR, true
+			}
+		}
+
+		switch {
+		case hasL && hasR:
+			points[i].Derivative = (slopeL*dxR + slopeR*dxL) / (dxL + dxR)
+		case hasL:
+			points[i].Derivative = slopeL
+		case hasR:
+			points[i].Derivative = slopeR
+		default:
+			points[i].Derivative = math.NaN()
+		}
+	}
+}
+
+const (
+	semilogSmoothing  = 0.2          // окно L производной для поиска участка радиального притока
+	radialStep        = 0.05         // прореживание по lg Δt перед поиском участка
+	radialMaxRatio    = 1.25         // производная на участке меняется не больше чем в столько раз
+	radialMinPoints   = 5            // точек на участке после прореживания
+	millidarcy        = 9.869233e-16 // 1 мД в м²
+	secondsPerDay     = 86400.0
+	lineSourceTDConst = 0.3514 // lg(4/γ), γ = e^0.5772
+)
+
+// Semilog интерпретирует КВД по прямой на полулогарифмическом графике методом Хорнера или MDH.
+//
+// Время работы tp = Qнакоп/q считается по дебитам жидкости блока 3 до остановки, q — последний дебит.
+// Участок радиального притока, если не задан, выбирается там, где производная Бурде почти постоянна:
+// по времени суперпозиции для Хорнера и по Δt для MDH. По наклону m прямой на логарифмический цикл (СИ):
+//
+//	kh = 2,303·q·Bo·μ / (4π·m),   s = 1,1513·[(p1ч − p(Δt=0))/m − lg(k·t1ч/(φ·μ·ct·rw²)) − 0,3514].
+//
+// Давления считаются на глубине замера в единицах хранения; p* дополнительно приводится к ВДП
+// по плотности жидкости в простое и отметкам TVD из блока 5.
+func Semilog(data []models.TableOne, period models.OperationPeriod, rates []models.TableThree,
+	report models.TableFive, opts models.SemilogOptions) (models.SemilogResult, error) {
+	res := models.SemilogResult{
+		Method:    opts.Method,
+		Period:    period,
+		Reservoir: opts.Reservoir,
+		PStar:     math.NaN(),
+		PStarVDP:  math.NaN(),
+	}
+	if err := validateReservoir(opts.Reservoir); err != nil {
+		return res, err
+	}
+
+	var err error
+	if res.Rate, res.ProducingTime, err = producingTime(rates, period); err != nil {
+		return res, err
+	}
+
+	// MDH пренебрегает историей дебитов, поэтому и участок ищется по производной от Δt
+	timeFunc := models.TimeSuperposition
+	if opts.Method == models.MethodMDH {
+		timeFunc = models.TimeElapsed
+	}
+	bu, err := BuildUp(data, period, rates, models.BuildUpOptions{Smoothing: semilogSmoothing, Time: timeFunc})
+	if err != nil {
+		return res, err
+	}
+	res.ShutInP = bu.ShutInP
+
+	res.FromHours, res.ToHours = opts.FromHours, opts.ToHours
+	if res.FromHours == 0 && res.ToHours == 0 {
+		var ok bool
+		if res.FromHours, res.ToHours, ok = radialFlowSegment(bu.Points); !ok {
+			return res, errors.New("не удалось выделить участок радиального притока по производной, задайте его вручную")
+		}
+	}
+	if res.FromHours < 0 || res.ToHours <= res.FromHours {
+		return res, errors.New("участок радиального притока: конец должен быть позже начала")
+	}
+
+	var xs, ys []float64
+	for _, p := range bu.Points {
+		if p.Elapsed <= 0 {
+			continue
+		}
+		pt := models.SemilogPoint{
+			Elapsed:   p.Elapsed,
+			X:         p.Elapsed,
+			Pressure:  res.ShutInP + p.DeltaP,
+			InSegment: p.Elapsed >= res.FromHours && p.Elapsed <= res.ToHours,
+		}
+		if opts.Method == models.MethodHorner {
+			pt.X = (res.ProducingTime + p.Elapsed) / p.Elapsed
+		}
+		if pt.InSegment {
+			xs = append(xs, math.Log10(pt.X))
+			ys = append(ys, pt.Pressure)
+		}
+		res.Points = append(res.Points, pt)
+	}
+	if len(xs) < 3 {
+		return res, errors.Newf("на участке %g–%g ч меньше трёх замеров", res.FromHours, res.ToHours)
+	}
+
+	var slope float64
+	slope, res.Intercept, res.R2 = fitLine(xs, ys)
+	res.Slope = math.Abs(slope)
+	if res.Slope == 0 {
+		return res, errors.New("давление на участке не меняется: наклон прямой равен нулю")
+	}
+	if opts.Method == models.MethodHorner {
+		res.PStar = res.Intercept
+		res.P1h = res.Intercept + slope*math.Log10(res.ProducingTime+1)
+	} else {
+		res.P1h = res.Intercept
+	}
+
+	// kh и скин — в СИ
+	r := opts.Reservoir
+	q := res.Rate * r.VolumeFactor / secondsPerDay // м³/с в пласте
+	mu := r.Viscosity * 1e-3                       // Па·с
+	ct := r.Compressibility * 1e-6                 // 1/Па
+	m := toPa(res.Slope)
+	kh := math.Ln10 * q * mu / (4 * math.Pi * m) // м³
+	k := kh / r.Thickness                        // м²
+	res.PermeabilityThickness = kh / millidarcy
+	res.Permeability = k / millidarcy
+	res.Skin = 1.1513 * ((res.P1h-res.ShutInP)/res.Slope -
+		math.Log10(k*3600/(r.Porosity*mu*ct*r.WellRadius*r.WellRadius)) - lineSourceTDConst)
+
+	if !math.IsNaN(res.PStar) && report.TrueVerticalDepth != nil && report.VDPTrueVerticalDepth != nil &&
+		report.DensityLiquidStopped > 0 {
+		dh := *report.VDPTrueVerticalDepth - *report.TrueVerticalDepth // ВДП обычно ниже прибора
+		res.PStarVDP = res.PStar + fromPa(report.DensityLiquidStopped*g*dh)
+	}
+	return res, nil
+}
+
+func validateReservoir(r models.ReservoirParams) error {
+	var missing []string
+	for _, f := range []struct {
+		name string
+		v    float64
+	}{
+		{"толщина h", r.Thickness},
+		{"пористость φ", r.Porosity},
+		{"вязкость μ", r.Viscosity},
+		{"объёмный коэффициент Bo", r.VolumeFactor},
+		{"сжимаемость ct", r.Compressibility},
+		{"радиус скважины rw", r.WellRadius},
+	} {
+		if !(f.v > 0) {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Newf("не заданы свойства пласта: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// producingTime возвращает дебит жидкости перед остановкой и эквивалентное время работы tp = Qнакоп/q в часах.
+func producingTime(rates []models.TableThree, period models.OperationPeriod) (q, tp float64, err error) {
+	var before []models.TableThree
+	for _, r := range rates {
+		if r.Timestamp.Before(period.Start) {
+			before = append(before, r)
+		}
+	}
+	if len(before) == 0 {
+		return 0, 0, errors.Newf("нет дебитов блока 3 до начала периода %s", period.Label)
+	}
+	sort.SliceStable(before, func(i, j int) bool { return before[i].Timestamp.Before(before[j].Timestamp) })
+
+	q = before[len(before)-1].LiquidFlowRate
+	if q <= 0 {
+		return 0, 0, errors.Newf("дебит жидкости перед периодом %s не положителен", period.Label)
+	}
+	var cumulative float64 // м³/сут·ч
+	for i, r := range before {
+		end := period.Start
+		if i+1 < len(before) {
+			end = before[i+1].Timestamp
+		}
+		cumulative += r.LiquidFlowRate * end.Sub(r.Timestamp).Hours()
+	}
+	return q, cumulative / q, nil
+}
+
+// radialFlowSegment ищет самый длинный по lg Δt участок, где производная Бурде меняется
+// не больше чем в radialMaxRatio раз. Точки предварительно прореживаются по lg Δt.
+func radialFlowSegment(points []models.BuildUpPoint) (from, to float64, ok bool) {
+	var sparse []models.BuildUpPoint
+	last := math.Inf(-1)
+	for _, p := range points {
+		if p.Elapsed <= 0 || !(p.Derivative > 0) {
+			continue
+		}
+		if lg := math.Log10(p.Elapsed); lg >= last+radialStep {
+			sparse = append(sparse, p)
+			last = lg
+		}
+	}
+
+	best := 0.0
+	for i := range sparse {
+		lo, hi := sparse[i].Derivative, sparse[i].Derivative
+		for j := i + 1; j < len(sparse); j++ {
+			lo, hi = min(lo, sparse[j].Derivative), max(hi, sparse[j].Derivative)
+			if hi > radialMaxRatio*lo {
+				break
+			}
+			span := math.Log10(sparse[j].Elapsed / sparse[i].Elapsed)
+			if j-i+1 >= radialMinPoints && span > best {
+				best, from, to, ok = span, sparse[i].Elapsed, sparse[j].Elapsed, true
+			}
+		}
+	}
+	return from, to, ok
+}
+
+// fitLine — линейная регрессия y = slope·x + intercept методом наименьших квадратов.
+func fitLine(xs, ys []float64) (slope, intercept, r2 float64) {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy, syy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+		syy += ys[i] * ys[i]
+	}
+	varX := sxx - sx*sx/n
+	if varX == 0 {
+		return 0, sy / n, 0
+	}
+	cov := sxy - sx*sy/n
+	slope = cov / varX
+	intercept = (sy - slope*sx) / n
+	if varY := syy - sy*sy/n; varY > 0 {
+		r2 = cov * cov / (varX * varY)
+	}
+	return slope, intercept, r2
+}
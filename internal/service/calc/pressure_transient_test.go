@@ -0,0 +1,53 @@
+package calc
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+func TestBourdet(t *testing.T) {
+	// Δt через равные шаги по ln Δt, Δp = X²: на равномерной сетке двусторонняя производная Бурде
+	// для параболы точна (2X), односторонний наклон ошибается на шаг сетки.
+	const n = 41
+	newPoints := func() []models.BuildUpPoint {
+		points := make([]models.BuildUpPoint, n)
+		for i := range points {
+			dt := math.Pow(10, -2+float64(i)/10)
+			x := math.Log(dt)
+			points[i] = models.BuildUpPoint{Elapsed: dt, TimeFunc: x, DeltaP: x * x}
+		}
+		return points
+	}
+
+	tests := []struct {
+		name string
+		l    float64
+	}{
+		{name: "соседние точки", l: 0},
+		{name: "окно меньше шага", l: 0.1},
+		{name: "окно в несколько шагов", l: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := newPoints()
+			bourdet(points, tt.l)
+			first, last := points[0].TimeFunc, points[n-1].TimeFunc
+			checked := 0
+			for i, p := range points {
+				// с обеих сторон есть точки не ближе L: производная двусторонняя
+				if i == 0 || i == n-1 || p.TimeFunc-first < tt.l || last-p.TimeFunc < tt.l {
+					continue
+				}
+				if want := 2 * p.TimeFunc; math.Abs(p.Derivative-want) > 1e-9 {
+					t.Errorf("точка %d (X=%.3f): производная %.6f, ожидалось %.6f", i, p.TimeFunc, p.Derivative, want)
+				}
+				checked++
+			}
+			if checked == 0 {
+				t.Fatal("нет точек с двусторонней производной")
+			}
+		})
+	}
+}
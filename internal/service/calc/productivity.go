@@ -0,0 +1,119 @@
+package calc
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const (
+	stabilizedShare = 0.25 // Рзаб режима усредняется по последней четверти его длительности
+	iprCurvePoints  = 50
+)
+
+// Productivity сопоставляет режимы блока 3 с Рзаб на ВДП блока 1 и рассчитывает коэффициенты
+// продуктивности и индикаторную кривую.
+//
+// Запись блока 3 действует от своей метки времени до следующей, последняя — до конца блока 1;
+// соседние записи с одинаковым дебитом объединяются в один режим. Рзаб берётся средним
+// по стабилизированной (последней) части режима, замеры с NaN на ВДП пропускаются.
+//
+// Кривая композитная: выше Pнас q = J·(Pпл − Pwf), ниже —
+//
+//	q = J·(Pпл − Pнас) + J·Pнас/1,8·[1 − 0,2·(Pwf/Pнас) − 0,8·(Pwf/Pнас)²];
+//
+// при Pнас ≥ Pпл это кривая Вогеля. J подбирается методом наименьших квадратов по всем режимам.
+func Productivity(t1 []models.TableOne, t3 []models.TableThree, opts models.IPROptions) (models.IPR, error) {
+	res := models.IPR{Options: opts}
+	pr := opts.ReservoirPressure
+	if !(pr > 0) {
+		return res, errors.New("не задано пластовое давление")
+	}
+	pb := opts.BubblePointPressure
+	if pb <= 0 || pb > pr {
+		pb = pr
+	}
+	if len(t1) == 0 || len(t3) == 0 {
+		return res, errors.New("для расчёта продуктивности нужны данные блоков 1 и 3")
+	}
+
+	pressures := make([]models.TableOne, len(t1))
+	copy(pressures, t1)
+	sort.SliceStable(pressures, func(i, j int) bool { return pressures[i].Timestamp.Before(pressures[j].Timestamp) })
+	rates := make([]models.TableThree, len(t3))
+	copy(rates, t3)
+	sort.SliceStable(rates, func(i, j int) bool { return rates[i].Timestamp.Before(rates[j].Timestamp) })
+
+	last := pressures[len(pressures)-1].Timestamp
+	for i := 0; i < len(rates); {
+		// режим — подряд идущие записи с одинаковым дебитом
+		j := i + 1
+		for j < len(rates) && rates[j].LiquidFlowRate == rates[i].LiquidFlowRate {
+			j++
+		}
+		step := models.ProductivityStep{Start: rates[i].Timestamp, End: last, Rate: rates[i].LiquidFlowRate}
+		if j < len(rates) {
+			step.End = rates[j].Timestamp
+		}
+		i = j
+		if step.Rate <= 0 || !step.End.After(step.Start) {
+			continue
+		}
+
+		from := step.End.Add(-time.Duration(float64(step.End.Sub(step.Start)) * stabilizedShare))
+		first := sort.Search(len(pressures), func(k int) bool { return !pressures[k].Timestamp.Before(from) })
+		var sum float64
+		for k := first; k < len(pressures) && pressures[k].Timestamp.Before(step.End); k++ {
+			if p := pressures[k].PressureAtVDP; !math.IsNaN(p) {
+				sum += p
+				step.Samples++
+			}
+		}
+		if step.Samples == 0 {
+			continue
+		}
+		step.Pwf = sum / float64(step.Samples)
+		step.Drawdown = pr - step.Pwf
+		step.PI = math.NaN()
+		if step.Drawdown != 0 {
+			step.PI = step.Rate / step.Drawdown
+		}
+		res.Steps = append(res.Steps, step)
+	}
+	if len(res.Steps) == 0 {
+		return res, errors.New("нет режимов с ненулевым дебитом, для которых есть Рзаб на ВДП")
+	}
+
+	// q = J·shape(Pwf): J — МНК без свободного члена
+	shape := func(pwf float64) float64 {
+		if pwf >= pb {
+			return pr - pwf
+		}
+		x := pwf / pb
+		return pr - pb + pb/1.8*(1-0.2*x-0.8*x*x)
+	}
+	var num, den float64
+	for _, s := range res.Steps {
+		f := shape(s.Pwf)
+		num += s.Rate * f
+		den += f * f
+	}
+	if den == 0 {
+		return res, errors.New("Рзаб на всех режимах равно пластовому давлению: кривую не подобрать")
+	}
+	res.J = num / den
+	if res.J <= 0 {
+		return res, errors.New("Рзаб на режимах выше пластового давления: проверьте Pпл")
+	}
+	res.QMax = res.J * shape(0)
+
+	for i := 0; i <= iprCurvePoints; i++ {
+		pwf := pr * float64(iprCurvePoints-i) / iprCurvePoints
+		res.Curve = append(res.Curve, models.IPRPoint{Pwf: pwf, Rate: res.J * shape(pwf)})
+	}
+	return res, nil
+}
@@ -1,47 +1,93 @@
 package calc
 
 import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
 	"github.com/samber/lo"
 
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
 )
 
-// TODO Тип вывода Рзаб на ВПД соотвутствует типу Рзаб на глубине замера
 // TODO Считает полностью все данные, не обрезая по времени
-// единицы измерения должны быть идентичны выбранным ранее единицам измерения давления при импорте Рзаб на глубине
-// замера. Т.е. формула для пересчета должна учитывать разные сценарии (пересчетные коэффициенты) в зависимости от ранее заданных при импорте значений Рзаб (атм,
-// кгс/см2, бар)
-// TableOne
+// Давления хранятся и считаются в models.StorageUnits: импорт переводит их из единиц файла,
+// в единицы отображения их переводят графики, формы и экспорт.
 
 const g = 9.80665 // м/с²
 
 // TableOne применяет гидростатику к одной записи, возвращая с заполненным PressureVPD.
+// Плотность берётся из периода графика, в который попадает замер; для замеров между
+// периодами — по cfg.GapPolicy. Если плотность не определена, PressureAtVDP = NaN.
 func TableOne(rec models.TableOne, cfg models.OperationConfig) models.TableOne {
-	// 1) переводим измеренное давление в Па
-	p0 := toPa(rec.PressureDepth, cfg.PressureUnit)
-
-	// 2) выбираем плотность по времени
-	var rho float64
-	t := rec.Timestamp
-	if t.After(cfg.WorkStart) && t.Before(cfg.WorkEnd) {
-		rho = cfg.WorkDensity
-	} else if t.After(cfg.IdleStart) && t.Before(cfg.IdleEnd) {
-		rho = cfg.IdleDensity
-	} else {
-		// не попадает ни в один период
+	// 1) выбираем плотность по времени
+	rho := densityAt(cfg, rec.Timestamp)
+	if math.IsNaN(rho) {
+		rec.PressureAtVDP = math.NaN()
 		return rec
 	}
 
+	// 2) переводим измеренное давление в Па
+	p0 := toPa(rec.PressureDepth)
+
 	// 3) гидростатическое приращение ΔP = ρ·g·Δh
 	deltaPa := rho * g * cfg.DepthDiff
 
 	// 4) итог в Па и обратно
 	pVpd := p0 + deltaPa
-	v := fromPa(pVpd, cfg.PressureUnit)
-	rec.PressureAtVDP = v
+	rec.PressureAtVDP = fromPa(pVpd)
 	return rec
 }
 
+// densityAt возвращает плотность жидкости на момент t по графику работы.
+// Периоды должны быть упорядочены (OperationConfig.Normalize).
+func densityAt(cfg models.OperationConfig, t time.Time) float64 {
+	periods := cfg.Periods
+	// первый период, который заканчивается не раньше t
+	i := sort.Search(len(periods), func(i int) bool { return !periods[i].End.Before(t) })
+	if i < len(periods) && !t.Before(periods[i].Start) {
+		return periods[i].Density
+	}
+
+	// замер между периодами: prev закончился до t, next начнётся после t
+	var prev, next *models.OperationPeriod
+	if i > 0 {
+		prev = &periods[i-1]
+	}
+	if i < len(periods) {
+		next = &periods[i]
+	}
+
+	switch cfg.GapPolicy {
+	case models.GapCarryForward:
+		if prev != nil {
+			return prev.Density
+		}
+	case models.GapInterpolate:
+		switch {
+		case prev != nil && next != nil:
+			ratio := float64(t.Sub(prev.End)) / float64(next.Start.Sub(prev.End))
+			return prev.Density + ratio*(next.Density-prev.Density)
+		case prev != nil:
+			return prev.Density
+		case next != nil:
+			return next.Density
+		}
+	}
+	return math.NaN()
+}
+
+// TableOneSeries применяет гидростатику ко всем записям блока 1 и возвращает новый срез.
+// В хранилище лежат только измеренные значения, поэтому смена параметров не требует повторного импорта.
+func TableOneSeries(data []models.TableOne, cfg models.OperationConfig) []models.TableOne {
+	out := make([]models.TableOne, len(data))
+	for i, rec := range data {
+		out[i] = TableOne(rec, cfg)
+	}
+	return out
+}
+
 // TableThree рассчитывает дебиты нефти (Qн), воды (Qв) и газовый фактор (ГФ)
 // на основании входных данных TableThree:
 //
@@ -72,91 +118,123 @@ func TableThree(tbl models.TableThree) models.TableThree {
 }
 
 // TableFive calculates automatic fields for TableFive using Block 4 survey data.
-func TableFive(tbl models.TableFive, survey []models.TableFour) models.TableFive {
+//
+// Вместе с отчётом возвращаются проблемы инклинометрии: предупреждения нужно показать пользователю,
+// при ошибках отметки прибора или ВДП не рассчитываются и отчёт сохранять нельзя.
+func TableFive(tbl models.TableFive, survey []models.TableFour, opts models.SurveyOptions) (models.TableFive, []models.SurveyIssue) {
+	// 1. Упорядочиваем инклинометрию и проверяем её
+	stations, issues := prepareSurvey(survey)
+	if len(stations) == 0 {
+		return tbl, issues
+	}
 
 	// 2. Вычисляем TVD и TVDSS для прибора по MD
-	tvd, tvdss := interpolateTVD(survey, tbl.MeasuredDepth)
-	tbl.TrueVerticalDepth = lo.ToPtr(tvd)
-	tbl.TrueVerticalDepthSubSea = lo.ToPtr(tvdss)
+	tvd, tvdss, issue := surveyDepth(stations, tbl.MeasuredDepth, "прибора", opts)
+	if issue != nil {
+		issues = append(issues, *issue)
+	}
+	if issue == nil || !issue.Error {
+		tbl.TrueVerticalDepth = lo.ToPtr(tvd)
+		tbl.TrueVerticalDepthSubSea = lo.ToPtr(tvdss)
+	}
 
 	// 3. Если задана MD перфорации (VDP), рассчитываем ее отметки
 	if tbl.VDPMeasuredDepth > 0 {
-		vdpTVD, vdpTVDSS := interpolateTVD(survey, tbl.VDPMeasuredDepth)
+		vdpTVD, vdpTVDSS, issue := surveyDepth(stations, tbl.VDPMeasuredDepth, "ВДП", opts)
+		if issue != nil {
+			issues = append(issues, *issue)
+			if issue.Error {
+				return tbl, issues
+			}
+		}
 		tbl.VDPTrueVerticalDepth = &vdpTVD
 		tbl.VDPTrueVerticalDepthSea = &vdpTVDSS
 
 		// 4. Разница между прибором и ВДП по абсолютным отметкам (TVDSS)
 		if tbl.TrueVerticalDepthSubSea != nil {
-			delta := *tbl.TrueVerticalDepthSubSea - vdpTVDSS
-			tbl.DiffInstrumentVDP = &delta
-		}
-
-		// 5. Гидростатическое давление (ΔP = ρ * g * Δh)
-		//    g = 9.81 m/s²
-		const g = 9.81
-		var heightDiff float64
-		if tbl.TrueVerticalDepthSubSea != nil {
-			heightDiff = *tbl.TrueVerticalDepthSubSea - vdpTVDSS
+			heightDiff := *tbl.TrueVerticalDepthSubSea - vdpTVDSS
+			tbl.DiffInstrumentVDP = &heightDiff
+
+			// 5. Гидростатическое давление (ΔP = ρ * g * Δh)
+			//    g = 9.81 m/s²
+			const g = 9.81
+			pStopped := tbl.DensityLiquidStopped * g * heightDiff
+			pWorking := tbl.DensityLiquidWorking * g * heightDiff
+			tbl.PressureDiffStopped = &pStopped
+			tbl.PressureDiffWorking = &pWorking
 		}
-		pStopped := tbl.DensityLiquidStopped * g * heightDiff
-		pWorking := tbl.DensityLiquidWorking * g * heightDiff
-		tbl.PressureDiffStopped = &pStopped
-		tbl.PressureDiffWorking = &pWorking
 	}
 
-	return tbl
+	return tbl, issues
 }
 
-// interpolateTVD performs linear interpolation on survey data to find TVD and TVDSS at a given MD.
-func interpolateTVD(survey []models.TableFour, md float64) (tvd, tvdss float64) {
-	if len(survey) == 0 {
-		return 0, 0
+// surveyDepth находит TVD и TVDSS на глубине md по упорядоченной инклинометрии без повторов MD.
+// Между замерами с углами точка лежит на дуге минимальной кривизны, иначе — на хорде.
+// За пределами замеров отметки продолжаются по касательной, если это разрешено, иначе возвращается ошибка.
+func surveyDepth(stations []models.TableFour, md float64, what string, opts models.SurveyOptions) (tvd, tvdss float64, issue *models.SurveyIssue) {
+	first, last := stations[0], stations[len(stations)-1]
+	if md < first.MeasuredDepth || md > last.MeasuredDepth {
+		edge, inner, where := first, (*models.TableFour)(nil), "выше первого"
+		if len(stations) > 1 {
+			inner = &stations[1]
+		}
+		if md > last.MeasuredDepth {
+			edge, inner, where = last, nil, "ниже последнего"
+			if len(stations) > 1 {
+				inner = &stations[len(stations)-2]
+			}
+		}
+		issue = &models.SurveyIssue{Kind: models.SurveyExtrapolated, MD: md}
+		if !opts.Extrapolate {
+			issue.Error = true
+			issue.Message = fmt.Sprintf("MD %s %g м %s замера (%g м): дополните инклинометрию или включите экстраполяцию по касательной",
+				what, md, where, edge.MeasuredDepth)
+			return 0, 0, issue
+		}
+		tvd = edge.TrueVerticalDepth + (md-edge.MeasuredDepth)*edgeSlope(edge, inner)
+		issue.Message = fmt.Sprintf("MD %s %g м %s замера (%g м): TVD продолжена по касательной на %.2f м",
+			what, md, where, edge.MeasuredDepth, math.Abs(md-edge.MeasuredDepth))
+		return tvd, tvd - (edge.TrueVerticalDepth - edge.TrueVerticalDepthSubSea), issue
 	}
 
-	// Если MD меньше минимального, возвращаем первую точку
-	if md <= survey[0].MeasuredDepth {
-		return survey[0].TrueVerticalDepth, survey[0].TrueVerticalDepthSubSea
+	i := sort.Search(len(stations), func(i int) bool { return stations[i].MeasuredDepth >= md })
+	curr := stations[i]
+	if curr.MeasuredDepth == md {
+		return curr.TrueVerticalDepth, curr.TrueVerticalDepthSubSea, nil
 	}
-
-	// Ищем два соседних замера для интерполяции
-	for i := 1; i < len(survey); i++ {
-		prev := survey[i-1]
-		curr := survey[i]
-		if md <= curr.MeasuredDepth {
-			ratio := (md - prev.MeasuredDepth) / (curr.MeasuredDepth - prev.MeasuredDepth)
-			tvd = prev.TrueVerticalDepth + ratio*(curr.TrueVerticalDepth-prev.TrueVerticalDepth)
-			tvdss = prev.TrueVerticalDepthSubSea + ratio*(curr.TrueVerticalDepthSubSea-prev.TrueVerticalDepthSubSea)
-			return tvd, tvdss
-		}
+	prev := stations[i-1]
+	// Если известны углы, точка лежит на дуге минимальной кривизны, иначе — на хорде
+	if prev.Inclination != nil && prev.Azimuth != nil && curr.Inclination != nil && curr.Azimuth != nil {
+		tvd = arcTVD(prev, curr, md)
+		return tvd, tvd - (prev.TrueVerticalDepth - prev.TrueVerticalDepthSubSea), nil
 	}
-
-	// Если MD больше максимального, возвращаем последнюю точку
-	last := survey[len(survey)-1]
-	return last.TrueVerticalDepth, last.TrueVerticalDepthSubSea
+	ratio := (md - prev.MeasuredDepth) / (curr.MeasuredDepth - prev.MeasuredDepth)
+	tvd = prev.TrueVerticalDepth + ratio*(curr.TrueVerticalDepth-prev.TrueVerticalDepth)
+	tvdss = prev.TrueVerticalDepthSubSea + ratio*(curr.TrueVerticalDepthSubSea-prev.TrueVerticalDepthSubSea)
+	return tvd, tvdss, nil
 }
 
-// конвертация в Паскали и обратно
-func toPa(p float64, unit string) float64 {
-	switch unit {
-	case "kgf/cm2":
-		return p * 98066.5
-	case "bar":
-		return p * 1e5
-	case "atm":
-		return p * 101325
+// edgeSlope возвращает dTVD/dMD касательной в крайнем замере: по зенитному углу, если он известен,
+// иначе по хорде к соседнему замеру; для единственного замера без углов ствол считается вертикальным.
+func edgeSlope(edge models.TableFour, inner *models.TableFour) float64 {
+	switch {
+	case edge.Inclination != nil:
+		return math.Cos(*edge.Inclination * math.Pi / 180)
+	case inner != nil:
+		return (edge.TrueVerticalDepth - inner.TrueVerticalDepth) / (edge.MeasuredDepth - inner.MeasuredDepth)
 	default:
-		return p
+		return 1
 	}
 }
-func fromPa(pa float64, unit string) float64 {
-	switch unit {
-	case "kgf/cm2":
-		return pa / 98066.5
-	case "bar":
-		return pa / 1e5
-	case "atm":
-		return pa / 101325
-	default:
-		return pa
-	}
+
+// toPa переводит давление из единицы хранения в Па.
+func toPa(p float64) float64 {
+	pa, _ := models.Convert(p, models.StorageUnits.Pressure, models.UnitPa)
+	return pa
+}
+
+// fromPa переводит давление из Па в единицу хранения.
+func fromPa(pa float64) float64 {
+	p, _ := models.Convert(pa, models.UnitPa, models.StorageUnits.Pressure)
+	return p
 }
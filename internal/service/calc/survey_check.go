@@ -0,0 +1,74 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const surveyTolerance = 0.01 // м; расхождения меньше этого считаются погрешностью округления
+
+// prepareSurvey возвращает копию инклинометрии, упорядоченную по MD и без повторов MD, и найденные проблемы.
+//
+// Неупорядоченные замеры сортируются с предупреждением. Из замеров на одной MD остаётся первый;
+// если отметки у них различаются, это ошибка. Уменьшение TVD с глубиной возможно только в стволе
+// с зенитным углом больше 90°, поэтому это предупреждение; TVD больше MD — ошибка.
+func prepareSurvey(survey []models.TableFour) ([]models.TableFour, []models.SurveyIssue) {
+	var issues []models.SurveyIssue
+	if len(survey) == 0 {
+		return nil, append(issues, models.SurveyIssue{
+			Kind: models.SurveyExtrapolated, Error: true, Message: "нет замеров инклинометрии (блок 4)",
+		})
+	}
+
+	// номера замеров в исходном порядке, чтобы сообщения ссылались на строки блока 4
+	order := make([]int, len(survey))
+	for i := range order {
+		order[i] = i
+		if i > 0 && survey[i].MeasuredDepth < survey[i-1].MeasuredDepth && len(issues) == 0 {
+			issues = append(issues, models.SurveyIssue{
+				Kind: models.SurveyUnsorted, Station: i + 1, MD: survey[i].MeasuredDepth,
+				Message: fmt.Sprintf("MD меньше, чем у предыдущего замера (%g м): для расчёта замеры отсортированы по MD", survey[i-1].MeasuredDepth),
+			})
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return survey[order[a]].MeasuredDepth < survey[order[b]].MeasuredDepth })
+
+	stations := make([]models.TableFour, 0, len(survey))
+	var prevIdx int
+	for k, idx := range order {
+		st := survey[idx]
+		if k > 0 {
+			prev := stations[len(stations)-1]
+			if st.MeasuredDepth == prev.MeasuredDepth {
+				issue := models.SurveyIssue{Kind: models.SurveyDuplicateMD, Station: idx + 1, MD: st.MeasuredDepth}
+				if math.Abs(st.TrueVerticalDepth-prev.TrueVerticalDepth) > surveyTolerance ||
+					math.Abs(st.TrueVerticalDepthSubSea-prev.TrueVerticalDepthSubSea) > surveyTolerance {
+					issue.Error = true
+					issue.Message = fmt.Sprintf("TVD %g м не совпадает с TVD %g м замера %d на той же MD", st.TrueVerticalDepth, prev.TrueVerticalDepth, prevIdx+1)
+				} else {
+					issue.Message = fmt.Sprintf("повторяет замер %d и пропущен", prevIdx+1)
+				}
+				issues = append(issues, issue)
+				continue
+			}
+			if st.TrueVerticalDepth < prev.TrueVerticalDepth-surveyTolerance {
+				issues = append(issues, models.SurveyIssue{
+					Kind: models.SurveyTVDDecreasing, Station: idx + 1, MD: st.MeasuredDepth,
+					Message: fmt.Sprintf("TVD %g м меньше, чем %g м у замера %d выше по стволу", st.TrueVerticalDepth, prev.TrueVerticalDepth, prevIdx+1),
+				})
+			}
+		}
+		if st.TrueVerticalDepth > st.MeasuredDepth+surveyTolerance {
+			issues = append(issues, models.SurveyIssue{
+				Kind: models.SurveyTVDExceedsMD, Error: true, Station: idx + 1, MD: st.MeasuredDepth,
+				Message: fmt.Sprintf("TVD %g м больше глубины по стволу", st.TrueVerticalDepth),
+			})
+		}
+		stations = append(stations, st)
+		prevIdx = idx
+	}
+	return stations, issues
+}
@@ -0,0 +1,111 @@
+package calc
+
+import (
+	"math"
+
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const (
+	doglegInterval = 10.0 // интенсивность искривления — градусы на 10 м ствола
+	smallDogleg    = 1e-9 // ниже этого угла (рад) участок считается прямым, коэффициент RF = 1
+)
+
+// Trajectory рассчитывает траекторию методом минимальной кривизны по замерам MD, зенитного угла и азимута.
+//
+// Отсчёт ведётся от устья: если первый замер глубже нуля, добавляется точка MD = 0 с нулевыми углами.
+// Между соседними точками ствол — дуга окружности с углом β между направлениями, приращения
+// ΔN, ΔE, ΔTVD = ΔMD/2·(вектор₁ + вектор₂)·RF, где RF = 2/β·tg(β/2).
+// TVDSS = TVD − elevation, где elevation — альтитуда стола ротора (КБ) над уровнем моря.
+func Trajectory(stations []models.SurveyStation, elevation float64) ([]models.TableFour, error) {
+	if len(stations) == 0 {
+		return nil, errors.New("нет замеров инклинометрии")
+	}
+	if stations[0].MeasuredDepth > 0 {
+		stations = append([]models.SurveyStation{{}}, stations...)
+	}
+
+	out := make([]models.TableFour, 0, len(stations))
+	var n, e, tvd float64
+	for i, st := range stations {
+		dls := 0.0
+		if i > 0 {
+			prev := stations[i-1]
+			dMD := st.MeasuredDepth - prev.MeasuredDepth
+			if dMD <= 0 {
+				return nil, errors.Newf("глубина по стволу должна возрастать: %g после %g", st.MeasuredDepth, prev.MeasuredDepth)
+			}
+			dn, de, dv, beta := minimumCurvature(prev, st, dMD)
+			n, e, tvd = n+dn, e+de, tvd+dv
+			dls = beta * 180 / math.Pi * doglegInterval / dMD
+		}
+		out = append(out, models.TableFour{
+			MeasuredDepth:           st.MeasuredDepth,
+			TrueVerticalDepth:       tvd,
+			TrueVerticalDepthSubSea: tvd - elevation,
+			Inclination:             lo.ToPtr(st.Inclination),
+			Azimuth:                 lo.ToPtr(st.Azimuth),
+			Northing:                lo.ToPtr(n),
+			Easting:                 lo.ToPtr(e),
+			DoglegSeverity:          lo.ToPtr(dls),
+		})
+	}
+	return out, nil
+}
+
+// minimumCurvature возвращает приращения N, E, TVD между двумя точками и угол искривления β в радианах.
+func minimumCurvature(a, b models.SurveyStation, dMD float64) (dn, de, dv, beta float64) {
+	ta, tb := tangent(a.Inclination, a.Azimuth), tangent(b.Inclination, b.Azimuth)
+	beta = angleBetween(ta, tb)
+	rf := 1.0
+	if beta > smallDogleg {
+		rf = 2 / beta * math.Tan(beta/2)
+	}
+	k := dMD / 2 * rf
+	return k * (ta[0] + tb[0]), k * (ta[1] + tb[1]), k * (ta[2] + tb[2]), beta
+}
+
+// tangent — единичный вектор направления ствола (N, E, вниз) по зенитному углу и азимуту в градусах.
+func tangent(inc, az float64) [3]float64 {
+	i, a := inc*math.Pi/180, az*math.Pi/180
+	return [3]float64{math.Sin(i) * math.Cos(a), math.Sin(i) * math.Sin(a), math.Cos(i)}
+}
+
+// angleBetween возвращает угол между единичными векторами; через atan2 он точен и для малых углов.
+func angleBetween(a, b [3]float64) float64 {
+	cross := [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	return math.Atan2(math.Sqrt(cross[0]*cross[0]+cross[1]*cross[1]+cross[2]*cross[2]), a[0]*b[0]+a[1]*b[1]+a[2]*b[2])
+}
+
+// arcTVD возвращает TVD на глубине md между точками prev и curr, у которых есть углы:
+// направление в промежуточной точке лежит на той же дуге (сферическая интерполяция векторов),
+// и приращение от prev считается методом минимальной кривизны.
+func arcTVD(prev, curr models.TableFour, md float64) float64 {
+	ta, tb := tangent(*prev.Inclination, *prev.Azimuth), tangent(*curr.Inclination, *curr.Azimuth)
+	beta := angleBetween(ta, tb)
+	f := (md - prev.MeasuredDepth) / (curr.MeasuredDepth - prev.MeasuredDepth)
+
+	t := ta
+	if beta > smallDogleg {
+		wa, wb := math.Sin((1-f)*beta)/math.Sin(beta), math.Sin(f*beta)/math.Sin(beta)
+		for i := range t {
+			t[i] = wa*ta[i] + wb*tb[i]
+		}
+	} else {
+		for i := range t {
+			t[i] = ta[i] + f*(tb[i]-ta[i])
+		}
+	}
+	inc := math.Acos(max(-1, min(1, t[2]))) * 180 / math.Pi
+	az := math.Atan2(t[1], t[0]) * 180 / math.Pi
+
+	_, _, dv, _ := minimumCurvature(
+		models.SurveyStation{Inclination: *prev.Inclination, Azimuth: *prev.Azimuth},
+		models.SurveyStation{Inclination: inc, Azimuth: az},
+		md-prev.MeasuredDepth,
+	)
+	return prev.TrueVerticalDepth + dv
+}
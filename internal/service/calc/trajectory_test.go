@@ -0,0 +1,134 @@
+package calc
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+func TestTrajectory(t *testing.T) {
+	// набор угла на восток с 0 до 90° по дуге радиусом 100 м: длина дуги 50π,
+	// TVD растёт на R·sin(θ), смещение на восток — на R·(1 − cos θ)
+	const (
+		r     = 100.0
+		build = r * math.Pi / 2
+		kop   = 1000.0 // глубина начала набора
+		dls   = 90 / build * doglegInterval
+	)
+	type point struct{ md, tvd, n, e, dls float64 }
+
+	tests := []struct {
+		name      string
+		stations  []models.SurveyStation
+		elevation float64
+		want      []point
+	}{
+		{
+			name:      "вертикальная скважина",
+			stations:  []models.SurveyStation{{MeasuredDepth: 0}, {MeasuredDepth: 1000}, {MeasuredDepth: 2500}},
+			elevation: 120,
+			want:      []point{{0, 0, 0, 0, 0}, {1000, 1000, 0, 0, 0}, {2500, 2500, 0, 0, 0}},
+		},
+		{
+			name:     "первый замер ниже устья",
+			stations: []models.SurveyStation{{MeasuredDepth: 100}, {MeasuredDepth: 300}},
+			want:     []point{{0, 0, 0, 0, 0}, {100, 100, 0, 0, 0}, {300, 300, 0, 0, 0}},
+		},
+		{
+			name: "наклонная прямая",
+			stations: []models.SurveyStation{
+				{MeasuredDepth: 0, Inclination: 30, Azimuth: 45},
+				{MeasuredDepth: 200, Inclination: 30, Azimuth: 45},
+			},
+			want: []point{
+				{0, 0, 0, 0, 0},
+				{200, 200 * math.Cos(math.Pi/6), 100 * math.Cos(math.Pi/4), 100 * math.Sin(math.Pi/4), 0},
+			},
+		},
+		{
+			name: "набор угла и горизонтальный участок",
+			stations: []models.SurveyStation{
+				{MeasuredDepth: 0, Azimuth: 90},
+				{MeasuredDepth: kop, Azimuth: 90},
+				{MeasuredDepth: kop + build/2, Inclination: 45, Azimuth: 90},
+				{MeasuredDepth: kop + build, Inclination: 90, Azimuth: 90},
+				{MeasuredDepth: kop + build + 500, Inclination: 90, Azimuth: 90},
+			},
+			elevation: 85,
+			want: []point{
+				{0, 0, 0, 0, 0},
+				{kop, kop, 0, 0, 0},
+				{kop + build/2, kop + r*math.Sin(math.Pi/4), 0, r * (1 - math.Cos(math.Pi/4)), dls},
+				{kop + build, kop + r, 0, r, dls},
+				{kop + build + 500, kop + r, 0, r + 500, 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Trajectory(tt.stations, tt.elevation)
+			if err != nil {
+				t.Fatalf("Trajectory: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("точек %d; ожидалось %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				for _, c := range []struct {
+					field     string
+					got, want float64
+				}{
+					{"MD", g.MeasuredDepth, w.md},
+					{"TVD", g.TrueVerticalDepth, w.tvd},
+					{"TVDSS", g.TrueVerticalDepthSubSea, w.tvd - tt.elevation},
+					{"N", *g.Northing, w.n},
+					{"E", *g.Easting, w.e},
+					{"DLS", *g.DoglegSeverity, w.dls},
+				} {
+					if math.Abs(c.got-c.want) > 1e-6 {
+						t.Errorf("точка %d: %s = %.6f; ожидалось %.6f", i, c.field, c.got, c.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTrajectoryErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stations []models.SurveyStation
+	}{
+		{name: "нет замеров"},
+		{name: "глубина повторяется", stations: []models.SurveyStation{{MeasuredDepth: 0}, {MeasuredDepth: 100}, {MeasuredDepth: 100}}},
+		{name: "глубина убывает", stations: []models.SurveyStation{{MeasuredDepth: 0}, {MeasuredDepth: 200}, {MeasuredDepth: 150}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Trajectory(tt.stations, 0); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
+
+func TestArcTVD(t *testing.T) {
+	// на дуге набора угла TVD промежуточной точки совпадает с точной окружностью, а не с хордой
+	const r = 100.0
+	build := r * math.Pi / 2
+	points, err := Trajectory([]models.SurveyStation{
+		{MeasuredDepth: 0},
+		{MeasuredDepth: build, Inclination: 90},
+	}, 0)
+	if err != nil {
+		t.Fatalf("Trajectory: %v", err)
+	}
+	for _, f := range []float64{0, 0.25, 0.5, 1} {
+		got := arcTVD(points[0], points[1], f*build)
+		if want := r * math.Sin(f*math.Pi/2); math.Abs(got-want) > 1e-6 {
+			t.Errorf("TVD на доле дуги %.2f = %.6f; ожидалось %.6f", f, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package chart
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// generateEchartsBuildUpData возвращает точки Δp и производной в координатах [Δt, значение].
+// На логарифмических осях нельзя показать нулевые и отрицательные значения, такие точки пропускаются.
+func generateEchartsBuildUpData(points []models.BuildUpPoint, units models.UnitSystem) ([]opts.ScatterData, []opts.ScatterData) {
+	k := units.Scale(models.QuantityPressure) // Δp и производная — разности давлений
+	deltaP := make([]opts.ScatterData, 0, len(points))
+	derivative := make([]opts.ScatterData, 0, len(points))
+	for _, p := range points {
+		if p.Elapsed <= 0 {
+			continue
+		}
+		name := p.Timestamp.Format(time.RFC3339)
+		if p.DeltaP > 0 {
+			deltaP = append(deltaP, opts.ScatterData{Value: []float64{p.Elapsed, p.DeltaP * k}, Name: name})
+		}
+		if p.Derivative > 0 { // NaN тоже не проходит
+			derivative = append(derivative, opts.ScatterData{Value: []float64{p.Elapsed, p.Derivative * k}, Name: name})
+		}
+	}
+	return deltaP, derivative
+}
+
+func (s *chartService) GenerateBuildUpChart(data models.BuildUp, units models.UnitSystem) (string, error) {
+	if len(data.Points) == 0 {
+		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateBuildUpChart")
+	}
+	deltaP, derivative := generateEchartsBuildUpData(data.Points, units)
+	if len(deltaP) == 0 {
+		return "", errors.Wrap(errors.New("Давление в периоде не растёт: нет точек для логарифмического графика"), "GenerateBuildUpChart")
+	}
+
+	scatter := charts.NewScatter()
+
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title: "Диагностический график КВД: " + data.Period.Label,
+			Subtitle: fmt.Sprintf("%s, L = %g, остановка %s",
+				data.Options.Time.Title(), data.Options.Smoothing, data.ShutInTime.Format("02.01.06 15:04")),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "item",
+			TriggerOn: "mousemove|click",
+		}),
+		charts.WithXAxisOpts(opts.XAxis{
+			Name: "Δt, ч",
+			Type: "log",
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Name: "Δp, dΔp (" + units.Symbol(models.QuantityPressure) + ")",
+			Type: "log",
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithDataZoomOpts(opts.DataZoom{
+			Type:       "inside",
+			Start:      0,
+			End:        100,
+			XAxisIndex: []int{0},
+		}),
+		charts.WithToolboxOpts(opts.Toolbox{
+			Show: opts.Bool(true),
+			Feature: &opts.ToolBoxFeature{
+				SaveAsImage: &opts.ToolBoxFeatureSaveAsImage{
+					Show:  opts.Bool(true),
+					Type:  "png",
+					Name:  "buildup_chart",
+					Title: "Сохранить PNG",
+				},
+				DataZoom: &opts.ToolBoxFeatureDataZoom{
+					Show:  opts.Bool(true),
+					Title: map[string]string{"zoom": "Зум", "back": "Сброс"},
+				},
+				Restore: &opts.ToolBoxFeatureRestore{
+					Show:  opts.Bool(true),
+					Title: "Сброс",
+				},
+			},
+		}),
+	)
+
+	scatter.
+		AddSeries("Δp", deltaP, charts.WithItemStyleOpts(opts.ItemStyle{Color: "blue"})).
+		AddSeries("Производная Бурде", derivative, charts.WithItemStyleOpts(opts.ItemStyle{Color: "red"})).
+		SetSeriesOptions(
+			charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+		)
+	// Проверяем, существует ли папка
+	if _, err := os.Stat(HtmlChartsDirectory); os.IsNotExist(err) {
+		err = os.Mkdir(HtmlChartsDirectory, 0755)
+		if err != nil {
+			return "", errors.Wrap(err, "Ошибка при создании папки:")
+		}
+	}
+	f, err := os.Create(HTMLFileNameBuildUp)
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл %s: %w", HTMLFileNameBuildUp, err)
+	}
+	defer f.Close()
+
+	err = scatter.Render(f)
+	if err != nil {
+		return "", fmt.Errorf("не удалось отрендерить график в файл: %w", err)
+	}
+
+	return HTMLFileNameBuildUp, nil
+}
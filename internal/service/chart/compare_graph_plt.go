@@ -0,0 +1,126 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// generateEchartsComparisonData возвращает точки [время, значение] каждого исследования в единицах units.
+// Время — часы от первого замера ряда или, для календарной оси, миллисекунды Unix.
+func generateEchartsComparisonData(c models.Comparison, units models.UnitSystem) [][]opts.LineData {
+	q, convert := c.Channel.Quantity()
+	out := make([][]opts.LineData, len(c.Series))
+	for j, sr := range c.Series {
+		out[j] = make([]opts.LineData, 0, len(sr.Times))
+		for i, v := range sr.Values {
+			// пропуски не рисуются: у исследований свои метки времени, разрывать линию не нужно
+			if math.IsNaN(v) {
+				continue
+			}
+			if convert {
+				v = units.FromStorage(q, v)
+			}
+			var x any = sr.Times[i].UnixMilli()
+			if c.Relative {
+				x = sr.Elapsed(i)
+			}
+			out[j] = append(out[j], opts.LineData{Value: []any{x, v}, Name: sr.Times[i].Format(time.RFC3339)})
+		}
+	}
+	return out
+}
+
+func (s *chartService) GenerateComparisonChart(c models.Comparison, units models.UnitSystem) (string, error) {
+	if c.Len() == 0 {
+		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateComparisonChart")
+	}
+
+	xAxis := opts.XAxis{Name: "Время", Type: "time"}
+	if c.Relative {
+		xAxis = opts.XAxis{Name: "Время от начала, ч", Type: "value", Min: 0}
+	}
+
+	line := charts.NewLine()
+
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Сравнение исследований: " + c.Channel.Title(),
+			Subtitle: fmt.Sprintf("%d исследований", len(c.Series)),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "axis",
+			TriggerOn: "mousemove|click",
+		}),
+		charts.WithXAxisOpts(xAxis),
+		charts.WithYAxisOpts(opts.YAxis{
+			Name:  c.Channel.Label(units),
+			Type:  "value",
+			Scale: opts.Bool(true),
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), Top: "bottom"}),
+		charts.WithDataZoomOpts(opts.DataZoom{
+			Type:       "inside",
+			Start:      0,
+			End:        100,
+			XAxisIndex: []int{0},
+		}),
+		charts.WithToolboxOpts(opts.Toolbox{
+			Show: opts.Bool(true),
+			Feature: &opts.ToolBoxFeature{
+				SaveAsImage: &opts.ToolBoxFeatureSaveAsImage{
+					Show:  opts.Bool(true),
+					Type:  "png",
+					Name:  "compare_chart",
+					Title: "Сохранить PNG",
+				},
+				DataZoom: &opts.ToolBoxFeatureDataZoom{
+					Show:  opts.Bool(true),
+					Title: map[string]string{"zoom": "Зум", "back": "Сброс"},
+				},
+				Restore: &opts.ToolBoxFeatureRestore{
+					Show:  opts.Bool(true),
+					Title: "Сброс",
+				},
+			},
+		}),
+	)
+
+	data := generateEchartsComparisonData(c, units)
+	for j, sr := range c.Series {
+		line.AddSeries(sr.Research, data[j],
+			charts.WithLineChartOpts(opts.LineChart{ShowSymbol: opts.Bool(false)}),
+		)
+	}
+	line.SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+	)
+
+	// Проверяем, существует ли папка
+	if _, err := os.Stat(HtmlChartsDirectory); os.IsNotExist(err) {
+		err = os.Mkdir(HtmlChartsDirectory, 0755)
+		if err != nil {
+			return "", errors.Wrap(err, "Ошибка при создании папки:")
+		}
+	}
+	f, err := os.Create(HTMLFileNameCompare)
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл %s: %w", HTMLFileNameCompare, err)
+	}
+	defer f.Close()
+
+	err = line.Render(f)
+	if err != nil {
+		return "", fmt.Errorf("не удалось отрендерить график в файл: %w", err)
+	}
+
+	return HTMLFileNameCompare, nil
+}
@@ -6,26 +6,32 @@ import (
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"math"
 	"os"
 	"time"
 )
 
-func generateEchartsTableOneData(data []models.TableOne) ([][]opts.LineData, []string) {
+func generateEchartsTableOneData(data []models.TableOne, units models.UnitSystem) ([][]opts.LineData, []string) {
 	yLabels := make([][]opts.LineData, 3)
 
 	xLabels := make([]string, 0, len(data))
 
 	for _, point := range data {
-		yLabels[0] = append(yLabels[0], opts.LineData{Value: point.PressureDepth, Name: point.Timestamp.Format(time.RFC3339)})
-		yLabels[1] = append(yLabels[1], opts.LineData{Value: point.PressureAtVDP, Name: point.Timestamp.Format(time.RFC3339)})
-		yLabels[2] = append(yLabels[2], opts.LineData{Value: point.TemperatureDepth, Name: point.Timestamp.Format(time.RFC3339)})
+		yLabels[0] = append(yLabels[0], opts.LineData{Value: units.FromStorage(models.QuantityPressure, point.PressureDepth), Name: point.Timestamp.Format(time.RFC3339)})
+		// NaN не сериализуется в JSON; "-" ECharts рисует как разрыв линии
+		var vdp any = units.FromStorage(models.QuantityPressure, point.PressureAtVDP)
+		if math.IsNaN(point.PressureAtVDP) {
+			vdp = "-"
+		}
+		yLabels[1] = append(yLabels[1], opts.LineData{Value: vdp, Name: point.Timestamp.Format(time.RFC3339)})
+		yLabels[2] = append(yLabels[2], opts.LineData{Value: units.FromStorage(models.QuantityTemperature, point.TemperatureDepth), Name: point.Timestamp.Format(time.RFC3339)})
 
 		xLabels = append(xLabels, point.Timestamp.Format("02.01.02 15:04")) // Только время для краткости оси X
 	}
 
 	return yLabels, xLabels
 }
-func (s *chartService) GenerateTableOneChart(data []models.TableOne) (string, error) {
+func (s *chartService) GenerateTableOneChart(data []models.TableOne, units models.UnitSystem) (string, error) {
 	if len(data) == 0 {
 		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateTableOneChart")
 	}
@@ -46,7 +52,7 @@ func (s *chartService) GenerateTableOneChart(data []models.TableOne) (string, er
 			Type: "category", // Ось категорий (наши метки времени)
 		}),
 		charts.WithYAxisOpts(opts.YAxis{ // Основная ось Y (Давление)
-			Name: "Давление (кгс/см2)",
+			Name: "Давление (" + units.Symbol(models.QuantityPressure) + "), температура (" + units.Symbol(models.QuantityTemperature) + ")",
 			Type: "value",
 		}),
 		// Важно: go-echarts напрямую не поддерживает вторую Y-ось так просто, как go-chart/v2.
@@ -81,7 +87,7 @@ func (s *chartService) GenerateTableOneChart(data []models.TableOne) (string, er
 		}),
 	)
 
-	tableOneData, xLabels := generateEchartsTableOneData(data)
+	tableOneData, xLabels := generateEchartsTableOneData(data, units)
 
 	line.SetXAxis(xLabels).
 		AddSeries("Рзаб на глубине", tableOneData[0], charts.WithLineStyleOpts(opts.LineStyle{Color: "blue"})).
@@ -0,0 +1,136 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// generateEchartsFrameData возвращает подписи узлов и точки каждой величины в единицах units;
+// пустой узел — разрыв линии.
+func generateEchartsFrameData(frame models.Frame, units models.UnitSystem) ([]string, [][]opts.LineData) {
+	xLabels := make([]string, 0, frame.Len())
+	for _, t := range frame.Times {
+		xLabels = append(xLabels, t.Format("02.01.06 15:04:05"))
+	}
+	series := make([][]opts.LineData, len(frame.Channels))
+	for j, ch := range frame.Channels {
+		q, convert := ch.Quantity()
+		series[j] = make([]opts.LineData, 0, frame.Len())
+		for i, v := range frame.Values[j] {
+			if convert {
+				v = units.FromStorage(q, v)
+			}
+			// NaN не сериализуется в JSON; "-" ECharts рисует как разрыв линии
+			var value any = v
+			if math.IsNaN(v) {
+				value = "-"
+			}
+			series[j] = append(series[j], opts.LineData{Value: value, Name: frame.Times[i].Format(time.RFC3339)})
+		}
+	}
+	return xLabels, series
+}
+
+func (s *chartService) GenerateFrameChart(frame models.Frame, units models.UnitSystem) (string, error) {
+	if frame.Len() == 0 {
+		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateFrameChart")
+	}
+
+	line := charts.NewLine()
+
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Сводный график блоков 1–3",
+			Subtitle: fmt.Sprintf("%d узлов сетки", frame.Len()),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "axis",
+			TriggerOn: "mousemove|click",
+		}),
+		charts.WithXAxisOpts(opts.XAxis{
+			Name: "Время",
+			Type: "category",
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Name:  "Давление (" + units.Symbol(models.QuantityPressure) + ")",
+			Type:  "value",
+			Scale: opts.Bool(true),
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), Top: "bottom"}),
+		charts.WithDataZoomOpts(opts.DataZoom{
+			Type:       "inside",
+			Start:      0,
+			End:        100,
+			XAxisIndex: []int{0},
+		}),
+		charts.WithToolboxOpts(opts.Toolbox{
+			Show: opts.Bool(true),
+			Feature: &opts.ToolBoxFeature{
+				SaveAsImage: &opts.ToolBoxFeatureSaveAsImage{
+					Show:  opts.Bool(true),
+					Type:  "png",
+					Name:  "frame_chart",
+					Title: "Сохранить PNG",
+				},
+				DataZoom: &opts.ToolBoxFeatureDataZoom{
+					Show:  opts.Bool(true),
+					Title: map[string]string{"zoom": "Зум", "back": "Сброс"},
+				},
+				Restore: &opts.ToolBoxFeatureRestore{
+					Show:  opts.Bool(true),
+					Title: "Сброс",
+				},
+			},
+		}),
+	)
+	// давления — на левой оси, температура и дебиты — на правой
+	line.ExtendYAxis(opts.YAxis{
+		Name:  "Температура, дебиты",
+		Type:  "value",
+		Scale: opts.Bool(true),
+	})
+
+	xLabels, data := generateEchartsFrameData(frame, units)
+	line.SetXAxis(xLabels)
+	for j, ch := range frame.Channels {
+		axis := 1
+		if ch.IsPressure() {
+			axis = 0
+		}
+		line.AddSeries(ch.Label(units), data[j],
+			charts.WithLineChartOpts(opts.LineChart{YAxisIndex: axis, ShowSymbol: opts.Bool(false)}),
+		)
+	}
+	line.SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+	)
+
+	// Проверяем, существует ли папка
+	if _, err := os.Stat(HtmlChartsDirectory); os.IsNotExist(err) {
+		err = os.Mkdir(HtmlChartsDirectory, 0755)
+		if err != nil {
+			return "", errors.Wrap(err, "Ошибка при создании папки:")
+		}
+	}
+	f, err := os.Create(HTMLFileNameFrame)
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл %s: %w", HTMLFileNameFrame, err)
+	}
+	defer f.Close()
+
+	err = line.Render(f)
+	if err != nil {
+		return "", fmt.Errorf("не удалось отрендерить график в файл: %w", err)
+	}
+
+	return HTMLFileNameFrame, nil
+}
@@ -0,0 +1,115 @@
+package chart
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// generateEchartsIPRData возвращает замеры режимов и подобранную кривую в координатах [Q, Рзаб].
+func generateEchartsIPRData(data models.IPR, units models.UnitSystem) ([]opts.ScatterData, []opts.LineData) {
+	q := func(v float64) float64 { return units.FromStorage(models.QuantityLiquidRate, v) }
+	p := func(v float64) float64 { return units.FromStorage(models.QuantityPressure, v) }
+	steps := make([]opts.ScatterData, 0, len(data.Steps))
+	for _, s := range data.Steps {
+		steps = append(steps, opts.ScatterData{
+			Value: []float64{q(s.Rate), p(s.Pwf)},
+			Name:  fmt.Sprintf("%s — %s", s.Start.Format("02.01.06 15:04"), s.End.Format("02.01.06 15:04")),
+		})
+	}
+	curve := make([]opts.LineData, 0, len(data.Curve))
+	for _, pt := range data.Curve {
+		curve = append(curve, opts.LineData{Value: []float64{q(pt.Rate), p(pt.Pwf)}})
+	}
+	return steps, curve
+}
+
+func (s *chartService) GenerateIPRChart(data models.IPR, units models.UnitSystem) (string, error) {
+	if len(data.Steps) == 0 {
+		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateIPRChart")
+	}
+	steps, curve := generateEchartsIPRData(data, units)
+	pUnit, qUnit := units.Symbol(models.QuantityPressure), units.Symbol(models.QuantityLiquidRate)
+
+	scatter := charts.NewScatter()
+
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title: "Индикаторная кривая (IPR)",
+			Subtitle: fmt.Sprintf("Pпл = %.4g %s, J = %.4g %s/%s, Qmax = %.4g %s",
+				units.FromStorage(models.QuantityPressure, data.Options.ReservoirPressure), pUnit,
+				units.FromStorage(models.QuantityLiquidRate, data.J)/units.Scale(models.QuantityPressure), qUnit, pUnit,
+				units.FromStorage(models.QuantityLiquidRate, data.QMax), qUnit),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "item",
+			TriggerOn: "mousemove|click",
+		}),
+		charts.WithXAxisOpts(opts.XAxis{
+			Name: "Qж, " + qUnit,
+			Type: "value",
+			Min:  0,
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Name: "Рзаб на ВДП (" + pUnit + ")",
+			Type: "value",
+			Min:  0,
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithToolboxOpts(opts.Toolbox{
+			Show: opts.Bool(true),
+			Feature: &opts.ToolBoxFeature{
+				SaveAsImage: &opts.ToolBoxFeatureSaveAsImage{
+					Show:  opts.Bool(true),
+					Type:  "png",
+					Name:  "ipr_chart",
+					Title: "Сохранить PNG",
+				},
+				Restore: &opts.ToolBoxFeatureRestore{
+					Show:  opts.Bool(true),
+					Title: "Сброс",
+				},
+			},
+		}),
+	)
+
+	scatter.
+		AddSeries("Режимы", steps, charts.WithItemStyleOpts(opts.ItemStyle{Color: "blue"})).
+		SetSeriesOptions(
+			charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+		)
+
+	line := charts.NewLine()
+	line.AddSeries("Кривая Вогеля", curve,
+		charts.WithLineStyleOpts(opts.LineStyle{Color: "red"}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: "red"}),
+		charts.WithLineChartOpts(opts.LineChart{ShowSymbol: opts.Bool(false), Smooth: opts.Bool(true)}),
+	)
+	scatter.Overlap(line)
+
+	// Проверяем, существует ли папка
+	if _, err := os.Stat(HtmlChartsDirectory); os.IsNotExist(err) {
+		err = os.Mkdir(HtmlChartsDirectory, 0755)
+		if err != nil {
+			return "", errors.Wrap(err, "Ошибка при создании папки:")
+		}
+	}
+	f, err := os.Create(HTMLFileNameIPR)
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл %s: %w", HTMLFileNameIPR, err)
+	}
+	defer f.Close()
+
+	err = scatter.Render(f)
+	if err != nil {
+		return "", fmt.Errorf("не удалось отрендерить график в файл: %w", err)
+	}
+
+	return HTMLFileNameIPR, nil
+}
@@ -5,20 +5,28 @@ import (
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"math"
 	"os"
 	"slices"
 	"time"
 )
 
-func generateEchartsTableTwoData(data []models.TableTwo) ([][]opts.LineData, []string) {
+func generateEchartsTableTwoData(data []models.TableTwo, units models.UnitSystem) ([][]opts.LineData, []string) {
+	// NaN (провал, убранный очисткой) не сериализуется в JSON; "-" ECharts рисует как разрыв линии
+	p := func(v float64) any {
+		if math.IsNaN(v) {
+			return "-"
+		}
+		return units.FromStorage(models.QuantityPressure, v)
+	}
 	yLabels := make([][]opts.LineData, 3)
 
 	uniqueXLabels := make(map[string]struct{})
 
 	for _, point := range data {
-		yLabels[0] = append(yLabels[0], opts.LineData{Value: point.PressureAnnulus, Name: point.TimestampAnnulus.Format(time.RFC3339)})
-		yLabels[1] = append(yLabels[1], opts.LineData{Value: point.PressureTubing, Name: point.TimestampTubing.Format(time.RFC3339)})
-		yLabels[2] = append(yLabels[2], opts.LineData{Value: point.PressureLinear, Name: point.TimestampLinear.Format(time.RFC3339)})
+		yLabels[0] = append(yLabels[0], opts.LineData{Value: p(point.PressureAnnulus), Name: point.TimestampAnnulus.Format(time.RFC3339)})
+		yLabels[1] = append(yLabels[1], opts.LineData{Value: p(point.PressureTubing), Name: point.TimestampTubing.Format(time.RFC3339)})
+		yLabels[2] = append(yLabels[2], opts.LineData{Value: p(point.PressureLinear), Name: point.TimestampLinear.Format(time.RFC3339)})
 
 		uniqueXLabels[point.TimestampAnnulus.Format("02.01.02 15:04")] = struct{}{}
 		uniqueXLabels[point.TimestampTubing.Format("02.01.02 15:04")] = struct{}{}
@@ -33,7 +41,7 @@ func generateEchartsTableTwoData(data []models.TableTwo) ([][]opts.LineData, []s
 	return yLabels, uniqueSlice
 }
 
-func (s *chartService) GenerateTableTwoChart(data []models.TableTwo, units string) (string, error) {
+func (s *chartService) GenerateTableTwoChart(data []models.TableTwo, units models.UnitSystem) (string, error) {
 	if len(data) == 0 {
 		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateTableTwoChart")
 	}
@@ -54,7 +62,7 @@ func (s *chartService) GenerateTableTwoChart(data []models.TableTwo, units strin
 			Type: "category", // Ось категорий (наши метки времени)
 		}),
 		charts.WithYAxisOpts(opts.YAxis{ // Основная ось Y (Давление)
-			Name: "Давление " + units,
+			Name: "Давление (" + units.Symbol(models.QuantityPressure) + ")",
 			Type: "value",
 		}),
 		// Важно: go-echarts напрямую не поддерживает вторую Y-ось так просто, как go-chart/v2.
@@ -91,7 +99,7 @@ func (s *chartService) GenerateTableTwoChart(data []models.TableTwo, units strin
 		//charts.WithTheme(types.ThemeInfographic),
 	)
 
-	tableOneData, xLabels := generateEchartsTableTwoData(data)
+	tableOneData, xLabels := generateEchartsTableTwoData(data, units)
 
 	line.SetXAxis(xLabels).
 		AddSeries("Ртр", tableOneData[0], charts.WithLineStyleOpts(opts.LineStyle{Color: "yellow"})).
@@ -0,0 +1,140 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/cockroachdb/errors"
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// generateEchartsSemilogData делит точки на участок аппроксимации и остальные и строит прямую:
+// для Хорнера — до X = 1, где она даёт p*.
+func generateEchartsSemilogData(res models.SemilogResult, units models.UnitSystem) (other, segment []opts.ScatterData, line []opts.LineData) {
+	k := units.Scale(models.QuantityPressure)
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, p := range res.Points {
+		item := opts.ScatterData{Value: []float64{p.X, p.Pressure * k}, Name: fmt.Sprintf("Δt = %.3f ч", p.Elapsed)}
+		if p.InSegment {
+			segment = append(segment, item)
+		} else {
+			other = append(other, item)
+		}
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+	}
+
+	slope := res.Slope
+	if res.Method == models.MethodHorner {
+		slope, minX = -slope, 1
+	}
+	for _, x := range []float64{minX, maxX} {
+		line = append(line, opts.LineData{Value: []float64{x, (res.Intercept + slope*math.Log10(x)) * k}})
+	}
+	return other, segment, line
+}
+
+func (s *chartService) GenerateSemilogChart(res models.SemilogResult, units models.UnitSystem) (string, error) {
+	if len(res.Points) == 0 {
+		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateSemilogChart")
+	}
+	other, segment, fitted := generateEchartsSemilogData(res, units)
+
+	k, unit := units.Scale(models.QuantityPressure), units.Symbol(models.QuantityPressure)
+	xName := "Δt, ч"
+	subtitle := fmt.Sprintf("m = %.4g %s/цикл, kh = %.4g мД·м, k = %.4g мД, S = %.2f, R² = %.4f",
+		res.Slope*k, unit, res.PermeabilityThickness, res.Permeability, res.Skin, res.R2)
+	if res.Method == models.MethodHorner {
+		xName = "(tp+Δt)/Δt"
+		subtitle = fmt.Sprintf("p* = %.4g %s, %s", res.PStar*k, unit, subtitle)
+	}
+
+	scatter := charts.NewScatter()
+
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    fmt.Sprintf("%s: %s", res.Method.Title(), res.Period.Label),
+			Subtitle: subtitle,
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "item",
+			TriggerOn: "mousemove|click",
+		}),
+		charts.WithXAxisOpts(opts.XAxis{
+			Name: xName,
+			Type: "log",
+			// на графике Хорнера время идёт справа налево: поздние замеры ближе к X = 1
+			Inverse: opts.Bool(res.Method == models.MethodHorner),
+		}),
+		charts.WithYAxisOpts(opts.YAxis{
+			Name:  "Давление (" + unit + ")",
+			Type:  "value",
+			Scale: opts.Bool(true),
+		}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithDataZoomOpts(opts.DataZoom{
+			Type:       "inside",
+			Start:      0,
+			End:        100,
+			XAxisIndex: []int{0},
+		}),
+		charts.WithToolboxOpts(opts.Toolbox{
+			Show: opts.Bool(true),
+			Feature: &opts.ToolBoxFeature{
+				SaveAsImage: &opts.ToolBoxFeatureSaveAsImage{
+					Show:  opts.Bool(true),
+					Type:  "png",
+					Name:  "semilog_chart",
+					Title: "Сохранить PNG",
+				},
+				DataZoom: &opts.ToolBoxFeatureDataZoom{
+					Show:  opts.Bool(true),
+					Title: map[string]string{"zoom": "Зум", "back": "Сброс"},
+				},
+				Restore: &opts.ToolBoxFeatureRestore{
+					Show:  opts.Bool(true),
+					Title: "Сброс",
+				},
+			},
+		}),
+	)
+
+	scatter.
+		AddSeries("Замеры", other, charts.WithItemStyleOpts(opts.ItemStyle{Color: "grey"})).
+		AddSeries("Участок радиального притока", segment, charts.WithItemStyleOpts(opts.ItemStyle{Color: "blue"})).
+		SetSeriesOptions(
+			charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+		)
+
+	line := charts.NewLine()
+	line.AddSeries("Прямая", fitted,
+		charts.WithLineStyleOpts(opts.LineStyle{Color: "red"}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Color: "red"}),
+		charts.WithLineChartOpts(opts.LineChart{ShowSymbol: opts.Bool(false)}),
+	)
+	scatter.Overlap(line)
+
+	// Проверяем, существует ли папка
+	if _, err := os.Stat(HtmlChartsDirectory); os.IsNotExist(err) {
+		err = os.Mkdir(HtmlChartsDirectory, 0755)
+		if err != nil {
+			return "", errors.Wrap(err, "Ошибка при создании папки:")
+		}
+	}
+	f, err := os.Create(HTMLFileNameSemilog)
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл %s: %w", HTMLFileNameSemilog, err)
+	}
+	defer f.Close()
+
+	err = scatter.Render(f)
+	if err != nil {
+		return "", fmt.Errorf("не удалось отрендерить график в файл: %w", err)
+	}
+
+	return HTMLFileNameSemilog, nil
+}
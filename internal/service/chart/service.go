@@ -13,13 +13,29 @@ const (
 	HTMLFileNameOne     = HtmlChartsDirectory + "first_chart.html"
 	HTMLFileNameTwo     = HtmlChartsDirectory + "second_chart.html"
 	HTMLFileNameThree   = HtmlChartsDirectory + "third_chart.html"
+	HTMLFileNameBuildUp = HtmlChartsDirectory + "buildup_chart.html"
+	HTMLFileNameSemilog = HtmlChartsDirectory + "semilog_chart.html"
+	HTMLFileNameIPR     = HtmlChartsDirectory + "ipr_chart.html"
+	HTMLFileNameFrame   = HtmlChartsDirectory + "frame_chart.html"
+	HTMLFileNameCompare = HtmlChartsDirectory + "compare_chart.html"
 )
 
 type Service interface {
-	// GenerateTableOneChart генерирует HTML файл графика и возвращает путь к нему
-	GenerateTableOneChart(data []models.TableOne) (string, error)
-	GenerateTableTwoChart(data []models.TableTwo, units string) (string, error)
-	GenerateTableThreeChart(data []models.TableThree) (string, error)
+	// GenerateTableOneChart генерирует HTML файл графика и возвращает путь к нему.
+	// Данные всех графиков — в единицах хранения, на график они выводятся в единицах units.
+	GenerateTableOneChart(data []models.TableOne, units models.UnitSystem) (string, error)
+	GenerateTableTwoChart(data []models.TableTwo, units models.UnitSystem) (string, error)
+	GenerateTableThreeChart(data []models.TableThree, units models.UnitSystem) (string, error)
+	// GenerateBuildUpChart строит диагностический log-log график КВД: Δp и производная Бурде от Δt
+	GenerateBuildUpChart(data models.BuildUp, units models.UnitSystem) (string, error)
+	// GenerateSemilogChart строит полулогарифмический график Хорнера или MDH с аппроксимирующей прямой
+	GenerateSemilogChart(res models.SemilogResult, units models.UnitSystem) (string, error)
+	// GenerateIPRChart строит индикаторную кривую: замеры режимов и подобранную кривую Вогеля
+	GenerateIPRChart(data models.IPR, units models.UnitSystem) (string, error)
+	// GenerateFrameChart строит величины блоков 1–3, выведенные на общую временную сетку
+	GenerateFrameChart(frame models.Frame, units models.UnitSystem) (string, error)
+	// GenerateComparisonChart строит одну величину нескольких исследований на общей оси времени
+	GenerateComparisonChart(c models.Comparison, units models.UnitSystem) (string, error)
 }
 
 type chartService struct{}
@@ -28,6 +44,15 @@ func NewService() Service {
 	return &chartService{}
 }
 
+// optional переводит необязательное значение величины q в единицы units; nil остаётся nil.
+func optional(units models.UnitSystem, q models.Quantity, v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	out := units.FromStorage(q, *v)
+	return &out
+}
+
 func generateTempEchartsData(data []models.TableOne) []opts.LineData {
 	items := make([]opts.LineData, 0, len(data))
 	for _, point := range data {
@@ -11,18 +11,18 @@ import (
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
 )
 
-func generateEchartsTableThreeData(data []models.TableThree) ([][]opts.ScatterData, []string) {
+func generateEchartsTableThreeData(data []models.TableThree, units models.UnitSystem) ([][]opts.ScatterData, []string) {
 	yLabels := make([][]opts.ScatterData, 6)
 
 	xLabels := make([]string, 0, len(data))
 
 	for _, point := range data {
-		yLabels[0] = append(yLabels[0], opts.ScatterData{Value: point.LiquidFlowRate, Name: point.Timestamp.Format(time.RFC3339)})
-		yLabels[1] = append(yLabels[1], opts.ScatterData{Value: point.OilFlowRate, Name: point.Timestamp.Format(time.RFC3339)})
-		yLabels[2] = append(yLabels[2], opts.ScatterData{Value: point.WaterFlowRate, Name: point.Timestamp.Format(time.RFC3339)})
+		yLabels[0] = append(yLabels[0], opts.ScatterData{Value: units.FromStorage(models.QuantityLiquidRate, point.LiquidFlowRate), Name: point.Timestamp.Format(time.RFC3339)})
+		yLabels[1] = append(yLabels[1], opts.ScatterData{Value: optional(units, models.QuantityLiquidRate, point.OilFlowRate), Name: point.Timestamp.Format(time.RFC3339)})
+		yLabels[2] = append(yLabels[2], opts.ScatterData{Value: optional(units, models.QuantityLiquidRate, point.WaterFlowRate), Name: point.Timestamp.Format(time.RFC3339)})
 
 		yLabels[3] = append(yLabels[3], opts.ScatterData{Value: point.WaterCut, Name: point.Timestamp.Format(time.RFC3339)})
-		yLabels[4] = append(yLabels[4], opts.ScatterData{Value: point.GasFlowRate, Name: point.Timestamp.Format(time.RFC3339)})
+		yLabels[4] = append(yLabels[4], opts.ScatterData{Value: units.FromStorage(models.QuantityGasRate, point.GasFlowRate), Name: point.Timestamp.Format(time.RFC3339)})
 		yLabels[5] = append(yLabels[5], opts.ScatterData{Value: point.GasFactor, Name: point.Timestamp.Format(time.RFC3339)})
 
 		xLabels = append(xLabels, point.Timestamp.Format("02.01.02 15:04")) // Только время для краткости оси X
@@ -30,7 +30,7 @@ func generateEchartsTableThreeData(data []models.TableThree) ([][]opts.ScatterDa
 
 	return yLabels, xLabels
 }
-func (s *chartService) GenerateTableThreeChart(data []models.TableThree) (string, error) {
+func (s *chartService) GenerateTableThreeChart(data []models.TableThree, units models.UnitSystem) (string, error) {
 	if len(data) == 0 {
 		return "", errors.Wrap(errors.New("Нет данных, для построения графика"), "GenerateTableOneChart")
 	}
@@ -51,7 +51,7 @@ func (s *chartService) GenerateTableThreeChart(data []models.TableThree) (string
 			Type: "category", // Ось категорий (наши метки времени)
 		}),
 		charts.WithYAxisOpts(opts.YAxis{ // Основная ось Y (Давление)
-			Name: "Дебит (м²/сут)",
+			Name: "Дебит (" + units.Symbol(models.QuantityLiquidRate) + ")",
 			Type: "value",
 		}),
 
@@ -85,15 +85,15 @@ func (s *chartService) GenerateTableThreeChart(data []models.TableThree) (string
 		//charts.WithTheme(types.ThemeInfographic),
 	)
 
-	tableOneData, xLabels := generateEchartsTableThreeData(data)
+	tableOneData, xLabels := generateEchartsTableThreeData(data, units)
 
 	line.SetXAxis(xLabels).
 		AddSeries("Дебит жидкости", tableOneData[0], charts.WithLineStyleOpts(opts.LineStyle{Color: "blue"})).
 		AddSeries("Дебит нефти", tableOneData[1], charts.WithLineStyleOpts(opts.LineStyle{Color: "green"})).
 		AddSeries("Дебит воды", tableOneData[2], charts.WithLineStyleOpts(opts.LineStyle{Color: "red"})).
-		AddSeries("Обводненность", tableOneData[3], charts.WithLineStyleOpts(opts.LineStyle{Color: "cyan"})).
-		AddSeries("Дебит газа", tableOneData[4], charts.WithLineStyleOpts(opts.LineStyle{Color: "grey"})).
-		AddSeries("Газовый фактор", tableOneData[5], charts.WithLineStyleOpts(opts.LineStyle{Color: "brown"})).
+		AddSeries("Обводненность, %", tableOneData[3], charts.WithLineStyleOpts(opts.LineStyle{Color: "cyan"})).
+		AddSeries("Дебит газа, "+units.Symbol(models.QuantityGasRate), tableOneData[4], charts.WithLineStyleOpts(opts.LineStyle{Color: "grey"})).
+		AddSeries("Газовый фактор, м³/м³", tableOneData[5], charts.WithLineStyleOpts(opts.LineStyle{Color: "brown"})).
 		SetSeriesOptions(
 			charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
 		)
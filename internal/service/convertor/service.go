@@ -16,24 +16,38 @@ func NewService() *Service {
 
 // ParseFlexibleTime пытается разобрать время как Excel‑serial или ISO/RFC строки.
 func (s *Service) ParseFlexibleTime(raw string) (time.Time, error) {
+	return s.ParseFlexibleTimeIn(raw, time.UTC)
+}
+
+// ParseFlexibleTimeIn разбирает время как ParseFlexibleTime, но метку без явного пояса
+// читает как местное время loc. Результат всегда в UTC.
+func (s *Service) ParseFlexibleTimeIn(raw string, loc *time.Location) (time.Time, error) {
 	if num, err := strconv.ParseFloat(raw, 64); err == nil {
-		return excelDateToTime(num, false)
+		t, err := excelDateToTime(num, false)
+		if err != nil {
+			return t, err
+		}
+		// Excel хранит местное время без пояса: переносим показания часов в loc
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc).UTC(), nil
 	}
 
 	layouts := []string{
 		time.RFC3339,          // 2024-11-09T17:21:21Z
 		"2006-01-02T15:04:05", // 2024-11-09T17:21:21
 		"2006-01-02 15:04:05", // 2024-11-09 17:21:21
+		"2006-01-02 15:04",    // 2024-11-09 17:21
 		"2006-01-02",          // 2024-11-10
 		"02/01/2006 15:04:05", // 09/11/2024 17:21:21
+		"02/01/2006 15:04",    // 09/11/2024 17:21
 		"02/01/2006",          // 09/11/2024
 		"02.01.2006 15:04:05", // 09.11.2024 17:21:21
+		"02.01.2006 15:04",    // 09.11.2024 17:21
 		"02.01.2006",          // 09.11.2024
 	}
 
 	for _, layout := range layouts {
-		if t, err := time.Parse(layout, raw); err == nil {
-			return t, nil
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t.UTC(), nil
 		}
 	}
 	return time.Time{}, errors.Errorf("unsupported time format %q", raw)
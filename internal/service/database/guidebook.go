@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// ListGuidebook возвращает значения справочника g с числом отчётов, где они указаны.
+// Если query не пустой, остаются только значения, содержащие его без учёта регистра.
+func (d *Service) ListGuidebook(ctx context.Context, g models.Guidebook, query string) ([]models.GuidebookEntry, error) {
+	if !g.Valid() {
+		return nil, errors.Errorf("неизвестный справочник %q", g)
+	}
+	items, err := d.repo.ListGuidebook(ctx, g)
+	if err != nil {
+		d.log.Errorw("ListGuidebook failed", "guidebook", g, "error", err)
+		return nil, err
+	}
+	if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+		found := items[:0]
+		for _, it := range items {
+			if strings.Contains(strings.ToLower(it.Name), query) {
+				found = append(found, it)
+			}
+		}
+		items = found
+	}
+	d.log.Debugw("ListGuidebook succeeded", "guidebook", g, "query", query, "count", len(items))
+
+	return items, nil
+}
+
+// AddGuidebookEntry добавляет в справочник g значение name
+func (d *Service) AddGuidebookEntry(ctx context.Context, g models.Guidebook, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("значение не может быть пустым")
+	}
+	if _, ok, err := d.guidebookEntry(ctx, g, name); err != nil {
+		return err
+	} else if ok {
+		return errors.Errorf("значение «%s» уже есть в справочнике «%s»", name, g.Title())
+	}
+
+	switch g {
+	case models.GuidebookOilField:
+		return d.SaveOilFields(ctx, []models.OilField{{Name: name}})
+	case models.GuidebookProductiveHorizon:
+		return d.SaveProductiveHorizons(ctx, []models.ProductiveHorizon{{Name: name}})
+	case models.GuidebookInstrumentType:
+		return d.SaveInstrumentTypes(ctx, []models.InstrumentType{{Name: name}})
+	default:
+		return d.SaveResearchTypes(ctx, []models.ResearchType{{Name: name}})
+	}
+}
+
+// RenameGuidebookEntry переименовывает значение from справочника g в to; отчёты, где оно указано,
+// получают новое имя. Если to уже есть в справочнике, значения нужно объединить.
+func (d *Service) RenameGuidebookEntry(ctx context.Context, g models.Guidebook, from, to string) error {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return errors.New("значение не может быть пустым")
+	}
+	if to == from {
+		return nil
+	}
+	if _, ok, err := d.guidebookEntry(ctx, g, to); err != nil {
+		return err
+	} else if ok {
+		return errors.Errorf("значение «%s» уже есть в справочнике «%s»: объедините значения", to, g.Title())
+	}
+
+	if err := d.repo.RenameGuidebookEntry(ctx, g, from, to); err != nil {
+		d.log.Errorw("RenameGuidebookEntry failed", "guidebook", g, "from", from, "to", to, "error", err)
+		return err
+	}
+	d.log.Infow("Guidebook entry renamed", "guidebook", g, "from", from, "to", to)
+
+	return nil
+}
+
+// MergeGuidebookEntries объединяет значение from справочника g со значением into: отчёты с from
+// получают into, а from удаляется из справочника.
+func (d *Service) MergeGuidebookEntries(ctx context.Context, g models.Guidebook, from, into string) error {
+	if !g.Valid() {
+		return errors.Errorf("неизвестный справочник %q", g)
+	}
+	if from == into {
+		return errors.New("значение нельзя объединить с самим собой")
+	}
+
+	if err := d.repo.MergeGuidebookEntries(ctx, g, from, into); err != nil {
+		d.log.Errorw("MergeGuidebookEntries failed", "guidebook", g, "from", from, "into", into, "error", err)
+		return err
+	}
+	d.log.Infow("Guidebook entries merged", "guidebook", g, "from", from, "into", into)
+
+	return nil
+}
+
+// DeleteGuidebookEntry удаляет значение name справочника g. Значение, указанное в отчётах, не удаляется:
+// его можно переименовать или объединить с другим.
+func (d *Service) DeleteGuidebookEntry(ctx context.Context, g models.Guidebook, name string) error {
+	entry, ok, err := d.guidebookEntry(ctx, g, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("значения «%s» нет в справочнике «%s»", name, g.Title())
+	}
+	if entry.Reports > 0 {
+		return errors.Errorf("значение «%s» указано в отчётах (%d): объедините его с другим значением", name, entry.Reports)
+	}
+
+	if err := d.repo.DeleteGuidebookEntry(ctx, g, name); err != nil {
+		d.log.Errorw("DeleteGuidebookEntry failed", "guidebook", g, "name", name, "error", err)
+		return err
+	}
+	d.log.Infow("Guidebook entry deleted", "guidebook", g, "name", name)
+
+	return nil
+}
+
+// guidebookEntry ищет значение name справочника g
+func (d *Service) guidebookEntry(ctx context.Context, g models.Guidebook, name string) (models.GuidebookEntry, bool, error) {
+	items, err := d.ListGuidebook(ctx, g, "")
+	if err != nil {
+		return models.GuidebookEntry{}, false, err
+	}
+	for _, it := range items {
+		if it.Name == name {
+			return it, true, nil
+		}
+	}
+	return models.GuidebookEntry{}, false, nil
+}
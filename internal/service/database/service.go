@@ -5,25 +5,51 @@ import (
 
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/logger"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
-	"github.com/lifedaemon-kill/burovichok-desktop/internal/storage/postgres"
 	"github.com/pkg/errors"
 )
 
-// Service реализуется через Postgres хранилище
+// Repository — хранилище отчётов, справочников и исследований.
+// Реализуется для PostgreSQL (storage/postgres) и для локального файла SQLite (storage/sqlite).
+type Repository interface {
+	GetAllTableFive(ctx context.Context) ([]models.TableFive, error)
+	AddBlockFive(ctx context.Context, data models.TableFive) (int64, error)
+	UpdateBlockFiveAnalysis(ctx context.Context, data models.TableFive) error
+
+	SaveResearchBlocks(ctx context.Context, reportID int64, r models.Research) error
+	GetResearch(ctx context.Context, reportID int64) (models.Research, error)
+	DeleteResearch(ctx context.Context, reportID int64) error
+
+	GetAllOilField(ctx context.Context) ([]models.OilField, error)
+	GetAllInstrumentType(ctx context.Context) ([]models.InstrumentType, error)
+	GetAllProductiveHorizon(ctx context.Context) ([]models.ProductiveHorizon, error)
+	GetAllResearchType(ctx context.Context) ([]models.ResearchType, error)
+	AddOilField(ctx context.Context, fields []models.OilField) error
+	AddInstrumentType(ctx context.Context, items []models.InstrumentType) error
+	AddProductiveHorizon(ctx context.Context, items []models.ProductiveHorizon) error
+	AddResearchType(ctx context.Context, items []models.ResearchType) error
+	ListGuidebook(ctx context.Context, g models.Guidebook) ([]models.GuidebookEntry, error)
+	RenameGuidebookEntry(ctx context.Context, g models.Guidebook, from, to string) error
+	MergeGuidebookEntries(ctx context.Context, g models.Guidebook, from, into string) error
+	DeleteGuidebookEntry(ctx context.Context, g models.Guidebook, name string) error
+
+	SaveArchiveInfo(ctx context.Context, info models.ArchiveInfo) error
+}
+
+// Service реализуется через Repository: PostgreSQL или SQLite
 type Service struct {
-	pg  *postgres.Postgres
-	log logger.Logger
+	repo Repository
+	log  logger.Logger
 }
 
-// NewService создаёт Service, используя Postgres
-func NewService(pg *postgres.Postgres, zLog logger.Logger) *Service {
-	zLog.Infow("Postgres repository initialized successfully")
-	return &Service{pg: pg, log: zLog}
+// NewService создаёт Service поверх хранилища repo
+func NewService(repo Repository, zLog logger.Logger) *Service {
+	zLog.Infow("Database repository initialized successfully")
+	return &Service{repo: repo, log: zLog}
 }
 
 
 func (s *Service) SaveArchiveInfo(ctx context.Context, info models.ArchiveInfo) error {
-	err := s.pg.SaveArchiveInfo(ctx, info) // Вызываем метод из postgres
+	err := s.repo.SaveArchiveInfo(ctx, info) // Вызываем метод хранилища
 	if err != nil {
 		s.log.Errorw("Database service failed to save archive info", "object_name", info.ObjectName, "error", err)
 		return errors.Wrap(err, "database: SaveArchiveInfo failed")
@@ -34,7 +60,7 @@ func (s *Service) SaveArchiveInfo(ctx context.Context, info models.ArchiveInfo)
 
 // GetAllReports возвращает все TableFive
 func (d *Service) GetAllReports(ctx context.Context) ([]models.TableFive, error) {
-	reports, err := d.pg.GetAllTableFive(ctx)
+	reports, err := d.repo.GetAllTableFive(ctx)
 	if err != nil {
 		d.log.Errorw("GetAllReports failed", "error", err)
 		return nil, err
@@ -46,7 +72,7 @@ func (d *Service) GetAllReports(ctx context.Context) ([]models.TableFive, error)
 
 // SaveReport сохраняет TableFive и возвращает ID
 func (d *Service) SaveReport(ctx context.Context, tableFive models.TableFive) (int64, error) {
-	id, err := d.pg.AddBlockFive(ctx, tableFive)
+	id, err := d.repo.AddBlockFive(ctx, tableFive)
 	if err != nil {
 		d.log.Errorw("SaveReport failed", "error", err)
 		return 0, err
@@ -56,9 +82,54 @@ func (d *Service) SaveReport(ctx context.Context, tableFive models.TableFive) (i
 	return id, nil
 }
 
+// UpdateReportAnalysis сохраняет свойства пласта и результаты интерпретации КВД в отчёте tableFive.ID
+func (d *Service) UpdateReportAnalysis(ctx context.Context, tableFive models.TableFive) error {
+	if err := d.repo.UpdateBlockFiveAnalysis(ctx, tableFive); err != nil {
+		d.log.Errorw("UpdateReportAnalysis failed", "id", tableFive.ID, "error", err)
+		return err
+	}
+	d.log.Debugw("UpdateReportAnalysis succeeded", "id", tableFive.ID)
+
+	return nil
+}
+
+// SaveResearch сохраняет блоки 1–4 и график работы исследования к отчёту reportID, заменяя ранее сохранённые
+func (d *Service) SaveResearch(ctx context.Context, reportID int64, research models.Research) error {
+	if err := d.repo.SaveResearchBlocks(ctx, reportID, research); err != nil {
+		d.log.Errorw("SaveResearch failed", "id", reportID, "error", err)
+		return err
+	}
+	d.log.Debugw("SaveResearch succeeded", "id", reportID, "rows", research.Rows())
+
+	return nil
+}
+
+// LoadResearch возвращает отчёт reportID вместе с блоками 1–4 и графиком работы
+func (d *Service) LoadResearch(ctx context.Context, reportID int64) (models.Research, error) {
+	research, err := d.repo.GetResearch(ctx, reportID)
+	if err != nil {
+		d.log.Errorw("LoadResearch failed", "id", reportID, "error", err)
+		return models.Research{}, err
+	}
+	d.log.Debugw("LoadResearch succeeded", "id", reportID, "rows", research.Rows())
+
+	return research, nil
+}
+
+// DeleteResearch удаляет отчёт reportID вместе с блоками 1–4
+func (d *Service) DeleteResearch(ctx context.Context, reportID int64) error {
+	if err := d.repo.DeleteResearch(ctx, reportID); err != nil {
+		d.log.Errorw("DeleteResearch failed", "id", reportID, "error", err)
+		return err
+	}
+	d.log.Debugw("DeleteResearch succeeded", "id", reportID)
+
+	return nil
+}
+
 // GetAllInstrumentTypes возвращает все InstrumentType
 func (d *Service) GetAllInstrumentTypes(ctx context.Context) ([]models.InstrumentType, error) {
-	items, err := d.pg.GetAllInstrumentType(ctx)
+	items, err := d.repo.GetAllInstrumentType(ctx)
 	if err != nil {
 		d.log.Errorw("GetAllInstrumentTypes failed", "error", err)
 		return nil, err
@@ -70,7 +141,7 @@ func (d *Service) GetAllInstrumentTypes(ctx context.Context) ([]models.Instrumen
 
 // GetAllProductiveHorizons возвращает все ProductiveHorizon
 func (d *Service) GetAllProductiveHorizons(ctx context.Context) ([]models.ProductiveHorizon, error) {
-	items, err := d.pg.GetAllProductiveHorizon(ctx)
+	items, err := d.repo.GetAllProductiveHorizon(ctx)
 	if err != nil {
 		d.log.Errorw("GetAllProductiveHorizons failed", "error", err)
 		return nil, err
@@ -82,7 +153,7 @@ func (d *Service) GetAllProductiveHorizons(ctx context.Context) ([]models.Produc
 
 // GetAllOilFields возвращает все OilField
 func (d *Service) GetAllOilFields(ctx context.Context) ([]models.OilField, error) {
-	items, err := d.pg.GetAllOilField(ctx)
+	items, err := d.repo.GetAllOilField(ctx)
 	if err != nil {
 		d.log.Errorw("GetAllOilFields failed", "error", err)
 		return nil, err
@@ -94,7 +165,7 @@ func (d *Service) GetAllOilFields(ctx context.Context) ([]models.OilField, error
 
 // GetAllResearchTypes возвращает все ResearchTypes
 func (d *Service) GetAllResearchTypes(ctx context.Context) ([]models.ResearchType, error) {
-	items, err := d.pg.GetAllResearchType(ctx)
+	items, err := d.repo.GetAllResearchType(ctx)
 	if err != nil {
 		d.log.Errorw("GetAllResearchTypes failed", "error", err)
 		return nil, err
@@ -106,7 +177,7 @@ func (d *Service) GetAllResearchTypes(ctx context.Context) ([]models.ResearchTyp
 
 // SaveInstrumentTypes сохраняет набор InstrumentType
 func (d *Service) SaveInstrumentTypes(ctx context.Context, types []models.InstrumentType) error {
-	if err := d.pg.AddInstrumentType(ctx, types); err != nil {
+	if err := d.repo.AddInstrumentType(ctx, types); err != nil {
 		d.log.Errorw("SaveInstrumentTypes failed", "error", err)
 		return err
 	}
@@ -117,7 +188,7 @@ func (d *Service) SaveInstrumentTypes(ctx context.Context, types []models.Instru
 
 // SaveOilFields сохраняет набор OilField
 func (d *Service) SaveOilFields(ctx context.Context, fields []models.OilField) error {
-	if err := d.pg.AddOilField(ctx, fields); err != nil {
+	if err := d.repo.AddOilField(ctx, fields); err != nil {
 		d.log.Errorw("SaveOilFields failed", "error", err)
 		return err
 	}
@@ -128,7 +199,7 @@ func (d *Service) SaveOilFields(ctx context.Context, fields []models.OilField) e
 
 // SaveProductiveHorizons сохраняет набор ProductiveHorizon
 func (d *Service) SaveProductiveHorizons(ctx context.Context, horizons []models.ProductiveHorizon) error {
-	if err := d.pg.AddProductiveHorizon(ctx, horizons); err != nil {
+	if err := d.repo.AddProductiveHorizon(ctx, horizons); err != nil {
 		d.log.Errorw("SaveProductiveHorizons failed", "error", err)
 		return err
 	}
@@ -139,7 +210,7 @@ func (d *Service) SaveProductiveHorizons(ctx context.Context, horizons []models.
 
 // SaveResearchTypes сохраняет набор ResearchType
 func (d *Service) SaveResearchTypes(ctx context.Context, researches []models.ResearchType) error {
-	if err := d.pg.AddResearchType(ctx, researches); err != nil {
+	if err := d.repo.AddResearchType(ctx, researches); err != nil {
 		d.log.Errorw("SaveResearchTypes failed", "error", err)
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/cockroachdb/errors"
@@ -14,14 +15,20 @@ import (
 )
 
 type Archiver interface {
-	// Archive собирает данные t1-t5 и возвращает ZIP-архив в буфере
+	// Archive собирает данные t1-t5 и возвращает ZIP-архив в буфере.
+	// Давления, температуры и дебиты блоков 1–3 выгружаются в единицах units, единица указана в заголовке.
 	Archive(
 		t1 []models.TableOne,
 		t2 []models.TableTwo,
 		t3 []models.TableThree,
 		t4 []models.TableFour,
 		t5 models.TableFive,
+		units models.UnitSystem,
 	) (*bytes.Buffer, error)
+	// FrameXLSX возвращает книгу Excel с блоками 1–3, выведенными на общую временную сетку
+	FrameXLSX(frame models.Frame, units models.UnitSystem) (*bytes.Buffer, error)
+	// ComparisonXLSX возвращает книгу Excel с величиной нескольких исследований: по две колонки на исследование
+	ComparisonXLSX(c models.Comparison, units models.UnitSystem) (*bytes.Buffer, error)
 }
 
 // Service реализует интерфейс Archiver
@@ -49,8 +56,9 @@ func (s *service) Archive(
 	t3 []models.TableThree,
 	t4 []models.TableFour,
 	t5 models.TableFive,
+	units models.UnitSystem,
 ) (*bytes.Buffer, error) {
-	s.log.Infow("Starting archiving process")
+	s.log.Infow("Starting archiving process", "units", units.String())
 
 	//Проверяем, что все блоки импортированы
 	if (len(t1) * len(t2) * len(t3) * len(t4)) == 0 {
@@ -69,7 +77,7 @@ func (s *service) Archive(
 
 	// 2. Создаем и добавляем XLSX файлы в архив
 	// Блок 1
-	if err := tableOneToXLSXBuffer(zipWriter, "Block_1_PressureTemp.xlsx", t1); err != nil {
+	if err := tableOneToXLSXBuffer(zipWriter, "Block_1_PressureTemp.xlsx", t1, units); err != nil {
 		finalErr = errors.Wrap(err, "failed to add block 1 to zip") // Собираем ошибки
 		s.log.Errorw("Archiver error", "error", finalErr)           // Логируем
 		// Не выходим сразу, пытаемся добавить другие файлы
@@ -78,7 +86,7 @@ func (s *service) Archive(
 	}
 
 	// Блок 2
-	if err := tableTwoToXLSXBuffer(zipWriter, "Block_2_TubingAnnulus.xlsx", t2, s.log); err != nil {
+	if err := tableTwoToXLSXBuffer(zipWriter, "Block_2_TubingAnnulus.xlsx", t2, units, s.log); err != nil {
 		finalErr = errors.Wrap(err, "failed to add block 2 to zip")
 		s.log.Errorw("Archiver error", "error", finalErr)
 	} else {
@@ -86,7 +94,7 @@ func (s *service) Archive(
 	}
 
 	// Блок 3
-	if err := tableThreeToXLSXBuffer(zipWriter, "Block_3_FlowRates.xlsx", t3, s.log); err != nil {
+	if err := tableThreeToXLSXBuffer(zipWriter, "Block_3_FlowRates.xlsx", t3, units, s.log); err != nil {
 		finalErr = errors.Wrap(err, "failed to add block 3 to zip")
 		s.log.Errorw("Archiver error", "error", finalErr)
 	} else {
@@ -129,14 +137,24 @@ func (s *service) Archive(
 
 // --- Хелперы для записи данных в XLSX и добавления в ZIP ---
 
+// withUnit дописывает к заголовку колонки единицу величины q, например "pressure_depth (бар)".
+func withUnit(header string, units models.UnitSystem, q models.Quantity) string {
+	return header + " (" + units.Symbol(q) + ")"
+}
+
 // Создадим отдельные функции для каждого типа таблицы
-func tableOneToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.TableOne) error {
+func tableOneToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.TableOne, units models.UnitSystem) error {
 	xlsxFile := excelize.NewFile()
 	sheetName := "Block1_PressureTemp"
 	_ = xlsxFile.SetSheetName("Sheet1", sheetName) // Переименуем лист
 
 	// Заголовки (лучше брать из модели, если есть Columns())
-	headers := []string{"timestamp", "pressure_depth", "temperature_depth", "pressure_at_vdp"}
+	headers := []string{
+		"timestamp",
+		withUnit("pressure_depth", units, models.QuantityPressure),
+		withUnit("temperature_depth", units, models.QuantityTemperature),
+		withUnit("pressure_at_vdp", units, models.QuantityPressure),
+	}
 	for i, h := range headers {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
 		_ = xlsxFile.SetCellValue(sheetName, cell, h)
@@ -145,10 +163,14 @@ func tableOneToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.
 	// Данные
 	for rowIdx, rowData := range data {
 		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("A%d", rowIdx+2), rowData.Timestamp) // Excelize сам может форматнуть время
-		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("B%d", rowIdx+2), rowData.PressureDepth)
-		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("C%d", rowIdx+2), rowData.TemperatureDepth)
-		//надо подумоть
-		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("D%d", rowIdx+2), rowData.PressureAtVDP)
+		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("B%d", rowIdx+2), units.FromStorage(models.QuantityPressure, rowData.PressureDepth))
+		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("C%d", rowIdx+2), units.FromStorage(models.QuantityTemperature, rowData.TemperatureDepth))
+		// NaN — замер вне графика работы, ячейка остаётся пустой
+		if math.IsNaN(rowData.PressureAtVDP) {
+			_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("D%d", rowIdx+2), nil)
+		} else {
+			_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("D%d", rowIdx+2), units.FromStorage(models.QuantityPressure, rowData.PressureAtVDP))
+		}
 	}
 
 	// Запись файла в ZIP
@@ -162,15 +184,23 @@ func tableOneToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.
 	return nil
 }
 
-func tableTwoToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.TableTwo, log logger.Logger) error { // Добавлен аргумент log
+// pressureCell возвращает значение ячейки давления; NaN — провал, убранный очисткой, ячейка остаётся пустой.
+func pressureCell(v float64, units models.UnitSystem) any {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return units.FromStorage(models.QuantityPressure, v)
+}
+
+func tableTwoToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.TableTwo, units models.UnitSystem, log logger.Logger) error { // Добавлен аргумент log
 	xlsxFile := excelize.NewFile()
 	sheetName := "Block2_TubingAnnulus"
 	_ = xlsxFile.SetSheetName("Sheet1", sheetName)
 
 	headers := []string{
-		"timestamp_tubing", "pressure_tubing",
-		"timestamp_annulus", "pressure_annulus",
-		"timestamp_linear", "pressure_linear",
+		"timestamp_tubing", withUnit("pressure_tubing", units, models.QuantityPressure),
+		"timestamp_annulus", withUnit("pressure_annulus", units, models.QuantityPressure),
+		"timestamp_linear", withUnit("pressure_linear", units, models.QuantityPressure),
 	}
 	for i, h := range headers {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
@@ -194,7 +224,7 @@ func tableTwoToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.
 			log.Errorw("Failed to get col name", "col", col, "error", err)
 			return errors.Wrapf(err, "col num %d", col)
 		}
-		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("%s%d", colName, rowIdx+2), rowData.PressureTubing)
+		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("%s%d", colName, rowIdx+2), pressureCell(rowData.PressureTubing, units))
 		col++
 
 		colName, err = excelize.ColumnNumberToName(col)
@@ -210,7 +240,7 @@ func tableTwoToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.
 			log.Errorw("Failed to get col name", "col", col, "error", err)
 			return errors.Wrapf(err, "col num %d", col)
 		}
-		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("%s%d", colName, rowIdx+2), rowData.PressureAnnulus)
+		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("%s%d", colName, rowIdx+2), pressureCell(rowData.PressureAnnulus, units))
 		col++
 
 		colName, err = excelize.ColumnNumberToName(col)
@@ -226,7 +256,7 @@ func tableTwoToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.
 			log.Errorw("Failed to get col name", "col", col, "error", err)
 			return errors.Wrapf(err, "col num %d", col)
 		}
-		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("%s%d", colName, rowIdx+2), rowData.PressureLinear)
+		_ = xlsxFile.SetCellValue(sheetName, fmt.Sprintf("%s%d", colName, rowIdx+2), pressureCell(rowData.PressureLinear, units))
 	}
 
 	// Запись файла в ZIP
@@ -240,14 +270,26 @@ func tableTwoToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.
 	return nil
 }
 
-func tableThreeToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.TableThree, log logger.Logger) error { // Добавлен логгер
+func tableThreeToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models.TableThree, units models.UnitSystem, log logger.Logger) error { // Добавлен логгер
 	xlsxFile := excelize.NewFile()
 	sheetName := "Block3_FlowRates"
 	_ = xlsxFile.SetSheetName("Sheet1", sheetName)
 
 	headers := []string{
-		"timestamp", "flow_liquid", "water_cut", "flow_gas",
-		"oil_flow_rate", "water_flow_rate", "gas_oil_ratio",
+		"timestamp",
+		withUnit("flow_liquid", units, models.QuantityLiquidRate),
+		"water_cut (%)",
+		withUnit("flow_gas", units, models.QuantityGasRate),
+		withUnit("oil_flow_rate", units, models.QuantityLiquidRate),
+		withUnit("water_flow_rate", units, models.QuantityLiquidRate),
+		"gas_oil_ratio (м³/м³)",
+	}
+	liquid := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		out := units.FromStorage(models.QuantityLiquidRate, *v)
+		return &out
 	}
 	for i, h := range headers {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
@@ -258,15 +300,15 @@ func tableThreeToXLSXBuffer(zipWriter *zip.Writer, filename string, data []model
 		col := 1
 		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.Timestamp, log) // Передаем логгер в setCellValue
 		col++
-		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.LiquidFlowRate, log)
+		setCellValue(xlsxFile, sheetName, col, rowIdx+2, units.FromStorage(models.QuantityLiquidRate, rowData.LiquidFlowRate), log)
 		col++
 		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.WaterCut, log)
 		col++
-		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.GasFlowRate, log)
+		setCellValue(xlsxFile, sheetName, col, rowIdx+2, units.FromStorage(models.QuantityGasRate, rowData.GasFlowRate), log)
 		col++
-		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.OilFlowRate, log)
+		setCellValue(xlsxFile, sheetName, col, rowIdx+2, liquid(rowData.OilFlowRate), log)
 		col++
-		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.WaterFlowRate, log)
+		setCellValue(xlsxFile, sheetName, col, rowIdx+2, liquid(rowData.WaterFlowRate), log)
 		col++
 		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.GasFactor, log) // GasFactor поле называется
 	}
@@ -286,7 +328,8 @@ func tableFourToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models
 	sheetName := "Block4_Inclinometry"
 	_ = xlsxFile.SetSheetName("Sheet1", sheetName)
 
-	headers := []string{"research_id", "measure_depth", "true_vertical_depth", "true_vertical_depth_sub_sea"}
+	headers := []string{"research_id", "measure_depth", "true_vertical_depth", "true_vertical_depth_sub_sea",
+		"inclination", "azimuth", "northing", "easting", "dogleg_severity"}
 	for i, h := range headers {
 		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
 		_ = xlsxFile.SetCellValue(sheetName, cell, h)
@@ -301,6 +344,11 @@ func tableFourToXLSXBuffer(zipWriter *zip.Writer, filename string, data []models
 		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.TrueVerticalDepth, log)
 		col++
 		setCellValue(xlsxFile, sheetName, col, rowIdx+2, rowData.TrueVerticalDepthSubSea, log)
+		// Рассчитанные по углам поля; для готовой таблицы MD/TVD/TVDSS ячейки пустые
+		for _, v := range []*float64{rowData.Inclination, rowData.Azimuth, rowData.Northing, rowData.Easting, rowData.DoglegSeverity} {
+			col++
+			setCellValue(xlsxFile, sheetName, col, rowIdx+2, v, log)
+		}
 	}
 
 	fileWriter, err := zipWriter.Create(filename)
@@ -385,3 +433,135 @@ func setCellValue(f *excelize.File, sheet string, col, row int, value interface{
 		_ = f.SetCellValue(sheet, cellName, v)
 	}
 }
+
+// FrameXLSX записывает выровненные по времени блоки 1–3 в книгу Excel: первая колонка — узел сетки,
+// далее по колонке на величину в единицах units; пустой узел — пустая ячейка.
+func (s *service) FrameXLSX(frame models.Frame, units models.UnitSystem) (*bytes.Buffer, error) {
+	if frame.Len() == 0 {
+		return nil, errors.New("нет данных для выгрузки")
+	}
+	if frame.Len() >= excelize.TotalRows {
+		return nil, errors.Newf("узлов сетки %d, в лист Excel помещается %d строк: увеличьте шаг", frame.Len(), excelize.TotalRows-1)
+	}
+
+	xlsxFile := excelize.NewFile()
+	defer xlsxFile.Close()
+	sheetName := "Aligned_Blocks_1_3"
+	_ = xlsxFile.SetSheetName("Sheet1", sheetName)
+
+	// Потоковая запись: сетка с мелким шагом — это сотни тысяч строк
+	sw, err := xlsxFile.NewStreamWriter(sheetName)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewStreamWriter")
+	}
+	header := []interface{}{"timestamp"}
+	quantities := make([]models.Quantity, len(frame.Channels))
+	for j, ch := range frame.Channels {
+		name := string(ch)
+		if q, ok := ch.Quantity(); ok {
+			quantities[j], name = q, withUnit(name, units, q)
+		}
+		header = append(header, name)
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return nil, errors.Wrap(err, "write header")
+	}
+	row := make([]interface{}, len(frame.Channels)+1)
+	for i, t := range frame.Times {
+		row[0] = t
+		for j := range frame.Channels {
+			row[j+1] = nil
+			v := frame.Values[j][i]
+			if math.IsNaN(v) {
+				continue
+			}
+			if quantities[j] != "" {
+				v = units.FromStorage(quantities[j], v)
+			}
+			row[j+1] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := sw.SetRow(cell, row); err != nil {
+			return nil, errors.Wrapf(err, "write row %d", i+2)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, errors.Wrap(err, "flush")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := xlsxFile.Write(buf); err != nil {
+		return nil, errors.Wrap(err, "xlsxFile.Write")
+	}
+	s.log.Infow("Aligned frame written to XLSX", "rows", frame.Len(), "channels", len(frame.Channels), "size_bytes", buf.Len())
+	return buf, nil
+}
+
+// ComparisonXLSX записывает величину нескольких исследований в книгу Excel. У исследований свои
+// метки времени, поэтому на каждое по две колонки: время (или часы от начала) и значение в единицах units.
+func (s *service) ComparisonXLSX(c models.Comparison, units models.UnitSystem) (*bytes.Buffer, error) {
+	if c.Len() == 0 {
+		return nil, errors.New("нет данных для выгрузки")
+	}
+	if c.Len() >= excelize.TotalRows {
+		return nil, errors.Newf("замеров %d, в лист Excel помещается %d строк", c.Len(), excelize.TotalRows-1)
+	}
+
+	xlsxFile := excelize.NewFile()
+	defer xlsxFile.Close()
+	sheetName := "Comparison"
+	_ = xlsxFile.SetSheetName("Sheet1", sheetName)
+
+	sw, err := xlsxFile.NewStreamWriter(sheetName)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewStreamWriter")
+	}
+	timeHeader := "timestamp"
+	if c.Relative {
+		timeHeader = "t, ч"
+	}
+	q, convert := c.Channel.Quantity()
+	header := make([]interface{}, 0, 2*len(c.Series))
+	for _, sr := range c.Series {
+		header = append(header, sr.Research+": "+timeHeader, sr.Research+": "+c.Channel.Label(units))
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return nil, errors.Wrap(err, "write header")
+	}
+	row := make([]interface{}, 2*len(c.Series))
+	for i := 0; i < c.Len(); i++ {
+		for j, sr := range c.Series {
+			row[2*j], row[2*j+1] = nil, nil
+			if i >= len(sr.Times) {
+				continue
+			}
+			if c.Relative {
+				row[2*j] = sr.Elapsed(i)
+			} else {
+				row[2*j] = sr.Times[i]
+			}
+			v := sr.Values[i]
+			if math.IsNaN(v) {
+				continue
+			}
+			if convert {
+				v = units.FromStorage(q, v)
+			}
+			row[2*j+1] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := sw.SetRow(cell, row); err != nil {
+			return nil, errors.Wrapf(err, "write row %d", i+2)
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, errors.Wrap(err, "flush")
+	}
+
+	buf := new(bytes.Buffer)
+	if err := xlsxFile.Write(buf); err != nil {
+		return nil, errors.Wrap(err, "xlsxFile.Write")
+	}
+	s.log.Infow("Comparison written to XLSX", "channel", c.Channel, "series", len(c.Series), "size_bytes", buf.Len())
+	return buf, nil
+}
@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// Service очищает записи манометров: убирает провалы и выбросы, сглаживает и прореживает ряды.
+// Исходные срезы не меняются, результат — новый срез.
+type Service struct{}
+
+// NewService создает новый экземпляр сервиса очистки сигнала.
+func NewService() *Service {
+	return &Service{}
+}
+
+// Validate проверяет согласованность параметров очистки.
+func Validate(opts models.FilterOptions) error {
+	switch opts.Despike {
+	case models.DespikeNone:
+	case models.DespikeMedian, models.DespikeHampel:
+		if opts.DespikeWindow < 3 || opts.DespikeWindow%2 == 0 {
+			return errors.New("окно удаления выбросов — нечётное число замеров, не меньше 3")
+		}
+		if opts.Despike == models.DespikeHampel && !(opts.Threshold > 0) {
+			return errors.New("порог фильтра Хампеля должен быть больше нуля")
+		}
+	default:
+		return errors.Newf("неизвестный способ удаления выбросов %q", opts.Despike)
+	}
+	switch opts.Smooth {
+	case models.SmoothNone:
+	case models.SmoothMovingAverage, models.SmoothSavitzkyGolay:
+		if opts.SmoothWindow < 3 || opts.SmoothWindow%2 == 0 {
+			return errors.New("окно сглаживания — нечётное число замеров, не меньше 3")
+		}
+		if opts.Smooth == models.SmoothSavitzkyGolay && (opts.PolyOrder < 1 || opts.PolyOrder >= opts.SmoothWindow) {
+			return errors.New("степень полинома Савицкого — Голея — от 1 до размера окна минус 1")
+		}
+	default:
+		return errors.Newf("неизвестный способ сглаживания %q", opts.Smooth)
+	}
+	if opts.MinDeltaP < 0 || opts.MaxDeltaT < 0 {
+		return errors.New("пороги прореживания не могут быть отрицательными")
+	}
+	return nil
+}
+
+// TableOne очищает блок 1. Выбросы и сглаживание применяются к давлению и температуре,
+// провалы и прореживание определяются по давлению. Записи упорядочиваются по времени.
+// Рзаб на ВДП не пересчитывается: его считают по очищенному давлению после очистки.
+func (s *Service) TableOne(data []models.TableOne, opts models.FilterOptions) ([]models.TableOne, models.FilterReport, error) {
+	report := models.FilterReport{Input: len(data)}
+	if err := Validate(opts); err != nil {
+		return nil, report, err
+	}
+
+	out := make([]models.TableOne, 0, len(data))
+	for _, rec := range data {
+		if opts.DropNonPositive && rec.PressureDepth <= 0 {
+			report.Dropouts++
+			continue
+		}
+		out = append(out, rec)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+
+	pressure := make([]float64, len(out))
+	temperature := make([]float64, len(out))
+	for i, rec := range out {
+		pressure[i], temperature[i] = rec.PressureDepth, rec.TemperatureDepth
+	}
+	var spikes int
+	pressure, spikes = clean(pressure, opts)
+	report.Spikes += spikes
+	temperature, spikes = clean(temperature, opts)
+	report.Spikes += spikes
+	for i := range out {
+		out[i].PressureDepth, out[i].TemperatureDepth = pressure[i], temperature[i]
+	}
+
+	if opts.Decimates() {
+		keep := decimate(len(out), opts,
+			func(i int) time.Time { return out[i].Timestamp },
+			func(i int) []float64 { return []float64{out[i].PressureDepth} },
+		)
+		report.Decimated = len(out) - len(keep)
+		kept := make([]models.TableOne, len(keep))
+		for k, i := range keep {
+			kept[k] = out[i]
+		}
+		out = kept
+	}
+	report.Output = len(out)
+	return out, report, nil
+}
+
+// TableTwo очищает блок 2. У трубного, затрубного и линейного давления свои метки времени,
+// поэтому каждое очищается отдельно. Провал убирается только в своём давлении: значение
+// становится NaN, а строка убирается, лишь если провал во всех трёх. Прореживанием строка
+// убирается целиком — если ни одно давление не изменилось на MinDeltaP и по трубному времени
+// не прошло MaxDeltaT. Порядок строк сохраняется.
+func (s *Service) TableTwo(data []models.TableTwo, opts models.FilterOptions) ([]models.TableTwo, models.FilterReport, error) {
+	report := models.FilterReport{Input: len(data)}
+	if err := Validate(opts); err != nil {
+		return nil, report, err
+	}
+
+	fields := []func(*models.TableTwo) *float64{
+		func(r *models.TableTwo) *float64 { return &r.PressureTubing },
+		func(r *models.TableTwo) *float64 { return &r.PressureAnnulus },
+		func(r *models.TableTwo) *float64 { return &r.PressureLinear },
+	}
+	out := make([]models.TableTwo, 0, len(data))
+	for _, rec := range data {
+		if opts.DropNonPositive {
+			dropped := 0
+			for _, field := range fields {
+				if p := field(&rec); *p <= 0 {
+					*p = math.NaN()
+					dropped++
+				}
+			}
+			report.Dropouts += dropped
+			if dropped == len(fields) {
+				continue
+			}
+		}
+		out = append(out, rec)
+	}
+
+	values := make([]float64, len(out))
+	for _, field := range fields {
+		for i := range out {
+			values[i] = *field(&out[i])
+		}
+		cleaned, spikes := clean(values, opts)
+		report.Spikes += spikes
+		for i := range out {
+			*field(&out[i]) = cleaned[i]
+		}
+	}
+
+	if opts.Decimates() {
+		keep := decimate(len(out), opts,
+			func(i int) time.Time { return out[i].TimestampTubing },
+			func(i int) []float64 {
+				return []float64{out[i].PressureTubing, out[i].PressureAnnulus, out[i].PressureLinear}
+			},
+		)
+		report.Decimated = len(out) - len(keep)
+		kept := make([]models.TableTwo, len(keep))
+		for k, i := range keep {
+			kept[k] = out[i]
+		}
+		out = kept
+	}
+	report.Output = len(out)
+	return out, report, nil
+}
+
+// clean удаляет выбросы и сглаживает ряд; возвращает новый ряд и число заменённых выбросов.
+// NaN — убранный провал: фильтры работают по остальным замерам, NaN остаются на своих местах.
+func clean(values []float64, opts models.FilterOptions) ([]float64, int) {
+	if len(values) == 0 {
+		return values, 0
+	}
+	present := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			present = append(present, v)
+		}
+	}
+	if len(present) < len(values) {
+		cleaned, spikes := clean(present, opts)
+		out := make([]float64, len(values))
+		k := 0
+		for i, v := range values {
+			if math.IsNaN(v) {
+				out[i] = v
+				continue
+			}
+			out[i] = cleaned[k]
+			k++
+		}
+		return out, spikes
+	}
+	spikes := 0
+	switch opts.Despike {
+	case models.DespikeMedian:
+		values, spikes = despike(values, opts.DespikeWindow/2, 0)
+	case models.DespikeHampel:
+		values, spikes = despike(values, opts.DespikeWindow/2, opts.Threshold)
+	}
+	switch opts.Smooth {
+	case models.SmoothMovingAverage:
+		values = movingAverage(values, opts.SmoothWindow/2)
+	case models.SmoothSavitzkyGolay:
+		values = savitzkyGolay(values, opts.SmoothWindow/2, opts.PolyOrder)
+	}
+	return values, spikes
+}
+
+// decimate возвращает индексы оставляемых замеров: первый, последний и каждый, у которого
+// хотя бы одна величина изменилась на MinDeltaP или время ушло на MaxDeltaT от последнего оставленного.
+// Появление или пропажа значения (NaN) тоже считается изменением.
+func decimate(n int, opts models.FilterOptions, at func(int) time.Time, values func(int) []float64) []int {
+	if n <= 2 {
+		keep := make([]int, n)
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+	keep := []int{0}
+	last := 0
+	for i := 1; i < n-1; i++ {
+		if opts.MaxDeltaT > 0 && at(i).Sub(at(last)) >= opts.MaxDeltaT {
+			keep, last = append(keep, i), i
+			continue
+		}
+		if opts.MinDeltaP > 0 {
+			ref := values(last)
+			for k, v := range values(i) {
+				if math.IsNaN(v) != math.IsNaN(ref[k]) || math.Abs(v-ref[k]) >= opts.MinDeltaP {
+					keep, last = append(keep, i), i
+					break
+				}
+			}
+		}
+	}
+	return append(keep, n-1)
+}
@@ -0,0 +1,217 @@
+package filter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+var t0 = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return t0.Add(time.Duration(minutes) * time.Minute)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts models.FilterOptions
+		ok   bool
+	}{
+		{name: "без очистки", ok: true},
+		{name: "медианный фильтр", opts: models.FilterOptions{Despike: models.DespikeMedian, DespikeWindow: 5}, ok: true},
+		{name: "чётное окно выбросов", opts: models.FilterOptions{Despike: models.DespikeMedian, DespikeWindow: 4}},
+		{name: "окно выбросов меньше 3", opts: models.FilterOptions{Despike: models.DespikeMedian, DespikeWindow: 1}},
+		{name: "Хампель без порога", opts: models.FilterOptions{Despike: models.DespikeHampel, DespikeWindow: 5}},
+		{name: "неизвестный способ выбросов", opts: models.FilterOptions{Despike: "wavelet"}},
+		{name: "Савицкий — Голей", opts: models.FilterOptions{Smooth: models.SmoothSavitzkyGolay, SmoothWindow: 7, PolyOrder: 2}, ok: true},
+		{name: "чётное окно сглаживания", opts: models.FilterOptions{Smooth: models.SmoothMovingAverage, SmoothWindow: 6}},
+		{name: "степень не меньше окна", opts: models.FilterOptions{Smooth: models.SmoothSavitzkyGolay, SmoothWindow: 5, PolyOrder: 5}},
+		{name: "нулевая степень", opts: models.FilterOptions{Smooth: models.SmoothSavitzkyGolay, SmoothWindow: 5}},
+		{name: "неизвестный способ сглаживания", opts: models.FilterOptions{Smooth: "kalman"}},
+		{name: "отрицательный порог давления", opts: models.FilterOptions{MinDeltaP: -0.1}},
+		{name: "отрицательный порог времени", opts: models.FilterOptions{MaxDeltaT: -time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.opts); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v; ожидалась ошибка: %v", err, !tt.ok)
+			}
+		})
+	}
+}
+
+func TestTableOne(t *testing.T) {
+	rows := func(pressures ...float64) []models.TableOne {
+		data := make([]models.TableOne, len(pressures))
+		for i, p := range pressures {
+			data[i] = models.TableOne{Timestamp: at(i), PressureDepth: p, TemperatureDepth: 20}
+		}
+		return data
+	}
+	tests := []struct {
+		name   string
+		data   []models.TableOne
+		opts   models.FilterOptions
+		want   []float64
+		report models.FilterReport
+	}{
+		{name: "пустой ряд", opts: models.FilterOptions{DropNonPositive: true, Despike: models.DespikeMedian, DespikeWindow: 3, MinDeltaP: 1}},
+		{
+			name:   "провалы убираются строкой",
+			data:   rows(10, 0, 11, -5, 12),
+			opts:   models.FilterOptions{DropNonPositive: true},
+			want:   []float64{10, 11, 12},
+			report: models.FilterReport{Input: 5, Dropouts: 2, Output: 3},
+		},
+		{
+			name:   "выброс Хампеля",
+			data:   rows(10, 10, 10, 100, 10, 10),
+			opts:   models.FilterOptions{Despike: models.DespikeHampel, DespikeWindow: 3, Threshold: 3},
+			want:   []float64{10, 10, 10, 10, 10, 10},
+			report: models.FilterReport{Input: 6, Spikes: 1, Output: 6},
+		},
+		{
+			name:   "прореживание по давлению",
+			data:   rows(10, 10.05, 10.2, 10.25, 10.3),
+			opts:   models.FilterOptions{MinDeltaP: 0.1},
+			want:   []float64{10, 10.2, 10.3},
+			report: models.FilterReport{Input: 5, Decimated: 2, Output: 3},
+		},
+		{
+			name:   "прореживание по времени",
+			data:   rows(10, 10, 10, 10, 10),
+			opts:   models.FilterOptions{MaxDeltaT: 2 * time.Minute},
+			want:   []float64{10, 10, 10},
+			report: models.FilterReport{Input: 5, Decimated: 2, Output: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := NewService().TableOne(tt.data, tt.opts)
+			if err != nil {
+				t.Fatalf("TableOne: %v", err)
+			}
+			if report != tt.report {
+				t.Errorf("отчёт %+v; ожидалось %+v", report, tt.report)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("записей %d; ожидалось %d", len(got), len(tt.want))
+			}
+			for i, rec := range got {
+				if math.Abs(rec.PressureDepth-tt.want[i]) > 1e-9 {
+					t.Errorf("запись %d: давление %v; ожидалось %v", i, rec.PressureDepth, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTableOneSortsByTime(t *testing.T) {
+	data := []models.TableOne{
+		{Timestamp: at(2), PressureDepth: 12},
+		{Timestamp: at(0), PressureDepth: 10},
+		{Timestamp: at(1), PressureDepth: 11},
+	}
+	got, _, err := NewService().TableOne(data, models.FilterOptions{})
+	if err != nil {
+		t.Fatalf("TableOne: %v", err)
+	}
+	for i, rec := range got {
+		if !rec.Timestamp.Equal(at(i)) {
+			t.Errorf("запись %d: время %s; ожидалось %s", i, rec.Timestamp, at(i))
+		}
+	}
+	if data[0].PressureDepth != 12 {
+		t.Error("исходный срез изменён")
+	}
+}
+
+func TestTableTwoDropNonPositive(t *testing.T) {
+	nan := math.NaN()
+	row := func(minute int, tubing, annulus, linear float64) models.TableTwo {
+		return models.TableTwo{
+			TimestampTubing: at(minute), PressureTubing: tubing,
+			TimestampAnnulus: at(minute), PressureAnnulus: annulus,
+			TimestampLinear: at(minute), PressureLinear: linear,
+		}
+	}
+	// провал только затрубного давления во второй строке и всех трёх — в пятой
+	data := []models.TableTwo{
+		row(0, 5, 3, 2),
+		row(1, 5, 0, 2),
+		row(2, 5, 3, 2),
+		row(3, 5, 3, 2),
+		row(4, 0, -1, 0),
+		row(5, 5, 3, 2),
+	}
+	tests := []struct {
+		name   string
+		opts   models.FilterOptions
+		want   [][3]float64
+		report models.FilterReport
+	}{
+		{
+			name: "провал убирается в своём давлении",
+			opts: models.FilterOptions{DropNonPositive: true},
+			want: [][3]float64{{5, 3, 2}, {5, nan, 2}, {5, 3, 2}, {5, 3, 2}, {5, 3, 2}},
+			// одно значение во второй строке и три — в пятой
+			report: models.FilterReport{Input: 6, Dropouts: 4, Output: 5},
+		},
+		{
+			name:   "пропажа и появление значения не прореживаются",
+			opts:   models.FilterOptions{DropNonPositive: true, MinDeltaP: 1},
+			want:   [][3]float64{{5, 3, 2}, {5, nan, 2}, {5, 3, 2}, {5, 3, 2}},
+			report: models.FilterReport{Input: 6, Dropouts: 4, Decimated: 1, Output: 4},
+		},
+		{
+			name:   "выбросы ищутся мимо провалов",
+			opts:   models.FilterOptions{DropNonPositive: true, Despike: models.DespikeHampel, DespikeWindow: 3, Threshold: 3},
+			want:   [][3]float64{{5, 3, 2}, {5, nan, 2}, {5, 3, 2}, {5, 3, 2}, {5, 3, 2}},
+			report: models.FilterReport{Input: 6, Dropouts: 4, Output: 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := NewService().TableTwo(data, tt.opts)
+			if err != nil {
+				t.Fatalf("TableTwo: %v", err)
+			}
+			if report != tt.report {
+				t.Errorf("отчёт %+v; ожидалось %+v", report, tt.report)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("записей %d; ожидалось %d", len(got), len(tt.want))
+			}
+			for i, rec := range got {
+				for k, v := range []float64{rec.PressureTubing, rec.PressureAnnulus, rec.PressureLinear} {
+					w := tt.want[i][k]
+					if math.IsNaN(w) != math.IsNaN(v) || !math.IsNaN(w) && v != w {
+						t.Errorf("запись %d, давление %d: %v; ожидалось %v", i, k, v, w)
+					}
+				}
+			}
+		})
+	}
+	if data[1].PressureAnnulus != 0 {
+		t.Error("исходный срез изменён")
+	}
+}
+
+func TestCleanKeepsNaN(t *testing.T) {
+	nan := math.NaN()
+	got, spikes := clean([]float64{1, nan, 1, 100, 1}, models.FilterOptions{
+		Despike: models.DespikeHampel, DespikeWindow: 3, Threshold: 3,
+	})
+	if spikes != 1 {
+		t.Errorf("выбросов %d; ожидалось 1", spikes)
+	}
+	want := []float64{1, nan, 1, 1, 1}
+	for i, v := range got {
+		if math.IsNaN(want[i]) != math.IsNaN(v) || !math.IsNaN(want[i]) && v != want[i] {
+			t.Errorf("замер %d: %v; ожидалось %v", i, v, want[i])
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package filter
+
+import (
+	"math"
+	"sort"
+)
+
+const madToSigma = 1.4826 // перевод MAD в σ для нормального шума
+
+// despike заменяет выбросы медианой окна из 2·half+1 замеров и возвращает число заменённых.
+// У краёв окно укорачивается. threshold ≤ 0 — медианный фильтр: заменяется каждый замер.
+func despike(values []float64, half int, threshold float64) ([]float64, int) {
+	out := make([]float64, len(values))
+	window := make([]float64, 0, 2*half+1)
+	deviations := make([]float64, 0, 2*half+1)
+	replaced := 0
+	for i, v := range values {
+		lo, hi := max(0, i-half), min(len(values), i+half+1)
+		window = append(window[:0], values[lo:hi]...)
+		med := median(window)
+		out[i] = v
+		if threshold <= 0 {
+			out[i] = med
+		} else {
+			deviations = deviations[:0]
+			for _, w := range values[lo:hi] {
+				deviations = append(deviations, math.Abs(w-med))
+			}
+			// при нулевом MAD (ровный участок) любое отличие от медианы — выброс
+			if math.Abs(v-med) > threshold*madToSigma*median(deviations) {
+				out[i] = med
+			}
+		}
+		if out[i] != v {
+			replaced++
+		}
+	}
+	return out, replaced
+}
+
+// median сортирует срез на месте и возвращает его медиану.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// movingAverage — центрированное скользящее среднее по 2·half+1 замерам; у краёв окно укорачивается.
+func movingAverage(values []float64, half int) []float64 {
+	out := make([]float64, len(values))
+	prefix := make([]float64, len(values)+1)
+	for i, v := range values {
+		prefix[i+1] = prefix[i] + v
+	}
+	for i := range values {
+		lo, hi := max(0, i-half), min(len(values), i+half+1)
+		out[i] = (prefix[hi] - prefix[lo]) / float64(hi-lo)
+	}
+	return out
+}
+
+// savitzkyGolay сглаживает ряд полиномом степени order по окну из 2·half+1 замеров.
+// Замеры считаются равноотстоящими. У краёв полином подбирается по крайнему полному окну
+// и вычисляется в точке замера, поэтому края не смещаются к середине ряда.
+func savitzkyGolay(values []float64, half, order int) []float64 {
+	n := len(values)
+	size := 2*half + 1
+	if n < size {
+		size = n
+	}
+	order = min(order, size-1)
+
+	out := make([]float64, n)
+	cache := make(map[int][]float64) // веса по смещению замера от начала окна
+	for i := range values {
+		lo := min(max(0, i-half), n-size)
+		pos := i - lo
+		w, ok := cache[pos]
+		if !ok {
+			w = sgWeights(size, pos, order)
+			cache[pos] = w
+		}
+		var sum float64
+		for j, wj := range w {
+			sum += wj * values[lo+j]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+// sgWeights возвращает веса, с которыми значение МНК-полинома степени order в точке pos окна
+// выражается через size замеров окна: w = A·(AᵀA)⁻¹·e₀, где A — матрица степеней смещений.
+func sgWeights(size, pos, order int) []float64 {
+	m := order + 1
+	a := make([][]float64, size)
+	for j := range a {
+		a[j] = make([]float64, m)
+		x := float64(j - pos)
+		p := 1.0
+		for k := range a[j] {
+			a[j][k] = p
+			p *= x
+		}
+	}
+	// (AᵀA)·z = e₀ методом Гаусса с выбором главного элемента
+	ata := make([][]float64, m)
+	for r := range ata {
+		ata[r] = make([]float64, m+1)
+		for c := 0; c < m; c++ {
+			for j := range a {
+				ata[r][c] += a[j][r] * a[j][c]
+			}
+		}
+	}
+	ata[0][m] = 1
+	for col := 0; col < m; col++ {
+		pivot := col
+		for r := col + 1; r < m; r++ {
+			if math.Abs(ata[r][col]) > math.Abs(ata[pivot][col]) {
+				pivot = r
+			}
+		}
+		ata[col], ata[pivot] = ata[pivot], ata[col]
+		for r := 0; r < m; r++ {
+			if r == col || ata[col][col] == 0 {
+				continue
+			}
+			f := ata[r][col] / ata[col][col]
+			for c := col; c <= m; c++ {
+				ata[r][c] -= f * ata[col][c]
+			}
+		}
+	}
+	z := make([]float64, m)
+	for k := range z {
+		z[k] = ata[k][m] / ata[k][k]
+	}
+
+	w := make([]float64, size)
+	for j := range w {
+		for k := range z {
+			w[j] += a[j][k] * z[k]
+		}
+	}
+	return w
+}
@@ -0,0 +1,299 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// delimiterCandidates перечислены в порядке предпочтения: запятая последняя,
+// потому что при десятичной запятой она встречается в каждой строке наравне с настоящим разделителем.
+var delimiterCandidates = []rune{'\t', ';', '|', ','}
+
+const (
+	detectBytes      = 64 << 10 // сколько байт из начала файла используется для определения кодировки и разделителя
+	detectLines      = 50       // сколько первых непустых строк используется для определения разделителя
+	detectSampleRows = 1000     // сколько строк просматривается для определения десятичного разделителя
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+var (
+	decimalCommaRe = regexp.MustCompile(`^[-+]?\d*,\d+([eE][-+]?\d+)?$`)
+	decimalDotRe   = regexp.MustCompile(`^[-+]?\d*\.\d+([eE][-+]?\d+)?$`)
+	groupedRe      = regexp.MustCompile(`^[-+]?\d{1,3}([ \x{00A0}]\d{3})+([.,]\d+)?$`)
+)
+
+// textFormat — параметры текстового файла, определённые автоматически.
+type textFormat struct {
+	Encoding     string
+	Delimiter    rune
+	DecimalComma bool
+}
+
+// String описывает формат для отчёта импорта.
+func (f textFormat) String() string {
+	delim := string(f.Delimiter)
+	if f.Delimiter == '\t' {
+		delim = "табуляция"
+	}
+	decimal := "точка"
+	if f.DecimalComma {
+		decimal = "запятая"
+	}
+	return fmt.Sprintf("текст: кодировка %s, разделитель «%s», десятичный разделитель — %s", f.Encoding, delim, decimal)
+}
+
+// delimitedReader читает CSV или текст с разделителями потоком и отдаёт строки в том же виде, что и xlsxReader.
+// Кодировка (UTF-8 или CP1251), разделитель колонок и десятичный разделитель определяются по началу файла.
+type delimitedReader struct {
+	file    *os.File
+	size    int64
+	read    *countingReader
+	csv     *csv.Reader
+	format  textFormat
+	pending []sheetRow // строки, прочитанные при определении десятичного разделителя
+}
+
+func openDelimited(path string) (*delimitedReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open file %s", path)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Wrapf(err, "stat file %s", path)
+	}
+
+	head := make([]byte, detectBytes)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		_ = f.Close()
+		return nil, errors.Wrapf(err, "read file %s", path)
+	}
+	head = head[:n]
+	// последняя строка образца может быть обрезана посередине символа
+	if n == detectBytes {
+		if i := bytes.LastIndexByte(head, '\n'); i > 0 {
+			head = head[:i+1]
+		}
+	}
+
+	r := &delimitedReader{
+		file: f,
+		size: st.Size(),
+		read: &countingReader{r: io.MultiReader(bytes.NewReader(head), io.NewSectionReader(f, int64(len(head)), st.Size()))},
+	}
+
+	var text io.Reader = r.read
+	sample := string(head)
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		r.format.Encoding = "UTF-8 (BOM)"
+		sample = string(head[len(utf8BOM):])
+		if _, err := io.CopyN(io.Discard, r.read, int64(len(utf8BOM))); err != nil {
+			_ = f.Close()
+			return nil, errors.Wrap(err, "skip BOM")
+		}
+	case utf8.Valid(head):
+		r.format.Encoding = "UTF-8"
+	default:
+		// невалидный UTF-8 считается CP1251 — так сохраняют CSV Excel и большинство SCADA под русской Windows
+		r.format.Encoding = "CP1251"
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(head)
+		if err != nil {
+			_ = f.Close()
+			return nil, errors.Wrap(err, "decode CP1251")
+		}
+		sample = string(decoded)
+		text = charmap.Windows1251.NewDecoder().Reader(r.read)
+	}
+	r.format.Delimiter = detectDelimiter(sample)
+
+	r.csv = csv.NewReader(text)
+	r.csv.Comma = r.format.Delimiter
+	r.csv.FieldsPerRecord = -1
+	r.csv.LazyQuotes = true
+
+	for len(r.pending) < detectSampleRows {
+		row, err := r.readRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return nil, errors.Wrapf(err, "parse delimited text %s", path)
+		}
+		r.pending = append(r.pending, row)
+	}
+	r.format.DecimalComma = detectDecimalComma(r.pending, r.format.Delimiter)
+	for _, row := range r.pending {
+		r.normalize(row)
+	}
+	return r, nil
+}
+
+// readRow читает следующую непустую строку без приведения чисел.
+func (r *delimitedReader) readRow() (sheetRow, error) {
+	for {
+		rec, err := r.csv.Read()
+		if err != nil {
+			return sheetRow{}, err
+		}
+		line, _ := r.csv.FieldPos(0)
+		row := sheetRow{Index: line, Values: rec}
+		if row.nonEmpty() > 0 {
+			return row, nil
+		}
+	}
+}
+
+func (r *delimitedReader) normalize(row sheetRow) {
+	for i, v := range row.Values {
+		row.Values[i] = normalizeNumber(strings.TrimSpace(v), r.format.DecimalComma)
+	}
+}
+
+func (r *delimitedReader) Next() (sheetRow, error) {
+	if len(r.pending) > 0 {
+		row := r.pending[0]
+		r.pending = r.pending[1:]
+		return row, nil
+	}
+	row, err := r.readRow()
+	if err == io.EOF {
+		return row, io.EOF
+	}
+	if err != nil {
+		return row, errors.Wrapf(err, "parse delimited text %s", r.file.Name())
+	}
+	r.normalize(row)
+	return row, nil
+}
+
+func (r *delimitedReader) Progress() float64 {
+	if r.size <= 0 {
+		return -1
+	}
+	return min(1, float64(r.read.n)/float64(r.size))
+}
+
+func (r *delimitedReader) Format() string {
+	return r.format.String()
+}
+
+func (r *delimitedReader) Close() error {
+	return r.file.Close()
+}
+
+// countingReader считает прочитанные байты для оценки прогресса.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// detectDelimiter выбирает разделитель, который встречается одинаковое ненулевое число раз
+// в большинстве первых строк. Символы внутри кавычек не учитываются.
+func detectDelimiter(text string) rune {
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.TrimRight(l, "\r"); strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+		if len(lines) >= detectLines {
+			break
+		}
+	}
+	if len(lines) == 0 {
+		return ','
+	}
+
+	best, bestScore := ',', 0.0
+	for _, d := range delimiterCandidates {
+		freq := make(map[int]int)
+		for _, l := range lines {
+			freq[countOutsideQuotes(l, d)]++
+		}
+		modeCount := 0
+		for n, c := range freq {
+			if n > 0 && c > modeCount {
+				modeCount = c
+			}
+		}
+		score := float64(modeCount) / float64(len(lines))
+		// первый по предпочтению разделитель с устойчивым числом вхождений побеждает сразу
+		if score >= 0.8 {
+			return d
+		}
+		if score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best
+}
+
+// countOutsideQuotes считает символ d вне кавычек.
+func countOutsideQuotes(line string, d rune) int {
+	n, quoted := 0, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == d && !quoted:
+			n++
+		}
+	}
+	return n
+}
+
+// detectDecimalComma определяет, что дробная часть чисел отделяется запятой.
+// При разделителе-запятой десятичная запятая невозможна.
+func detectDecimalComma(rows []sheetRow, delim rune) bool {
+	if delim == ',' {
+		return false
+	}
+	if len(rows) > detectSampleRows {
+		rows = rows[:detectSampleRows]
+	}
+	commas, dots := 0, 0
+	for _, row := range rows {
+		for _, v := range row.Values {
+			v = strings.TrimSpace(v)
+			switch {
+			case decimalCommaRe.MatchString(v):
+				commas++
+			case decimalDotRe.MatchString(v):
+				dots++
+			}
+		}
+	}
+	return commas > dots
+}
+
+// normalizeNumber приводит число к виду, понятному strconv.ParseFloat:
+// убирает пробелы-разделители разрядов и заменяет десятичную запятую точкой.
+// Значения, не похожие на число (даты, текст), возвращаются как есть.
+func normalizeNumber(v string, decimalComma bool) string {
+	if groupedRe.MatchString(v) {
+		v = strings.NewReplacer(" ", "", "\u00a0", "").Replace(v)
+	}
+	if decimalComma && decimalCommaRe.MatchString(v) {
+		return strings.Replace(v, ",", ".", 1)
+	}
+	return v
+}
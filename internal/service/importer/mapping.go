@@ -0,0 +1,348 @@
+package importer
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const (
+	defaultHeaderScanRows = 15
+	defaultMinMatchScore  = 0.6
+)
+
+// defaultAliases — встроенные варианты заголовков, которые встречаются в выгрузках подрядчиков.
+// Ключ — имя поля модели. Для Блока 2 групповые заголовки («Трубное давление») намеренно
+// не заданы: они стоят и над колонкой времени, и над колонкой давления.
+var defaultAliases = map[string][]string{
+	"Timestamp":               {"Дата, время", "Дата и время", "Время", "Date time", "Timestamp"},
+	"PressureDepth":           {"Давление на глубине замера", "Pзаб"},
+	"TemperatureDepth":        {"Температура на глубине замера", "Tзаб"},
+	"PressureTubing":          {"Ртр"},
+	"PressureAnnulus":         {"Рзтр"},
+	"PressureLinear":          {"Рлин"},
+	"LiquidFlowRate":          {"Дебит жидкости", "Qж"},
+	"WaterCut":                {"Обводненность", "W"},
+	"GasFlowRate":             {"Дебит газа", "Qг"},
+	"MeasuredDepth":           {"Глубина по стволу", "MD"},
+	"TrueVerticalDepth":       {"Вертикальная глубина", "TVD"},
+	"TrueVerticalDepthSubSea": {"Абсолютная глубина", "Абсолютная отметка", "TVDSS"},
+	"Inclination":             {"Зенитный угол", "Зенит", "Inclination", "Inc"},
+	"Azimuth":                 {"Азимут", "Azimuth", "Azi"},
+}
+
+// stopWords не несут смысла при сравнении заголовков.
+var stopWords = map[string]struct{}{"по": {}, "на": {}, "в": {}, "и": {}, "с": {}}
+
+// lookalikes приводит латинские буквы, похожие на кириллические, к кириллице:
+// в шаблонах встречаются «Tзаб» с латинской T и «°C» с латинской C.
+var lookalikes = strings.NewReplacer(
+	"a", "а", "c", "с", "e", "е", "o", "о", "p", "р", "x", "х",
+	"t", "т", "k", "к", "m", "м", "h", "н", "b", "в", "y", "у",
+	"ё", "е", "°", "о",
+)
+
+// fieldSpec — поле модели, которое ищется в шапке файла.
+type fieldSpec struct {
+	Name    string
+	Tag     string
+	aliases [][]string // токены тега и всех псевдонимов
+}
+
+// sheetRow — строка листа в плотном виде: значение по индексу колонки.
+type sheetRow struct {
+	Index  int // номер строки в файле, с единицы
+	Values []string
+}
+
+// cell возвращает значение колонки idx или пустую строку.
+func (r sheetRow) cell(idx int) string {
+	if idx < 0 || idx >= len(r.Values) {
+		return ""
+	}
+	return strings.TrimSpace(r.Values[idx])
+}
+
+// nonEmpty возвращает число непустых ячеек строки.
+func (r sheetRow) nonEmpty() int {
+	n := 0
+	for _, v := range r.Values {
+		if strings.TrimSpace(v) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// blank сообщает, что все сопоставленные колонки строки пусты.
+func (r sheetRow) blank(m models.ColumnMapping) bool {
+	for _, c := range m.Columns {
+		if r.cell(c.Index) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldsOf собирает поля модели, у которых задан xlsx-тег.
+func (s *Service) fieldsOf(model any) []fieldSpec {
+	t := reflect.TypeOf(model)
+	var out []fieldSpec
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("xlsx")
+		if tag == "" {
+			continue
+		}
+		spec := fieldSpec{Name: f.Name, Tag: tag}
+		names := append([]string{tag}, defaultAliases[f.Name]...)
+		names = append(names, s.conf.Aliases[f.Name]...)
+		for _, n := range names {
+			if toks := tokenize(n); len(toks) > 0 {
+				spec.aliases = append(spec.aliases, toks)
+			}
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
+// detectColumns находит шапку среди первых строк файла и сопоставляет колонки полям модели.
+// Данные начинаются с первой строки, большинство ячеек которой — числа или даты.
+func (s *Service) detectColumns(head []sheetRow, fields []fieldSpec) (models.ColumnMapping, error) {
+	dataIdx := -1
+	for i, row := range head {
+		if s.looksLikeData(row) {
+			dataIdx = i
+			break
+		}
+	}
+	if dataIdx < 0 {
+		return models.ColumnMapping{}, errors.Errorf("не найдены строки с данными в первых %d строках", len(head))
+	}
+	if dataIdx == 0 {
+		return models.ColumnMapping{}, errors.New("не найдена строка заголовков перед данными")
+	}
+
+	headerRows := head[:dataIdx]
+	headers := compositeHeaders(headerRows)
+
+	type candidate struct {
+		field, col int
+		score      float64
+	}
+	var cands []candidate
+	for fi, f := range fields {
+		for ci, h := range headers {
+			if len(h) == 0 {
+				continue
+			}
+			if sc := matchScore(f.aliases, h); sc >= s.conf.MinMatchScore {
+				cands = append(cands, candidate{field: fi, col: ci, score: sc})
+			}
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].score > cands[j].score })
+
+	byField := make(map[int]candidate, len(fields))
+	usedCols := make(map[int]bool)
+	for _, c := range cands {
+		if _, ok := byField[c.field]; ok || usedCols[c.col] {
+			continue
+		}
+		byField[c.field] = c
+		usedCols[c.col] = true
+	}
+
+	mapping := models.ColumnMapping{DataStartRow: head[dataIdx].Index}
+	for _, r := range headerRows {
+		mapping.HeaderRows = append(mapping.HeaderRows, r.Index)
+	}
+	var missing []string
+	for fi, f := range fields {
+		c, ok := byField[fi]
+		if !ok {
+			missing = append(missing, f.Tag)
+			continue
+		}
+		mapping.Columns = append(mapping.Columns, models.MappedColumn{
+			Field:  f.Name,
+			Tag:    f.Tag,
+			Header: headerText(headerRows, c.col),
+			Column: columnName(c.col),
+			Index:  c.col,
+			Score:  c.score,
+		})
+	}
+	for ci := range headers {
+		if !usedCols[ci] {
+			if h := headerText(headerRows, ci); h != "" {
+				mapping.Ignored = append(mapping.Ignored, columnName(ci)+": "+h)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return mapping, errors.Errorf("не найдены колонки: %s", strings.Join(missing, "; "))
+	}
+	return mapping, nil
+}
+
+// looksLikeData проверяет, что строка похожа на строку данных, а не на шапку или единицы измерения.
+func (s *Service) looksLikeData(row sheetRow) bool {
+	filled, parsed := 0, 0
+	for _, v := range row.Values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		filled++
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			parsed++
+		} else if _, err := s.converter.ParseFlexibleTime(v); err == nil {
+			parsed++
+		}
+	}
+	return filled >= 2 && float64(parsed) >= 0.6*float64(filled)
+}
+
+// compositeHeaders склеивает токены заголовков каждой колонки по всем строкам шапки.
+// Строки-группы («Трубное давление» над парой колонок) заполнены меньше чем наполовину,
+// их значения распространяются вправо до следующей непустой ячейки — так объединённые
+// ячейки Excel попадают во все колонки под ними.
+func compositeHeaders(rows []sheetRow) [][]string {
+	width := 0
+	for _, r := range rows {
+		if len(r.Values) > width {
+			width = len(r.Values)
+		}
+	}
+	out := make([][]string, width)
+	for ri, r := range rows {
+		group := ri < len(rows)-1 && r.nonEmpty()*2 <= width
+		carry := ""
+		for ci := 0; ci < width; ci++ {
+			v := r.cell(ci)
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				v = "" // случайные числа в шапке не являются заголовками
+			}
+			if v != "" {
+				carry = v
+			} else if group {
+				v = carry
+			}
+			out[ci] = append(out[ci], tokenize(v)...)
+		}
+	}
+	return out
+}
+
+// headerText возвращает заголовок колонки в исходном виде для отчёта пользователю.
+func headerText(rows []sheetRow, col int) string {
+	var parts []string
+	for _, r := range rows {
+		if v := r.cell(col); v != "" {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				parts = append(parts, v)
+			}
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// tokenize нормализует заголовок и разбивает его на слова.
+func tokenize(s string) []string {
+	s = lookalikes.Replace(strings.ToLower(s))
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if _, ok := stopWords[w]; !ok {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// matchScore оценивает совпадение заголовка с лучшим из вариантов поля.
+// Основной вес у полноты (все ли слова варианта нашлись), точность различает близкие колонки.
+func matchScore(aliases [][]string, header []string) float64 {
+	best := 0.0
+	for _, alias := range aliases {
+		hit := 0
+		for _, a := range alias {
+			for _, h := range header {
+				if tokensMatch(a, h) {
+					hit++
+					break
+				}
+			}
+		}
+		recall := float64(hit) / float64(len(alias))
+		precision := float64(hit) / float64(len(header))
+		if precision > 1 {
+			precision = 1
+		}
+		if sc := 0.8*recall + 0.2*precision; sc > best {
+			best = sc
+		}
+	}
+	return best
+}
+
+// tokensMatch сравнивает слова с учётом окончаний и единичных опечаток.
+func tokensMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	short := min(len(ra), len(rb))
+	if short < 4 {
+		return false
+	}
+	prefix := 0
+	for prefix < short && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	if prefix >= 4 && float64(prefix) >= 0.6*float64(short) {
+		return true
+	}
+	return short >= 5 && levenshtein(ra, rb) <= 1
+}
+
+// levenshtein — расстояние редактирования между двумя словами.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// columnName переводит индекс колонки в буквенное обозначение Excel.
+func columnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/thedatashed/xlsxreader"
+)
+
+// rowReader отдаёт строки файла по одной, не загружая файл в память целиком.
+type rowReader interface {
+	// Next возвращает следующую строку файла или io.EOF, когда строки закончились.
+	Next() (sheetRow, error)
+	// Progress возвращает прочитанную долю файла от 0 до 1 или -1, если её не оценить.
+	Progress() float64
+	// Format описывает формат файла для отчёта импорта.
+	Format() string
+	Close() error
+}
+
+// delimitedExtensions — расширения файлов, которые читаются как текст с разделителями.
+var delimitedExtensions = map[string]bool{".csv": true, ".txt": true, ".tsv": true, ".dat": true, ".asc": true}
+
+// SupportedExtensions возвращает расширения файлов, которые умеет читать импорт.
+func SupportedExtensions() []string {
+	exts := []string{".xlsx"}
+	for ext := range delimitedExtensions {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// openRows выбирает читатель по расширению файла.
+func openRows(path string) (rowReader, error) {
+	if delimitedExtensions[strings.ToLower(filepath.Ext(path))] {
+		return openDelimited(path)
+	}
+	return openXLSX(path)
+}
+
+// dimensionRe извлекает номер последней строки из диапазона листа <dimension ref="A1:D42445"/>.
+var dimensionRe = regexp.MustCompile(`<dimension ref="[A-Z]+\d+:[A-Z]+(\d+)"`)
+
+// xlsxReader читает первый лист XLSX‑файла потоком и раскладывает ячейки в плотный вид:
+// xlsxreader пропускает пустые ячейки, поэтому значения расставляются по индексу колонки.
+type xlsxReader struct {
+	file    *xlsxreader.XlsxFileCloser
+	rows    chan xlsxreader.Row
+	lastRow int // последняя строка листа по <dimension>, 0 — неизвестна
+	current int
+}
+
+func openXLSX(path string) (*xlsxReader, error) {
+	xl, err := xlsxreader.OpenFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open xlsx %s", path)
+	}
+	if len(xl.Sheets) == 0 {
+		_ = xl.Close()
+		return nil, errors.Newf("в файле %s нет листов", path)
+	}
+	sheet := xl.Sheets[0]
+	return &xlsxReader{
+		file:    xl,
+		rows:    xl.ReadRows(sheet),
+		lastRow: sheetLastRow(xl.GetSheetFileForSheetName(sheet)),
+	}, nil
+}
+
+// sheetLastRow читает начало XML листа и возвращает последнюю строку из <dimension>.
+// Excel пишет этот элемент в самом начале листа, так что распаковывать лист целиком не нужно.
+func sheetLastRow(f *zip.File) int {
+	if f == nil {
+		return 0
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return 0
+	}
+	defer rc.Close()
+
+	buf := make([]byte, 4096)
+	n, _ := io.ReadFull(rc, buf)
+	m := dimensionRe.FindSubmatch(buf[:n])
+	if m == nil {
+		return 0
+	}
+	last, _ := strconv.Atoi(string(m[1]))
+	return last
+}
+
+func (r *xlsxReader) Next() (sheetRow, error) {
+	row, ok := <-r.rows
+	if !ok {
+		return sheetRow{}, io.EOF
+	}
+	if row.Error != nil {
+		return sheetRow{}, errors.Wrapf(row.Error, "read row %d", row.Index)
+	}
+	r.current = row.Index
+
+	out := sheetRow{Index: row.Index}
+	for _, c := range row.Cells {
+		idx := c.ColumnIndex()
+		for len(out.Values) <= idx {
+			out.Values = append(out.Values, "")
+		}
+		out.Values[idx] = c.Value
+	}
+	return out, nil
+}
+
+func (r *xlsxReader) Progress() float64 {
+	if r.lastRow <= 0 {
+		return -1
+	}
+	return min(1, float64(r.current)/float64(r.lastRow))
+}
+
+func (r *xlsxReader) Format() string {
+	return "XLSX"
+}
+
+// Close останавливает горутину чтения листа и закрывает файл.
+func (r *xlsxReader) Close() error {
+	return r.file.Close()
+}
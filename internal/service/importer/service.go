@@ -1,176 +1,125 @@
 package importer
 
 import (
-	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/cockroachdb/errors"
-	"github.com/thedatashed/xlsxreader"
 
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/config"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
 )
 
 type converterService interface {
 	ParseFlexibleTime(raw string) (time.Time, error)
+	ParseFlexibleTimeIn(raw string, loc *time.Location) (time.Time, error)
 }
 
-// Service отвечает за логику импорта данных из Excel.
+// Service отвечает за логику импорта данных из Excel и текстовых файлов с разделителями.
 type Service struct {
 	converter converterService
+	conf      config.ImporterConf
 }
 
 // NewService создает новый экземпляр сервис импорта.
-func NewService(converter converterService) *Service {
+func NewService(converter converterService, conf config.ImporterConf) *Service {
+	if conf.HeaderScanRows <= 0 {
+		conf.HeaderScanRows = defaultHeaderScanRows
+	}
+	if conf.MinMatchScore <= 0 {
+		conf.MinMatchScore = defaultMinMatchScore
+	}
+	if conf.ChunkSize <= 0 {
+		conf.ChunkSize = defaultChunkSize
+	}
 	return &Service{
 		converter: converter,
+		conf:      conf,
 	}
 }
 
-// ParseBlockOneFile читает XLSX‑файл через xlsxreader и возвращает []TableOne.
-func (s *Service) ParseBlockOneFile(path string, cfg models.OperationConfig) ([]models.TableOne, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, errors.Wrapf(err, "read file %s", path)
-	}
-	xl, err := xlsxreader.NewReader(data)
-	if err != nil {
-		return nil, errors.Wrap(err, "xlsxreader.NewReader")
-	}
-
-	var out []models.TableOne
-	for row := range xl.ReadRows(xl.Sheets[0]) {
-		if row.Index == 1 {
-			continue
-		}
-		cells := row.Cells
-		if len(cells) < 3 {
-			continue
-		}
-		ts, err := s.converter.ParseFlexibleTime(cells[0].Value)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse timestamp block1 row %d", row.Index)
-		}
-		pres, err := strconv.ParseFloat(cells[1].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse pressure block1 row %d", row.Index)
-		}
-		temp, err := strconv.ParseFloat(cells[2].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse temperature block1 row %d", row.Index)
-		}
-
-		rec := models.TableOne{
+// StreamBlockOneFile читает XLSX или текстовый файл построчно и отдаёт []TableOne порциями в sink.
+// В строгом режиме первая ошибочная строка прерывает импорт, в мягком — попадает в отчёт и пропускается.
+// Записи содержат только измеренные значения: Рзаб на ВДП считается при чтении по текущим параметрам гидростатики.
+func (s *Service) StreamBlockOneFile(ctx context.Context, path string,
+	opts models.ImportOptions, sink func([]models.TableOne) error) (models.ImportReport, error) {
+	return streamFile(ctx, s, path, "block1", models.TableOne{}, opts, func(p *rowParser, row sheetRow) (models.TableOne, bool) {
+		ts, okTs := p.time(row, "Timestamp")
+		pres, okPres := p.float(row, "PressureDepth")
+		temp, okTemp := p.float(row, "TemperatureDepth")
+		if !(okTs && okPres && okTemp && p.uniqueTime(row, "Timestamp", ts)) {
+			return models.TableOne{}, false
+		}
+		p.rememberTime(row, "Timestamp", ts)
+
+		return models.TableOne{
 			Timestamp:        ts,
 			PressureDepth:    pres,
 			TemperatureDepth: temp,
-		}
-
-		// 4) автоматический расчёт ВДП
-		rec = calc.TableOne(rec, cfg)
-		out = append(out, rec)
-	}
-	return out, nil
+		}, true
+	}, sink)
 }
 
-// ParseBlockTwoFile читает XLSX‑файл и возвращает []TableTwo.
-func (s *Service) ParseBlockTwoFile(path string) ([]models.TableTwo, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, errors.Wrapf(err, "read file %s", path)
-	}
-	xl, err := xlsxreader.NewReader(data)
-	if err != nil {
-		return nil, errors.Wrap(err, "xlsxreader.NewReader")
-	}
-
-	var out []models.TableTwo
-	for row := range xl.ReadRows(xl.Sheets[0]) {
-		if row.Index <= 2 {
-			continue
-		}
-		cells := row.Cells
-		if len(cells) < 6 {
-			continue
-		}
+// StreamBlockTwoFile читает XLSX или текстовый файл построчно и отдаёт []TableTwo порциями в sink.
+func (s *Service) StreamBlockTwoFile(ctx context.Context, path string,
+	opts models.ImportOptions, sink func([]models.TableTwo) error) (models.ImportReport, error) {
+	return streamFile(ctx, s, path, "block2", models.TableTwo{}, opts, func(p *rowParser, row sheetRow) (models.TableTwo, bool) {
 		// Tubing pressure
-		tsTub, err := s.converter.ParseFlexibleTime(cells[0].Value)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse tubing timestamp row %d", row.Index)
-		}
-		presTub, err := strconv.ParseFloat(cells[1].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse tubing pressure row %d", row.Index)
-		}
+		tsTub, okTsTub := p.time(row, "TimestampTubing")
+		presTub, okPresTub := p.float(row, "PressureTubing")
 		// Annulus pressure
-		tsAnn, err := s.converter.ParseFlexibleTime(cells[2].Value)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse annulus timestamp row %d", row.Index)
-		}
-		presAnn, err := strconv.ParseFloat(cells[3].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse annulus pressure row %d", row.Index)
-		}
+		tsAnn, okTsAnn := p.time(row, "TimestampAnnulus")
+		presAnn, okPresAnn := p.float(row, "PressureAnnulus")
 		// Linear pressure
-		tsLin, err := s.converter.ParseFlexibleTime(cells[4].Value)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse linear timestamp row %d", row.Index)
-		}
-		presLin, err := strconv.ParseFloat(cells[5].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse linear pressure row %d", row.Index)
+		tsLin, okTsLin := p.time(row, "TimestampLinear")
+		presLin, okPresLin := p.float(row, "PressureLinear")
+
+		ok := okTsTub && okPresTub && okTsAnn && okPresAnn && okTsLin && okPresLin &&
+			p.uniqueTime(row, "TimestampTubing", tsTub) &&
+			p.uniqueTime(row, "TimestampAnnulus", tsAnn) &&
+			p.uniqueTime(row, "TimestampLinear", tsLin)
+		if !ok {
+			return models.TableTwo{}, false
 		}
+		p.rememberTime(row, "TimestampTubing", tsTub)
+		p.rememberTime(row, "TimestampAnnulus", tsAnn)
+		p.rememberTime(row, "TimestampLinear", tsLin)
 
-		out = append(out, models.TableTwo{
+		return models.TableTwo{
 			TimestampTubing:  tsTub,
 			PressureTubing:   presTub,
 			TimestampAnnulus: tsAnn,
 			PressureAnnulus:  presAnn,
 			TimestampLinear:  tsLin,
 			PressureLinear:   presLin,
-		})
-	}
-	return out, nil
+		}, true
+	}, sink)
 }
 
-// ParseBlockThreeFile читает XLSX‑файл и возвращает []TableThree.
-func (s *Service) ParseBlockThreeFile(path string) ([]models.TableThree, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, errors.Wrapf(err, "read file %s", path)
-	}
-	xl, err := xlsxreader.NewReader(data)
-	if err != nil {
-		return nil, errors.Wrap(err, "xlsxreader.NewReader")
-	}
-
-	var out []models.TableThree
-	for row := range xl.ReadRows(xl.Sheets[0]) {
-		// пропускаем заголовок
-		if row.Index == 1 {
-			continue
-		}
-		cells := row.Cells
-		if len(cells) < 4 {
-			continue
-		}
-		ts, err := s.converter.ParseFlexibleTime(cells[0].Value)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse timestamp block3 row %d", row.Index)
-		}
-		flowL, err := strconv.ParseFloat(cells[1].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse flow liquid row %d", row.Index)
-		}
-		wc, err := strconv.ParseFloat(cells[2].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse water cut row %d", row.Index)
-		}
-		flowG, err := strconv.ParseFloat(cells[3].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse flow gas row %d", row.Index)
-		}
+// StreamBlockThreeFile читает XLSX или текстовый файл построчно и отдаёт []TableThree порциями в sink.
+func (s *Service) StreamBlockThreeFile(ctx context.Context, path string,
+	opts models.ImportOptions, sink func([]models.TableThree) error) (models.ImportReport, error) {
+	return streamFile(ctx, s, path, "block3", models.TableThree{}, opts, func(p *rowParser, row sheetRow) (models.TableThree, bool) {
+		ts, okTs := p.time(row, "Timestamp")
+		flowL, okFlowL := p.float(row, "LiquidFlowRate")
+		wc, okWc := p.float(row, "WaterCut")
+		flowG, okFlowG := p.float(row, "GasFlowRate")
+
+		ok := okTs && okFlowL && okWc && okFlowG &&
+			p.inRange(row, "LiquidFlowRate", flowL, 0, math.MaxFloat64) &&
+			p.inRange(row, "WaterCut", wc, 0, 100) &&
+			p.inRange(row, "GasFlowRate", flowG, 0, math.MaxFloat64) &&
+			p.uniqueTime(row, "Timestamp", ts)
+		if !ok {
+			return models.TableThree{}, false
+		}
+		p.rememberTime(row, "Timestamp", ts)
 
 		res := models.TableThree{
 			Timestamp:      ts,
@@ -179,68 +128,115 @@ func (s *Service) ParseBlockThreeFile(path string) ([]models.TableThree, error)
 			GasFlowRate:    flowG,
 		}
 
-		res = calc.TableThree(res)
+		return calc.TableThree(res), true
+	}, sink)
+}
+
+// StreamBlockFourFile читает XLSX или текстовый файл построчно и отдаёт []TableFour порциями в sink.
+// Шапка инклинометрии занимает несколько строк (группы, названия, единицы измерения).
+func (s *Service) StreamBlockFourFile(ctx context.Context, path string,
+	opts models.ImportOptions, sink func([]models.TableFour) error) (models.ImportReport, error) {
+	var prevMD *float64
+	return streamFile(ctx, s, path, "block4", models.TableFour{}, opts, func(p *rowParser, row sheetRow) (models.TableFour, bool) {
+		md, okMD := p.float(row, "MeasuredDepth")
+		tvd, okTVD := p.float(row, "TrueVerticalDepth")
+		tvdss, okTVDSS := p.float(row, "TrueVerticalDepthSubSea")
+
+		if !(okMD && okTVD && okTVDSS && p.increasing(row, "MeasuredDepth", md, prevMD)) {
+			return models.TableFour{}, false
+		}
+		prevMD = &md
+
+		return models.TableFour{
+			MeasuredDepth:           md,
+			TrueVerticalDepth:       tvd,
+			TrueVerticalDepthSubSea: tvdss,
+		}, true
+	}, sink)
+}
+
+// StreamSurveyFile читает замеры инклинометрии MD, зенитный угол, азимут построчно и отдаёт их порциями в sink.
+// Траекторию по ним считает calc.Trajectory, когда прочитан весь файл.
+func (s *Service) StreamSurveyFile(ctx context.Context, path string,
+	opts models.ImportOptions, sink func([]models.SurveyStation) error) (models.ImportReport, error) {
+	var prevMD *float64
+	return streamFile(ctx, s, path, "block4", models.SurveyStation{}, opts, func(p *rowParser, row sheetRow) (models.SurveyStation, bool) {
+		md, okMD := p.float(row, "MeasuredDepth")
+		inc, okInc := p.float(row, "Inclination")
+		az, okAz := p.float(row, "Azimuth")
+
+		ok := okMD && okInc && okAz &&
+			p.inRange(row, "Inclination", inc, 0, 180) &&
+			p.inRange(row, "Azimuth", az, 0, 360) &&
+			p.increasing(row, "MeasuredDepth", md, prevMD)
+		if !ok {
+			return models.SurveyStation{}, false
+		}
+		prevMD = &md
+
+		return models.SurveyStation{MeasuredDepth: md, Inclination: inc, Azimuth: az}, true
+	}, sink)
+}
 
-		out = append(out, res)
+// ParseBlockOneFile читает файл целиком в []TableOne. Для больших файлов предпочтителен StreamBlockOneFile.
+func (s *Service) ParseBlockOneFile(path string, mode models.ImportMode) ([]models.TableOne, models.ImportReport, error) {
+	var out []models.TableOne
+	report, err := s.StreamBlockOneFile(context.Background(), path, models.ImportOptions{Mode: mode}, collect(&out))
+	if err != nil {
+		return nil, report, err
 	}
-	return out, nil
+	return out, report, nil
 }
 
-// ParseBlockFourFile читает XLSX‑файл и возвращает []Inclinometry.
-func (s *Service) ParseBlockFourFile(path string) ([]models.TableFour, error) {
-	// 1) читаем файл
-	data, err := os.ReadFile(path)
+// ParseBlockTwoFile читает файл целиком в []TableTwo.
+func (s *Service) ParseBlockTwoFile(path string, mode models.ImportMode) ([]models.TableTwo, models.ImportReport, error) {
+	var out []models.TableTwo
+	report, err := s.StreamBlockTwoFile(context.Background(), path, models.ImportOptions{Mode: mode}, collect(&out))
 	if err != nil {
-		return nil, errors.Wrapf(err, "read file %s", path)
+		return nil, report, err
 	}
+	return out, report, nil
+}
 
-	// 2) создаём ридер xlsxreader
-	xl, err := xlsxreader.NewReader(data)
+// ParseBlockThreeFile читает файл целиком в []TableThree.
+func (s *Service) ParseBlockThreeFile(path string, mode models.ImportMode) ([]models.TableThree, models.ImportReport, error) {
+	var out []models.TableThree
+	report, err := s.StreamBlockThreeFile(context.Background(), path, models.ImportOptions{Mode: mode}, collect(&out))
 	if err != nil {
-		return nil, errors.Wrap(err, "xlsxreader.NewReader")
+		return nil, report, err
 	}
+	return out, report, nil
+}
 
+// ParseBlockFourFile читает файл целиком в []TableFour.
+func (s *Service) ParseBlockFourFile(path string, mode models.ImportMode) ([]models.TableFour, models.ImportReport, error) {
 	var out []models.TableFour
+	report, err := s.StreamBlockFourFile(context.Background(), path, models.ImportOptions{Mode: mode}, collect(&out))
+	if err != nil {
+		return nil, report, err
+	}
+	return out, report, nil
+}
 
-	// 3) перебираем все строки на первом листе
-	for row := range xl.ReadRows(xl.Sheets[0]) {
-		// пропускаем заголовки и строку с единицами (Excel: строки 1–4)
-		if row.Index <= 4 {
-			continue
-		}
-
-		cells := row.Cells
-		if len(cells) < 3 {
-			continue
-		}
-
-		// 4) парсим MeasuredDepth (колонка A / cells[0])
-		md, err := strconv.ParseFloat(cells[0].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse MeasuredDepth block4 row %d", row.Index)
-		}
-
-		// 5) парсим TrueVerticalDepth (колонка I / cells[1])
-		tvd, err := strconv.ParseFloat(cells[1].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse TrueVerticalDepth block4 row %d", row.Index)
-		}
-
-		// 6) парсим TrueVerticalDepthSubSea (колонка J / cells[2])
-		tvdss, err := strconv.ParseFloat(cells[2].Value, 64)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parse TrueVerticalDepthSubSea block4 row %d", row.Index)
-		}
-
-		// 7) собираем запись и добавляем в выходной срез
-		rec := models.TableFour{
-			MeasuredDepth:           md,
-			TrueVerticalDepth:       tvd,
-			TrueVerticalDepthSubSea: tvdss,
-		}
-
-		out = append(out, rec)
+// collect возвращает sink, который копит порции в срез.
+func collect[T any](out *[]T) func([]T) error {
+	return func(chunk []T) error {
+		*out = append(*out, chunk...)
+		return nil
 	}
+}
 
-	return out, nil
+// FileHash возвращает SHA-256 содержимого файла в шестнадцатеричном виде: по нему узнаётся
+// повторный импорт того же файла, даже переименованного.
+func (s *Service) FileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "не удалось открыть файл")
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err, "не удалось прочитать файл")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const (
+	defaultChunkSize = 10000
+	progressInterval = 200 * time.Millisecond // как часто сообщать о прогрессе
+)
+
+// rowParseFunc разбирает строку данных в запись блока. false — строка отброшена, проблема уже в отчёте.
+type rowParseFunc[T any] func(p *rowParser, row sheetRow) (T, bool)
+
+// streamFile читает файл построчно, разбирает строки через parse и отдаёт принятые записи в sink
+// порциями по ChunkSize. Срез, переданный в sink, переиспользуется — sink должен скопировать данные.
+// Отмена ctx прерывает разбор; порции, уже отданные в sink, остаются на совести вызывающего.
+func streamFile[T any](ctx context.Context, s *Service, path, block string, model any,
+	opts models.ImportOptions, parse rowParseFunc[T], sink func([]T) error) (models.ImportReport, error) {
+	report := models.ImportReport{File: path, Mode: opts.Mode}
+	src, err := openRows(path)
+	if err != nil {
+		return report, err
+	}
+	defer src.Close()
+	report.Format = src.Format()
+
+	var pending []sheetRow
+	report.Mapping, pending, err = s.readHeader(src, model)
+	if err != nil {
+		return report, errors.Wrapf(err, "%s header", block)
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = s.conf.ChunkSize
+	}
+	chunk := make([]T, 0, chunkSize)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := sink(chunk); err != nil {
+			return errors.Wrapf(err, "%s store chunk", block)
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	start := time.Now()
+	lastProgress := start
+	progress := func() {
+		if opts.OnProgress == nil {
+			return
+		}
+		opts.OnProgress(models.ImportProgress{
+			Rows:     report.TotalRows,
+			Accepted: report.AcceptedRows,
+			Fraction: src.Progress(),
+			Elapsed:  time.Since(start),
+		})
+	}
+
+	p := s.newRowParser(&report, block)
+	if err := p.withTime(opts.Time); err != nil {
+		return report, errors.Wrapf(err, "%s time correction", block)
+	}
+	if opts.Time != nil && !opts.Time.IsZero() {
+		report.TimeCorrection = opts.Time
+	}
+	if report.Units, err = p.withUnits(opts.Units); err != nil {
+		return report, errors.Wrapf(err, "%s units", block)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, errors.Wrapf(err, "%s import", block)
+		}
+
+		var row sheetRow
+		if len(pending) > 0 {
+			row, pending = pending[0], pending[1:]
+		} else if row, err = src.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return report, err
+		}
+
+		if row.blank(report.Mapping) {
+			continue
+		}
+		report.TotalRows++
+
+		rec, ok := parse(p, row)
+		if p.err != nil {
+			return report, p.err
+		}
+		if ok {
+			report.AcceptedRows++
+			chunk = append(chunk, rec)
+			if len(chunk) >= chunkSize {
+				if err := flush(); err != nil {
+					return report, err
+				}
+			}
+		}
+
+		if now := time.Now(); now.Sub(lastProgress) >= progressInterval {
+			progress()
+			lastProgress = now
+		}
+	}
+
+	if err := flush(); err != nil {
+		return report, err
+	}
+	progress()
+	return report, nil
+}
+
+// readHeader читает первые строки файла, определяет по ним шапку и возвращает
+// сопоставление вместе с уже прочитанными строками данных.
+func (s *Service) readHeader(src rowReader, model any) (models.ColumnMapping, []sheetRow, error) {
+	var head []sheetRow
+	for len(head) < s.conf.HeaderScanRows {
+		row, err := src.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return models.ColumnMapping{}, nil, err
+		}
+		head = append(head, row)
+	}
+
+	mapping, err := s.detectColumns(head, s.fieldsOf(model))
+	if err != nil {
+		return mapping, nil, err
+	}
+	for i, r := range head {
+		if r.Index == mapping.DataStartRow {
+			return mapping, head[i:], nil
+		}
+	}
+	return mapping, nil, nil
+}
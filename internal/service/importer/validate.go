@@ -0,0 +1,203 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// maxOutOfOrderTimes — сколько меток времени, пришедших не по порядку, запоминается на поле для поиска повторов.
+const maxOutOfOrderTimes = 10000
+
+// timeSeen — принятые метки времени одного поля. Замеры датчика идут по времени, поэтому в упорядоченном
+// файле повтор — это совпадение с предыдущей принятой меткой, и хранить все метки файла не нужно.
+// Метки, пришедшие не по порядку, запоминаются отдельно, не больше maxOutOfOrderTimes: повтор более
+// ранней метки после скачка времени назад не обнаруживается, о чём говорит отчёт.
+type timeSeen struct {
+	last, latest       time.Time // предыдущая и наибольшая принятые метки
+	lastRow, latestRow int
+	back               map[time.Time]int // метка не по порядку -> строка
+}
+
+// rowParser разбирает ячейки строк по сопоставлению колонок и копит проблемы в отчёте.
+// В строгом режиме первая проблема превращается в ошибку err, и разбор файла прекращается.
+type rowParser struct {
+	converter converterService
+	report    *models.ImportReport
+	block     string
+	seenTime  map[string]*timeSeen   // поле -> принятые метки времени
+	loc       *time.Location         // пояс меток без явного смещения
+	timeFix   *models.TimeCorrection // сдвиг или поправка дрейфа; nil — метки как есть
+	units     map[string]models.Unit // поле -> единица колонки, если она отличается от единицы хранения
+	err       error
+}
+
+func (s *Service) newRowParser(report *models.ImportReport, block string) *rowParser {
+	return &rowParser{
+		converter: s.converter,
+		report:    report,
+		block:     block,
+		seenTime:  make(map[string]*timeSeen),
+		loc:       time.UTC,
+	}
+}
+
+// withTime настраивает разбор меток времени по поправке файла.
+func (p *rowParser) withTime(c *models.TimeCorrection) error {
+	if c == nil {
+		return nil
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	loc, err := c.Location()
+	if err != nil {
+		return err
+	}
+	p.loc, p.timeFix = loc, c
+	return nil
+}
+
+// withUnits определяет единицы колонок: указанную в заголовке, иначе заданную в параметрах импорта.
+// Возвращает единицы всех сопоставленных колонок с размерностью для отчёта.
+func (p *rowParser) withUnits(units models.UnitSystem) ([]models.FieldUnit, error) {
+	if err := units.Validate(); err != nil {
+		return nil, err
+	}
+	var out []models.FieldUnit
+	for _, c := range p.report.Mapping.Columns {
+		q, ok := models.FieldQuantities[c.Field]
+		if !ok {
+			continue
+		}
+		fu := models.FieldUnit{Field: c.Field, Unit: units.Unit(q)}
+		if u, ok := models.DetectUnit(c.Header, q); ok {
+			fu.Unit, fu.FromHeader = u, true
+		}
+		if fu.Unit != models.StorageUnits.Unit(q) {
+			if p.units == nil {
+				p.units = make(map[string]models.Unit)
+			}
+			p.units[c.Field] = fu.Unit
+		}
+		out = append(out, fu)
+	}
+	return out, nil
+}
+
+// issue регистрирует проблему строки. Возвращает false, чтобы вызывающий код мог сразу выйти.
+func (p *rowParser) issue(row sheetRow, field string, kind models.IssueKind, value, msg string) bool {
+	col := ""
+	if idx, ok := p.report.Mapping.Index(field); ok {
+		col = columnName(idx)
+	}
+	p.report.AddIssue(models.ImportIssue{
+		Row:     row.Index,
+		Column:  col,
+		Field:   field,
+		Kind:    kind,
+		Value:   value,
+		Message: msg,
+	})
+	if p.report.Mode == models.ImportModeStrict && p.err == nil {
+		p.err = errors.Newf("%s row %d, field %s: %s %q %s", p.block, row.Index, field, kind.Title(), value, msg)
+	}
+	return false
+}
+
+// time разбирает дату/время из колонки поля и применяет поправку времени файла.
+func (p *rowParser) time(row sheetRow, field string) (time.Time, bool) {
+	idx, _ := p.report.Mapping.Index(field)
+	raw := row.cell(idx)
+	ts, err := p.converter.ParseFlexibleTimeIn(raw, p.loc)
+	if err != nil {
+		return time.Time{}, p.issue(row, field, models.IssueBadTimestamp, raw, "")
+	}
+	if p.timeFix != nil {
+		ts = p.timeFix.Apply(ts)
+	}
+	return ts, true
+}
+
+// float разбирает число из колонки поля и переводит его в единицу хранения.
+func (p *rowParser) float(row sheetRow, field string) (float64, bool) {
+	idx, _ := p.report.Mapping.Index(field)
+	raw := row.cell(idx)
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, p.issue(row, field, models.IssueNotNumeric, raw, "")
+	}
+	if u, ok := p.units[field]; ok {
+		// единица проверена в withUnits, перевод не ошибается
+		v, _ = models.Convert(v, u, models.StorageUnits.Unit(models.FieldQuantities[field]))
+	}
+	return v, true
+}
+
+// inRange проверяет, что значение поля лежит в [lo, hi].
+func (p *rowParser) inRange(row sheetRow, field string, v, lo, hi float64) bool {
+	if v < lo || v > hi {
+		idx, _ := p.report.Mapping.Index(field)
+		return p.issue(row, field, models.IssueOutOfRange, row.cell(idx), fmt.Sprintf("допустимо от %g до %g", lo, hi))
+	}
+	return true
+}
+
+// uniqueTime проверяет, что метка времени поля ещё не встречалась среди принятых строк.
+func (p *rowParser) uniqueTime(row sheetRow, field string, ts time.Time) bool {
+	seen := p.seenTime[field]
+	if seen == nil {
+		return true
+	}
+	var (
+		first int
+		dup   bool
+	)
+	switch {
+	case ts.Equal(seen.last):
+		first, dup = seen.lastRow, true
+	case ts.Equal(seen.latest):
+		first, dup = seen.latestRow, true
+	case ts.Before(seen.latest):
+		first, dup = seen.back[ts]
+	}
+	if dup {
+		idx, _ := p.report.Mapping.Index(field)
+		return p.issue(row, field, models.IssueDuplicateTime, row.cell(idx), fmt.Sprintf("уже есть в строке %d", first))
+	}
+	return true
+}
+
+// rememberTime запоминает метку времени принятой строки для поиска повторов.
+func (p *rowParser) rememberTime(row sheetRow, field string, ts time.Time) {
+	seen, ok := p.seenTime[field]
+	switch {
+	case !ok:
+		seen = &timeSeen{latest: ts, latestRow: row.Index}
+		p.seenTime[field] = seen
+	case ts.After(seen.latest):
+		seen.latest, seen.latestRow = ts, row.Index
+	default:
+		if seen.back == nil {
+			seen.back = make(map[time.Time]int)
+			p.report.UnorderedTime = append(p.report.UnorderedTime, field)
+		}
+		if len(seen.back) < maxOutOfOrderTimes {
+			seen.back[ts] = row.Index
+		}
+	}
+	seen.last, seen.lastRow = ts, row.Index
+}
+
+// increasing проверяет, что значение поля строго больше предыдущего принятого.
+func (p *rowParser) increasing(row sheetRow, field string, v float64, prev *float64) bool {
+	if prev != nil && v <= *prev {
+		idx, _ := p.report.Mapping.Index(field)
+		return p.issue(row, field, models.IssueNonMonotonic, row.cell(idx), fmt.Sprintf("предыдущее значение %g", *prev))
+	}
+	return true
+}
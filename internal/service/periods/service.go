@@ -0,0 +1,307 @@
+package periods
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const (
+	defaultMinPeriod = time.Hour // более короткие периоды сливаются с соседними
+	minWindow        = 5         // окно сравнения средних, замеров
+	maxWindow        = 500
+	windowDivisor    = 200    // окно ≈ n/windowDivisor замеров
+	noiseFactor      = 8.0    // во сколько раз скачок должен превышать шум разности средних
+	minRelativeStep  = 0.001  // и не меньше этой доли от типичного давления
+	madToSigma       = 1.4826 // перевод MAD в σ для нормального шума
+)
+
+// Service предлагает график работы скважины по записям блока 1 и дебитам блока 3.
+type Service struct {
+	minPeriod time.Duration
+}
+
+// NewService создает новый экземпляр сервиса определения периодов.
+func NewService() *Service {
+	return &Service{minPeriod: defaultMinPeriod}
+}
+
+// boundary — момент смены режима.
+type boundary struct {
+	at       time.Time
+	step     float64 // скачок давления; 0 — граница найдена только по дебитам
+	strength float64 // |step| относительно порога, 0..1
+}
+
+// Detect разбивает время записей блока 1 на периоды работы и простоя.
+//
+// Границы ищутся по скачкам давления: разность средних по окнам до и после замера,
+// превышающая шум, — локальный максимум даёт границу. Рост давления означает остановку
+// (КВД), падение — пуск (КПД). Если есть блок 3, режим проверяется по дебиту жидкости,
+// который действует от своей метки времени до следующей: нулевой дебит — простой.
+// Смена нулевого дебита на ненулевой и обратно тоже даёт границу.
+func (s *Service) Detect(t1 []models.TableOne, t3 []models.TableThree) []models.PeriodCandidate {
+	if len(t1) < 2 {
+		return nil
+	}
+	data := make([]models.TableOne, len(t1))
+	copy(data, t1)
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Timestamp.Before(data[j].Timestamp) })
+	rates := make([]models.TableThree, len(t3))
+	copy(rates, t3)
+	sort.SliceStable(rates, func(i, j int) bool { return rates[i].Timestamp.Before(rates[j].Timestamp) })
+
+	first, last := data[0].Timestamp, data[len(data)-1].Timestamp
+	window := min(maxWindow, max(minWindow, len(data)/windowDivisor))
+	tolerance := data[min(window, len(data)-1)].Timestamp.Sub(first)
+
+	bounds := mergeBoundaries(pressureSteps(data, window), rateChanges(rates, first, last), tolerance)
+
+	var out []models.PeriodCandidate
+	start := first
+	for i := 0; i <= len(bounds); i++ {
+		end := last
+		if i < len(bounds) {
+			end = bounds[i].at
+		}
+		if !end.After(start) {
+			continue
+		}
+		var opening, closing *boundary
+		if i > 0 {
+			opening = &bounds[i-1]
+		}
+		if i < len(bounds) {
+			closing = &bounds[i]
+		}
+		out = append(out, classify(start, end, opening, closing, rates))
+		start = end
+	}
+
+	out = s.mergeShort(mergeSameKind(out))
+	for i := range out {
+		out[i].Label = fmt.Sprintf("%s %d", out[i].Kind.Title(), i+1)
+	}
+	return out
+}
+
+// pressureSteps находит скачки давления, заметно превышающие шум датчика.
+func pressureSteps(data []models.TableOne, w int) []boundary {
+	n := len(data)
+	if n < 2*w+1 {
+		return nil
+	}
+	prefix := make([]float64, n+1)
+	for i, r := range data {
+		prefix[i+1] = prefix[i] + r.PressureDepth
+	}
+	mean := func(from, to int) float64 { return (prefix[to] - prefix[from]) / float64(to-from) }
+
+	// σ шума по первым разностям: у разности соседних замеров σ·√2
+	diffs := make([]float64, 0, n-1)
+	levels := make([]float64, 0, n)
+	for i := 1; i < n; i++ {
+		diffs = append(diffs, math.Abs(data[i].PressureDepth-data[i-1].PressureDepth))
+	}
+	for _, r := range data {
+		levels = append(levels, math.Abs(r.PressureDepth))
+	}
+	sigma := madToSigma * median(diffs) / math.Sqrt2
+	threshold := max(noiseFactor*sigma*math.Sqrt(2/float64(w)), minRelativeStep*median(levels))
+	if threshold == 0 {
+		return nil
+	}
+
+	steps := make([]float64, n)
+	for i := w; i <= n-w; i++ {
+		steps[i] = mean(i, i+w) - mean(i-w, i)
+	}
+
+	var out []boundary
+	for i := w; i <= n-w; i++ {
+		a := math.Abs(steps[i])
+		if a < threshold {
+			continue
+		}
+		// граница — локальный максимум скачка в окрестности ±w
+		isMax := true
+		for j := max(w, i-w); j <= min(n-w, i+w); j++ {
+			if aj := math.Abs(steps[j]); aj > a || (aj == a && j < i) {
+				isMax = false
+				break
+			}
+		}
+		if isMax {
+			out = append(out, boundary{
+				at:       data[i].Timestamp,
+				step:     steps[i],
+				strength: min(1, a/(3*threshold)),
+			})
+		}
+	}
+	return out
+}
+
+// rateChanges возвращает моменты смены нулевого дебита жидкости на ненулевой и обратно.
+func rateChanges(rates []models.TableThree, from, to time.Time) []boundary {
+	var out []boundary
+	for i := 1; i < len(rates); i++ {
+		wasIdle, isIdle := rates[i-1].LiquidFlowRate == 0, rates[i].LiquidFlowRate == 0
+		at := rates[i].Timestamp
+		if wasIdle != isIdle && at.After(from) && at.Before(to) {
+			out = append(out, boundary{at: at})
+		}
+	}
+	return out
+}
+
+// mergeBoundaries объединяет границы по давлению и дебитам. Скачок давления рядом со сменой
+// дебита (ближе tolerance) считается той же границей и переносится на время из блока 3.
+func mergeBoundaries(steps, changes []boundary, tolerance time.Duration) []boundary {
+	out := append([]boundary(nil), steps...)
+	for _, c := range changes {
+		matched := false
+		for i := range out {
+			if d := out[i].at.Sub(c.at); d >= -tolerance && d <= tolerance {
+				out[i].at = c.at
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].at.Before(out[j].at) })
+	return out
+}
+
+// classify определяет режим отрезка [start, end] по скачку давления и дебиту.
+func classify(start, end time.Time, opening, closing *boundary, rates []models.TableThree) models.PeriodCandidate {
+	c := models.PeriodCandidate{OperationPeriod: models.OperationPeriod{Start: start, End: end}}
+
+	// режим по давлению: скачок вверх в начале — остановка, вниз — пуск;
+	// у первого отрезка — наоборот относительно скачка в его конце
+	var (
+		pressureKind models.PeriodKind
+		strength     float64
+		pressureWhy  string
+	)
+	switch {
+	case opening != nil && opening.step != 0:
+		pressureKind, strength = kindAfterStep(opening.step), opening.strength
+		pressureWhy = fmt.Sprintf("скачок давления %+.2f в начале", opening.step)
+	case opening == nil && closing != nil && closing.step != 0:
+		pressureKind, strength = kindAfterStep(-closing.step), closing.strength
+		pressureWhy = fmt.Sprintf("скачок давления %+.2f в конце", closing.step)
+	}
+
+	// режим по дебиту в середине отрезка
+	var (
+		rateKind models.PeriodKind
+		rateWhy  string
+	)
+	mid := start.Add(end.Sub(start) / 2)
+	if q, ok := rateAt(rates, mid); ok {
+		rateKind = models.PeriodWork
+		rateWhy = fmt.Sprintf("дебит жидкости %.1f", q)
+		if q == 0 {
+			rateKind = models.PeriodIdle
+			rateWhy = "нулевой дебит жидкости"
+		}
+	}
+
+	switch {
+	case pressureKind != "" && rateKind == pressureKind:
+		c.Kind, c.Confidence = rateKind, 0.6+0.4*strength
+		c.Reason = pressureWhy + "; " + rateWhy
+	case pressureKind != "" && rateKind != "":
+		c.Kind, c.Confidence = rateKind, 0.4
+		c.Reason = rateWhy + "; противоречит: " + pressureWhy
+	case rateKind != "":
+		c.Kind, c.Confidence = rateKind, 0.7
+		c.Reason = rateWhy
+	case pressureKind != "":
+		c.Kind, c.Confidence = pressureKind, 0.6*strength
+		c.Reason = pressureWhy
+	default:
+		c.Kind, c.Confidence = models.PeriodWork, 0.1
+		c.Reason = "нет признаков смены режима"
+	}
+	return c
+}
+
+func kindAfterStep(step float64) models.PeriodKind {
+	if step > 0 {
+		return models.PeriodIdle
+	}
+	return models.PeriodWork
+}
+
+// rateAt возвращает дебит жидкости, действующий в момент t: запись блока 3 действует до следующей.
+func rateAt(rates []models.TableThree, t time.Time) (float64, bool) {
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Timestamp.After(t) })
+	if i == 0 {
+		return 0, false
+	}
+	return rates[i-1].LiquidFlowRate, true
+}
+
+// mergeSameKind объединяет соседние периоды одного режима; уверенность усредняется по длительности,
+// пояснение берётся у более длинной части.
+func mergeSameKind(in []models.PeriodCandidate) []models.PeriodCandidate {
+	var out []models.PeriodCandidate
+	for _, c := range in {
+		if n := len(out); n > 0 && out[n-1].Kind == c.Kind {
+			prev := &out[n-1]
+			a, b := prev.End.Sub(prev.Start).Seconds(), c.End.Sub(c.Start).Seconds()
+			prev.Confidence = (prev.Confidence*a + c.Confidence*b) / (a + b)
+			prev.End = c.End
+			if b > a {
+				prev.Reason = c.Reason
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// mergeShort присоединяет периоды короче minPeriod к предыдущему (первый — к следующему)
+// и снова объединяет периоды одного режима.
+func (s *Service) mergeShort(in []models.PeriodCandidate) []models.PeriodCandidate {
+	for {
+		idx := -1
+		for i, c := range in {
+			if len(in) > 1 && c.End.Sub(c.Start) < s.minPeriod {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return in
+		}
+		if idx == 0 {
+			in[1].Start = in[0].Start
+		} else {
+			in[idx-1].End = in[idx].End
+		}
+		in = mergeSameKind(append(in[:idx], in[idx+1:]...))
+	}
+}
+
+func median(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), v...)
+	sort.Float64s(sorted)
+	m := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[m-1] + sorted[m]) / 2
+	}
+	return sorted[m]
+}
@@ -0,0 +1,105 @@
+package project
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// projectEntry — имя файла с данными проекта внутри zip.
+const projectEntry = "project.json"
+
+// Service сохраняет сессию исследования в файл проекта и открывает её обратно.
+// Файл проекта — zip с одним JSON-документом models.Project: сжатие заметно уменьшает
+// длинные ряды замеров, а JSON можно прочитать и без приложения.
+type Service struct{}
+
+// NewService создает новый экземпляр сервиса файлов проекта.
+func NewService() *Service {
+	return &Service{}
+}
+
+// Save записывает проект в path. Запись идёт во временный файл рядом, который затем
+// заменяет path: прерванное сохранение не портит прежний файл.
+func (s *Service) Save(path string, p models.Project) error {
+	p.Format, p.Version, p.SavedAt = models.ProjectFormat, models.ProjectVersion, time.Now().UTC()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "не удалось создать временный файл проекта")
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp, p); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "не удалось записать файл проекта")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrap(err, "не удалось заменить файл проекта")
+	}
+	return nil
+}
+
+// Open читает проект из path и проверяет его формат и версию.
+func (s *Service) Open(path string) (models.Project, error) {
+	var p models.Project
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return p, errors.Wrapf(err, "%s не является файлом проекта", filepath.Base(path))
+	}
+	defer zr.Close()
+
+	f, err := zr.Open(projectEntry)
+	if err != nil {
+		return p, errors.Newf("%s не является файлом проекта: нет %s", filepath.Base(path), projectEntry)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&p); err != nil {
+		return p, errors.Wrap(err, "файл проекта повреждён")
+	}
+	if p.Format != models.ProjectFormat {
+		return p, errors.Newf("%s не является файлом проекта", filepath.Base(path))
+	}
+	if p.Version > models.ProjectVersion {
+		return p, errors.Newf("файл проекта версии %d создан более новой версией приложения (поддерживается до %d)",
+			p.Version, models.ProjectVersion)
+	}
+	return p, nil
+}
+
+// RecoveryPath возвращает путь файла автосохранения для проекта, который ещё не сохранялся.
+func RecoveryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, "не найден каталог настроек пользователя")
+	}
+	dir = filepath.Join(dir, "burovichok")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", errors.Wrap(err, "не удалось создать каталог автосохранения")
+	}
+	return filepath.Join(dir, "autosave"+models.ProjectExtension), nil
+}
+
+func write(f *os.File, p models.Project) error {
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(projectEntry)
+	if err != nil {
+		return errors.Wrap(err, "не удалось записать файл проекта")
+	}
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		return errors.Wrap(err, "не удалось записать данные проекта")
+	}
+	if err := zw.Close(); err != nil {
+		return errors.Wrap(err, "не удалось записать файл проекта")
+	}
+	return nil
+}
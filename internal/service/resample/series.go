@@ -0,0 +1,97 @@
+package resample
+
+import (
+	"math"
+	"time"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// FromTableOne раскладывает блок 1 на ряды давления и температуры. Рзаб на ВДП добавляется,
+// только если оно рассчитано: без гидростатики поле нулевое, и такой ряд только вводил бы в заблуждение.
+func FromTableOne(data []models.TableOne) []models.Series {
+	if len(data) == 0 {
+		return nil
+	}
+	times := make([]time.Time, len(data))
+	pressure := make([]float64, len(data))
+	temperature := make([]float64, len(data))
+	vdp := make([]float64, len(data))
+	hasVDP := false
+	for i, rec := range data {
+		times[i] = rec.Timestamp
+		pressure[i] = rec.PressureDepth
+		temperature[i] = rec.TemperatureDepth
+		vdp[i] = rec.PressureAtVDP
+		hasVDP = hasVDP || (rec.PressureAtVDP != 0 && !math.IsNaN(rec.PressureAtVDP))
+	}
+	out := []models.Series{
+		{Channel: models.ChannelPressureDepth, Times: times, Values: pressure},
+		{Channel: models.ChannelTemperatureDepth, Times: times, Values: temperature},
+	}
+	if hasVDP {
+		out = append(out, models.Series{Channel: models.ChannelPressureAtVDP, Times: times, Values: vdp})
+	}
+	return out
+}
+
+// FromTableTwo раскладывает блок 2 на три ряда: у трубного, затрубного и линейного давления
+// свои метки времени. Замеры без метки времени и провалы, убранные очисткой (NaN), в ряд не попадают.
+func FromTableTwo(data []models.TableTwo) []models.Series {
+	if len(data) == 0 {
+		return nil
+	}
+	tubing := models.Series{Channel: models.ChannelPressureTubing}
+	annulus := models.Series{Channel: models.ChannelPressureAnnulus}
+	linear := models.Series{Channel: models.ChannelPressureLinear}
+	add := func(sr *models.Series, at time.Time, v float64) {
+		if at.IsZero() || math.IsNaN(v) {
+			return
+		}
+		sr.Times = append(sr.Times, at)
+		sr.Values = append(sr.Values, v)
+	}
+	for _, rec := range data {
+		add(&tubing, rec.TimestampTubing, rec.PressureTubing)
+		add(&annulus, rec.TimestampAnnulus, rec.PressureAnnulus)
+		add(&linear, rec.TimestampLinear, rec.PressureLinear)
+	}
+	return []models.Series{tubing, annulus, linear}
+}
+
+// FromTableThree раскладывает блок 3 на ряды дебитов. Расчётные поля без значения дают NaN.
+func FromTableThree(data []models.TableThree) []models.Series {
+	if len(data) == 0 {
+		return nil
+	}
+	times := make([]time.Time, len(data))
+	columns := map[models.Channel][]float64{}
+	channels := []models.Channel{
+		models.ChannelLiquidFlowRate, models.ChannelWaterCut, models.ChannelGasFlowRate,
+		models.ChannelOilFlowRate, models.ChannelWaterFlowRate, models.ChannelGasFactor,
+	}
+	for _, ch := range channels {
+		columns[ch] = make([]float64, len(data))
+	}
+	for i, rec := range data {
+		times[i] = rec.Timestamp
+		columns[models.ChannelLiquidFlowRate][i] = rec.LiquidFlowRate
+		columns[models.ChannelWaterCut][i] = rec.WaterCut
+		columns[models.ChannelGasFlowRate][i] = rec.GasFlowRate
+		columns[models.ChannelOilFlowRate][i] = optional(rec.OilFlowRate)
+		columns[models.ChannelWaterFlowRate][i] = optional(rec.WaterFlowRate)
+		columns[models.ChannelGasFactor][i] = optional(rec.GasFactor)
+	}
+	out := make([]models.Series, 0, len(channels))
+	for _, ch := range channels {
+		out = append(out, models.Series{Channel: ch, Times: times, Values: columns[ch]})
+	}
+	return out
+}
+
+func optional(v *float64) float64 {
+	if v == nil {
+		return math.NaN()
+	}
+	return *v
+}
@@ -0,0 +1,275 @@
+package resample
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+const defaultMaxNodes = 2_000_000 // больше узлов сетка не строится: столько не покажет ни график, ни Excel
+
+// Service выводит ряды блоков 1–3 на общую временную сетку.
+type Service struct {
+	maxNodes int
+}
+
+// NewService создает новый экземпляр сервиса выравнивания рядов.
+func NewService() *Service {
+	return &Service{maxNodes: defaultMaxNodes}
+}
+
+// sample — замер ряда без пропусков.
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// Blocks раскладывает блоки 1–3 на ряды и выводит их на общую сетку. Пустые блоки пропускаются.
+func (s *Service) Blocks(t1 []models.TableOne, t2 []models.TableTwo, t3 []models.TableThree, opts models.ResampleOptions) (models.Frame, error) {
+	var series []models.Series
+	series = append(series, FromTableOne(t1)...)
+	series = append(series, FromTableTwo(t2)...)
+	series = append(series, FromTableThree(t3)...)
+	return s.Resample(series, opts)
+}
+
+// Series возвращает ряд величины ch из блоков 1–3; false — в блоках нет такого ряда.
+func (s *Service) Series(t1 []models.TableOne, t2 []models.TableTwo, t3 []models.TableThree, ch models.Channel) (models.Series, bool) {
+	var series []models.Series
+	series = append(series, FromTableOne(t1)...)
+	series = append(series, FromTableTwo(t2)...)
+	series = append(series, FromTableThree(t3)...)
+	for _, sr := range series {
+		if sr.Channel == ch && len(sr.Times) > 0 {
+			return sr, true
+		}
+	}
+	return models.Series{}, false
+}
+
+// Resample строит сетку по opts и переносит на неё каждый ряд выбранным для него способом.
+//
+// Замеры с NaN не участвуют в расчёте. Узел без значения (пустой интервал, промежуток больше
+// MaxGap, время до первого замера ряда) остаётся NaN.
+func (s *Service) Resample(series []models.Series, opts models.ResampleOptions) (models.Frame, error) {
+	var frame models.Frame
+	if len(series) == 0 {
+		return frame, errors.New("нет рядов для выравнивания")
+	}
+
+	seen := make(map[models.Channel]bool, len(series))
+	samples := make([][]sample, len(series))
+	for j, sr := range series {
+		if seen[sr.Channel] {
+			return frame, errors.Newf("величина %s передана дважды", sr.Channel.Title())
+		}
+		seen[sr.Channel] = true
+		if len(sr.Times) != len(sr.Values) {
+			return frame, errors.Newf("ряд %s: %d меток времени на %d значений", sr.Channel.Title(), len(sr.Times), len(sr.Values))
+		}
+		if err := validAggregation(opts.AggregationFor(sr.Channel)); err != nil {
+			return frame, errors.Wrapf(err, "ряд %s", sr.Channel.Title())
+		}
+		samples[j] = clean(sr)
+	}
+
+	grid, step, err := s.grid(samples, opts)
+	if err != nil {
+		return frame, err
+	}
+
+	frame.Times = grid
+	for j, sr := range series {
+		frame.Channels = append(frame.Channels, sr.Channel)
+		frame.Values = append(frame.Values, resample(samples[j], grid, step, opts.AggregationFor(sr.Channel), opts.MaxGap))
+	}
+	if opts.DropEmpty {
+		frame = dropEmpty(frame)
+	}
+	return frame, nil
+}
+
+func validAggregation(a models.Aggregation) error {
+	switch a {
+	case models.AggregationMean, models.AggregationLast, models.AggregationLinear, models.AggregationStepHold:
+		return nil
+	default:
+		return errors.Newf("неизвестный способ агрегации %q", a)
+	}
+}
+
+// clean возвращает замеры ряда без NaN, упорядоченные по времени.
+func clean(sr models.Series) []sample {
+	out := make([]sample, 0, len(sr.Values))
+	for i, v := range sr.Values {
+		if !math.IsNaN(v) && !sr.Times[i].IsZero() {
+			out = append(out, sample{at: sr.Times[i], value: v})
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].at.Before(out[b].at) })
+	return out
+}
+
+// grid строит узлы сетки. Для постоянного шага возвращается и шаг — ширина последнего интервала;
+// для объединения меток последний интервал вырожден и шаг равен нулю.
+func (s *Service) grid(samples [][]sample, opts models.ResampleOptions) ([]time.Time, time.Duration, error) {
+	start, end := opts.Start, opts.End
+	for _, ss := range samples {
+		if len(ss) == 0 {
+			continue
+		}
+		if opts.Start.IsZero() && (start.IsZero() || ss[0].at.Before(start)) {
+			start = ss[0].at
+		}
+		if opts.End.IsZero() && (end.IsZero() || ss[len(ss)-1].at.After(end)) {
+			end = ss[len(ss)-1].at
+		}
+	}
+	if start.IsZero() || end.IsZero() {
+		return nil, 0, errors.New("в рядах нет замеров")
+	}
+	if end.Before(start) {
+		return nil, 0, errors.Newf("конец сетки %s раньше начала %s", end.Format(time.DateTime), start.Format(time.DateTime))
+	}
+
+	switch opts.Grid {
+	case models.GridFixedStep:
+		if opts.Step <= 0 {
+			return nil, 0, errors.New("шаг сетки должен быть больше нуля")
+		}
+		n := int64(end.Sub(start)/opts.Step) + 1
+		if n > int64(s.maxNodes) {
+			return nil, 0, errors.Newf("с шагом %s получится %d узлов, допустимо не больше %d: увеличьте шаг", opts.Step, n, s.maxNodes)
+		}
+		grid := make([]time.Time, n)
+		for i := range grid {
+			grid[i] = start.Add(time.Duration(i) * opts.Step)
+		}
+		return grid, opts.Step, nil
+
+	case models.GridUnion:
+		var grid []time.Time
+		for _, ss := range samples {
+			for _, sm := range ss {
+				if !sm.at.Before(start) && !sm.at.After(end) {
+					grid = append(grid, sm.at)
+				}
+			}
+		}
+		sort.Slice(grid, func(a, b int) bool { return grid[a].Before(grid[b]) })
+		grid = dedupTimes(grid)
+		if len(grid) > s.maxNodes {
+			return nil, 0, errors.Newf("меток времени %d, допустимо не больше %d: выберите постоянный шаг", len(grid), s.maxNodes)
+		}
+		if len(grid) == 0 {
+			return nil, 0, errors.New("в заданном интервале нет замеров")
+		}
+		return grid, 0, nil
+
+	default:
+		return nil, 0, errors.Newf("неизвестный вид сетки %q", opts.Grid)
+	}
+}
+
+func dedupTimes(times []time.Time) []time.Time {
+	out := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(out[len(out)-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// resample переносит упорядоченные замеры на узлы сетки. Обе последовательности отсортированы,
+// поэтому проход линейный.
+func resample(ss []sample, grid []time.Time, step time.Duration, agg models.Aggregation, maxGap time.Duration) []float64 {
+	out := make([]float64, len(grid))
+	k := 0 // первый замер не раньше текущего узла
+	for i, t := range grid {
+		out[i] = math.NaN()
+		for k < len(ss) && ss[k].at.Before(t) {
+			k++
+		}
+
+		switch agg {
+		case models.AggregationMean, models.AggregationLast:
+			// интервал узла [t, edge); последний интервал при объединении меток — только сам узел
+			var edge time.Time
+			switch {
+			case i+1 < len(grid):
+				edge = grid[i+1]
+			case step > 0:
+				edge = t.Add(step)
+			default:
+				edge = t.Add(1)
+			}
+			var sum float64
+			n := 0
+			for m := k; m < len(ss) && ss[m].at.Before(edge); m++ {
+				sum += ss[m].value
+				out[i] = ss[m].value
+				n++
+			}
+			if agg == models.AggregationMean && n > 0 {
+				out[i] = sum / float64(n)
+			}
+
+		case models.AggregationLinear:
+			switch {
+			case k < len(ss) && ss[k].at.Equal(t):
+				out[i] = ss[k].value
+			case k > 0 && k < len(ss):
+				prev, next := ss[k-1], ss[k]
+				span := next.at.Sub(prev.at)
+				if maxGap > 0 && span > maxGap {
+					continue
+				}
+				ratio := float64(t.Sub(prev.at)) / float64(span)
+				out[i] = prev.value + ratio*(next.value-prev.value)
+			}
+
+		case models.AggregationStepHold:
+			// последний замер не позже узла: при совпадении меток — последний из совпавших
+			m := k
+			for m < len(ss) && ss[m].at.Equal(t) {
+				m++
+			}
+			if m == 0 {
+				continue
+			}
+			held := ss[m-1]
+			if maxGap > 0 && t.Sub(held.at) > maxGap {
+				continue
+			}
+			out[i] = held.value
+		}
+	}
+	return out
+}
+
+// dropEmpty убирает узлы, в которых пусто во всех рядах.
+func dropEmpty(f models.Frame) models.Frame {
+	out := models.Frame{Channels: f.Channels, Values: make([][]float64, len(f.Values))}
+	for i, t := range f.Times {
+		empty := true
+		for j := range f.Values {
+			if !math.IsNaN(f.Values[j][i]) {
+				empty = false
+				break
+			}
+		}
+		if empty {
+			continue
+		}
+		out.Times = append(out.Times, t)
+		for j := range f.Values {
+			out.Values[j] = append(out.Values[j], f.Values[j][i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,155 @@
+package resample
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+var t0 = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+func at(minutes float64) time.Time {
+	return t0.Add(time.Duration(minutes * float64(time.Minute)))
+}
+
+// equalValues сравнивает значения узлов; NaN равен только NaN.
+func equalValues(got, want []float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || !math.IsNaN(want[i]) && math.Abs(got[i]-want[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestResampleAggregation(t *testing.T) {
+	nan := math.NaN()
+	// замер в 2:30 пропущен (NaN) и в расчёте не участвует; узлы — 0, 2 и 4 минуты
+	series := models.Series{
+		Channel: models.ChannelPressureDepth,
+		Times:   []time.Time{at(0), at(1), at(2.5), at(4)},
+		Values:  []float64{1, 3, nan, 5},
+	}
+	tests := []struct {
+		name   string
+		agg    models.Aggregation
+		maxGap time.Duration
+		want   []float64
+	}{
+		{name: "среднее", agg: models.AggregationMean, want: []float64{2, nan, 5}},
+		{name: "последнее", agg: models.AggregationLast, want: []float64{3, nan, 5}},
+		{name: "интерполяция", agg: models.AggregationLinear, want: []float64{1, 3 + 2.0/3, 5}},
+		{name: "интерполяция через большой промежуток", agg: models.AggregationLinear, maxGap: 2 * time.Minute, want: []float64{1, nan, 5}},
+		{name: "удержание", agg: models.AggregationStepHold, want: []float64{1, 3, 5}},
+		{name: "удержание дольше промежутка", agg: models.AggregationStepHold, maxGap: 30 * time.Second, want: []float64{1, nan, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := NewService().Resample([]models.Series{series}, models.ResampleOptions{
+				Grid: models.GridFixedStep, Step: 2 * time.Minute, Aggregation: tt.agg, MaxGap: tt.maxGap,
+			})
+			if err != nil {
+				t.Fatalf("Resample: %v", err)
+			}
+			if len(frame.Times) != 3 || !frame.Times[2].Equal(at(4)) {
+				t.Fatalf("узлы %v; ожидалось 0, 2, 4 минуты", frame.Times)
+			}
+			if got := frame.Values[0]; !equalValues(got, tt.want) {
+				t.Errorf("значения %v; ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResampleUnion(t *testing.T) {
+	nan := math.NaN()
+	series := []models.Series{
+		{Channel: models.ChannelPressureDepth, Times: []time.Time{at(2), at(0)}, Values: []float64{20, 10}},
+		{Channel: models.ChannelLiquidFlowRate, Times: []time.Time{at(1), at(2)}, Values: []float64{5, 7}},
+	}
+	frame, err := NewService().Resample(series, models.ResampleOptions{
+		Grid:        models.GridUnion,
+		Aggregation: models.AggregationMean,
+		Overrides:   map[models.Channel]models.Aggregation{models.ChannelLiquidFlowRate: models.AggregationStepHold},
+	})
+	if err != nil {
+		t.Fatalf("Resample: %v", err)
+	}
+	// общая метка 2:00 попадает в сетку один раз; до первого замера дебита узел пуст
+	if want := []time.Time{at(0), at(1), at(2)}; len(frame.Times) != len(want) || !frame.Times[0].Equal(want[0]) ||
+		!frame.Times[1].Equal(want[1]) || !frame.Times[2].Equal(want[2]) {
+		t.Fatalf("узлы %v; ожидалось %v", frame.Times, want)
+	}
+	if got, want := frame.Column(models.ChannelPressureDepth), []float64{10, nan, 20}; !equalValues(got, want) {
+		t.Errorf("давление %v; ожидалось %v", got, want)
+	}
+	if got, want := frame.Column(models.ChannelLiquidFlowRate), []float64{nan, 5, 7}; !equalValues(got, want) {
+		t.Errorf("дебит %v; ожидалось %v", got, want)
+	}
+}
+
+func TestResampleDropEmpty(t *testing.T) {
+	series := models.Series{Channel: models.ChannelPressureDepth, Times: []time.Time{at(0), at(3)}, Values: []float64{1, 2}}
+	frame, err := NewService().Resample([]models.Series{series}, models.ResampleOptions{
+		Grid: models.GridFixedStep, Step: time.Minute, Aggregation: models.AggregationLast, DropEmpty: true,
+	})
+	if err != nil {
+		t.Fatalf("Resample: %v", err)
+	}
+	if frame.Len() != 2 || !frame.Times[1].Equal(at(3)) || !equalValues(frame.Values[0], []float64{1, 2}) {
+		t.Errorf("узлы %v, значения %v; ожидались 0 и 3 минуты со значениями 1 и 2", frame.Times, frame.Values[0])
+	}
+}
+
+func TestResampleErrors(t *testing.T) {
+	pressure := models.Series{Channel: models.ChannelPressureDepth, Times: []time.Time{at(0), at(10)}, Values: []float64{1, 2}}
+	fixed := models.ResampleOptions{Grid: models.GridFixedStep, Step: time.Minute, Aggregation: models.AggregationMean}
+	tests := []struct {
+		name     string
+		maxNodes int
+		series   []models.Series
+		opts     models.ResampleOptions
+	}{
+		{name: "нет рядов", opts: fixed},
+		{name: "величина дважды", series: []models.Series{pressure, pressure}, opts: fixed},
+		{name: "меток меньше, чем значений", series: []models.Series{{Channel: models.ChannelPressureDepth, Times: []time.Time{at(0)}, Values: []float64{1, 2}}}, opts: fixed},
+		{name: "неизвестная агрегация", series: []models.Series{pressure}, opts: models.ResampleOptions{Grid: models.GridFixedStep, Step: time.Minute, Aggregation: "median"}},
+		{name: "все замеры пропущены", series: []models.Series{{Channel: models.ChannelPressureDepth, Times: []time.Time{at(0)}, Values: []float64{math.NaN()}}}, opts: fixed},
+		{name: "нулевой шаг", series: []models.Series{pressure}, opts: models.ResampleOptions{Grid: models.GridFixedStep, Aggregation: models.AggregationMean}},
+		{name: "конец раньше начала", series: []models.Series{pressure}, opts: models.ResampleOptions{Grid: models.GridFixedStep, Step: time.Minute, Aggregation: models.AggregationMean, Start: at(5), End: at(1)}},
+		{name: "слишком много узлов", maxNodes: 5, series: []models.Series{pressure}, opts: fixed},
+		{name: "в интервале нет замеров", series: []models.Series{pressure}, opts: models.ResampleOptions{Grid: models.GridUnion, Aggregation: models.AggregationMean, Start: at(2), End: at(8)}},
+		{name: "неизвестная сетка", series: []models.Series{pressure}, opts: models.ResampleOptions{Grid: "log", Aggregation: models.AggregationMean}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService()
+			if tt.maxNodes > 0 {
+				s.maxNodes = tt.maxNodes
+			}
+			if _, err := s.Resample(tt.series, tt.opts); err == nil {
+				t.Error("ожидалась ошибка")
+			}
+		})
+	}
+}
+
+func TestFromTableTwo(t *testing.T) {
+	// провал, убранный очисткой (NaN), и замер без метки времени в ряд не попадают
+	data := []models.TableTwo{
+		{TimestampTubing: at(0), PressureTubing: 10, TimestampAnnulus: at(0), PressureAnnulus: math.NaN(), TimestampLinear: at(0), PressureLinear: 3},
+		{TimestampTubing: at(1), PressureTubing: 11, TimestampAnnulus: at(1), PressureAnnulus: 5},
+	}
+	series := FromTableTwo(data)
+	want := map[models.Channel]int{models.ChannelPressureTubing: 2, models.ChannelPressureAnnulus: 1, models.ChannelPressureLinear: 1}
+	for _, sr := range series {
+		if len(sr.Times) != want[sr.Channel] || len(sr.Values) != want[sr.Channel] {
+			t.Errorf("%s: замеров %d; ожидалось %d", sr.Channel, len(sr.Times), want[sr.Channel])
+		}
+	}
+}
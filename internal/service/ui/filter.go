@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+var (
+	despikeMethods = []models.DespikeMethod{models.DespikeNone, models.DespikeHampel, models.DespikeMedian}
+	smoothMethods  = []models.SmoothMethod{models.SmoothNone, models.SmoothMovingAverage, models.SmoothSavitzkyGolay}
+)
+
+// defaultFilterOptions — параметры, с которыми открывается форма очистки, пока она не сохранена.
+var defaultFilterOptions = models.FilterOptions{
+	DropNonPositive: true,
+	Despike:         models.DespikeHampel,
+	DespikeWindow:   7,
+	Threshold:       3,
+	SmoothWindow:    5,
+	PolyOrder:       2,
+}
+
+// tableOneMeasured возвращает измеренные записи блока 1: очищенные, если очистка включена, иначе исходные.
+func (s *Service) tableOneMeasured() ([]models.TableOne, error) {
+	data, err := s.memStorage.GetTableOneData()
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok, err := s.memStorage.GetFilterConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || !cfg.UseClean {
+		return data, nil
+	}
+	data, _, err = s.filter.TableOne(data, cfg.Options)
+	return data, err
+}
+
+// tableTwoData возвращает записи блока 2: очищенные, если очистка включена, иначе исходные.
+func (s *Service) tableTwoData() ([]models.TableTwo, error) {
+	data, err := s.memStorage.GetTableTwoData()
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok, err := s.memStorage.GetFilterConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || !cfg.UseClean {
+		return data, nil
+	}
+	data, _, err = s.filter.TableTwo(data, cfg.Options)
+	return data, err
+}
+
+// setUseClean переключает графики, расчёты и экспорт между очищенными и исходными рядами.
+func (s *Service) setUseClean(useClean bool) error {
+	cfg, ok, err := s.memStorage.GetFilterConfig()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		cfg.Options = defaultFilterOptions
+	}
+	cfg.UseClean = useClean
+	if err := s.memStorage.PutFilterConfig(cfg); err != nil {
+		return err
+	}
+	s.recordChange(models.ChangeSettings, "Очистка сигнала")
+	return s.refreshTableOneChart()
+}
+
+// editFilterConfig открывает форму очистки блоков 1 и 2. Исходные замеры не меняются:
+// сохраняются только параметры, а очищенный ряд строится при каждом обращении к данным.
+func (s *Service) editFilterConfig() {
+	cfg, ok, err := s.memStorage.GetFilterConfig()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить параметры очистки: %w", err), s.window)
+		return
+	}
+	if !ok {
+		cfg = models.FilterConfig{Options: defaultFilterOptions, UseClean: true}
+	}
+	o := cfg.Options
+
+	dropCheck := widget.NewCheck("Убрать провалы записи (давление ≤ 0)", nil)
+	dropCheck.SetChecked(o.DropNonPositive)
+
+	despikeTitles := make([]string, len(despikeMethods))
+	despikeIndex := 0
+	for i, m := range despikeMethods {
+		despikeTitles[i] = m.Title()
+		if m == o.Despike {
+			despikeIndex = i
+		}
+	}
+	despikeSelect := widget.NewSelect(despikeTitles, nil)
+	despikeSelect.SetSelectedIndex(despikeIndex)
+	despikeWindow := widget.NewEntry()
+	despikeWindow.SetText(strconv.Itoa(o.DespikeWindow))
+	threshold := widget.NewEntry()
+	threshold.SetText(formatFormFloat(o.Threshold))
+
+	smoothTitles := make([]string, len(smoothMethods))
+	smoothIndex := 0
+	for i, m := range smoothMethods {
+		smoothTitles[i] = m.Title()
+		if m == o.Smooth {
+			smoothIndex = i
+		}
+	}
+	smoothSelect := widget.NewSelect(smoothTitles, nil)
+	smoothSelect.SetSelectedIndex(smoothIndex)
+	smoothWindow := widget.NewEntry()
+	smoothWindow.SetText(strconv.Itoa(o.SmoothWindow))
+	polyOrder := widget.NewEntry()
+	polyOrder.SetText(strconv.Itoa(o.PolyOrder))
+
+	minDeltaP := widget.NewEntry()
+	minDeltaP.PlaceHolder = "не прореживать"
+	if o.MinDeltaP > 0 {
+		minDeltaP.SetText(formatFormFloat(o.MinDeltaP))
+	}
+	maxDeltaT := widget.NewEntry()
+	maxDeltaT.PlaceHolder = "не прореживать"
+	if o.MaxDeltaT > 0 {
+		maxDeltaT.SetText(formatFormFloat(o.MaxDeltaT.Seconds()))
+	}
+
+	useCleanCheck := widget.NewCheck("Использовать очищенные данные в графиках, расчётах и экспорте", nil)
+	useCleanCheck.SetChecked(cfg.UseClean)
+
+	form := widget.NewForm(
+		widget.NewFormItem("", dropCheck),
+		widget.NewFormItem("Выбросы", despikeSelect),
+		widget.NewFormItem("Окно, замеров", despikeWindow),
+		widget.NewFormItem("Порог Хампеля, σ", threshold),
+		widget.NewFormItem("Сглаживание", smoothSelect),
+		widget.NewFormItem("Окно, замеров", smoothWindow),
+		widget.NewFormItem("Степень полинома", polyOrder),
+		widget.NewFormItem("Прореживание: Δp не меньше", minDeltaP),
+		widget.NewFormItem("или Δt не меньше, с", maxDeltaT),
+		widget.NewFormItem("", useCleanCheck),
+	)
+
+	// parse собирает параметры из формы; ошибки показываются здесь же
+	parse := func() (models.FilterOptions, bool) {
+		opts := models.FilterOptions{
+			DropNonPositive: dropCheck.Checked,
+			Despike:         despikeMethods[max(0, despikeSelect.SelectedIndex())],
+			Smooth:          smoothMethods[max(0, smoothSelect.SelectedIndex())],
+		}
+		var errs []string
+		parseInt := func(e *widget.Entry, name string, dst *int) {
+			v, err := strconv.Atoi(strings.TrimSpace(e.Text))
+			if err != nil {
+				errs = append(errs, name+": требуется целое число")
+			}
+			*dst = v
+		}
+		parseFloat := func(e *widget.Entry, name string, dst *float64) {
+			text := strings.TrimSpace(e.Text)
+			if text == "" {
+				return
+			}
+			v, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+			if err != nil {
+				errs = append(errs, name+": требуется число")
+			}
+			*dst = v
+		}
+		if opts.Despike != models.DespikeNone {
+			parseInt(despikeWindow, "Окно выбросов", &opts.DespikeWindow)
+			parseFloat(threshold, "Порог Хампеля", &opts.Threshold)
+		}
+		if opts.Smooth != models.SmoothNone {
+			parseInt(smoothWindow, "Окно сглаживания", &opts.SmoothWindow)
+			parseInt(polyOrder, "Степень полинома", &opts.PolyOrder)
+		}
+		parseFloat(minDeltaP, "Прореживание по Δp", &opts.MinDeltaP)
+		var seconds float64
+		parseFloat(maxDeltaT, "Прореживание по Δt", &seconds)
+		opts.MaxDeltaT = time.Duration(seconds * float64(time.Second))
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("Проверьте параметры:\n%s", strings.Join(errs, "\n")), s.window)
+			return opts, false
+		}
+		return opts, true
+	}
+
+	// preview очищает оба блока с заданными параметрами и возвращает сводку
+	preview := func(opts models.FilterOptions) (string, error) {
+		t1, err := s.memStorage.GetTableOneData()
+		if err != nil {
+			return "", fmt.Errorf("не удалось получить данные Блока 1: %w", err)
+		}
+		t2, err := s.memStorage.GetTableTwoData()
+		if err != nil {
+			return "", fmt.Errorf("не удалось получить данные Блока 2: %w", err)
+		}
+		_, r1, err := s.filter.TableOne(t1, opts)
+		if err != nil {
+			return "", err
+		}
+		_, r2, err := s.filter.TableTwo(t2, opts)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Блок 1: %s\nБлок 2: %s", formatFilterReport(r1), formatFilterReport(r2)), nil
+	}
+
+	dlg := dialog.NewCustomWithoutButtons("Очистка сигнала (Блоки 1 и 2)", form, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	previewBtn := widget.NewButton("Проверить", func() {
+		opts, ok := parse()
+		if !ok {
+			return
+		}
+		summary, err := preview(opts)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		dialog.ShowInformation("Результат очистки", summary, s.window)
+	})
+	saveBtn := widget.NewButton("Сохранить", func() {
+		opts, ok := parse()
+		if !ok {
+			return
+		}
+		summary, err := preview(opts)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if err := s.memStorage.PutFilterConfig(models.FilterConfig{Options: opts, UseClean: useCleanCheck.Checked}); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось сохранить параметры очистки: %w", err), s.window)
+			return
+		}
+		s.recordChange(models.ChangeSettings, "Очистка сигнала")
+		s.zLog.Infow("Filter config saved", "options", opts, "use_clean", useCleanCheck.Checked)
+		dlg.Hide()
+		if err := s.refreshTableOneChart(); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось обновить график блока 1: %w", err), s.window)
+			return
+		}
+		dialog.ShowInformation("Готово", "Параметры очистки сохранены. Исходные замеры не изменены.\n\n"+summary, s.window)
+	})
+	saveBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, previewBtn, saveBtn})
+	dlg.Resize(fyne.NewSize(600, 560))
+	dlg.Show()
+}
+
+func formatFilterReport(r models.FilterReport) string {
+	if r.Input == 0 {
+		return "нет данных"
+	}
+	return fmt.Sprintf("%d → %d замеров (провалов %d, выбросов заменено %d, прорежено %d)",
+		r.Input, r.Output, r.Dropouts, r.Spikes, r.Decimated)
+}
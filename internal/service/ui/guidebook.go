@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/validation"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// showGuidebookView показывает экран справочников: поиск, добавление, переименование, объединение
+// и удаление значений. Переименование и объединение меняют и отчёты, в которых значение указано.
+func (s *Service) showGuidebookView(ctx context.Context) {
+	s.zLog.Debugw("Opening Guidebook Management view")
+
+	var (
+		guidebook = models.Guidebooks[0]
+		entries   []models.GuidebookEntry // показанные значения
+		selected  *models.GuidebookEntry
+		list      *widget.List
+	)
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder("Поиск")
+	statusLabel := widget.NewLabel("")
+
+	reload := func() {
+		items, err := s.db.ListGuidebook(ctx, guidebook, searchEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить справочник «%s»: %w", guidebook.Title(), err), s.window)
+		}
+		entries, selected = items, nil
+		statusLabel.SetText(fmt.Sprintf("Значений: %d", len(entries)))
+		list.UnselectAll()
+		list.Refresh()
+	}
+
+	list = widget.NewList(
+		func() int { return len(entries) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(formatGuidebookEntry(entries[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		entry := entries[id]
+		selected = &entry
+	}
+	list.OnUnselected = func(widget.ListItemID) { selected = nil }
+
+	titles := make([]string, len(models.Guidebooks))
+	for i, g := range models.Guidebooks {
+		titles[i] = g.Title()
+	}
+	guidebookSelect := widget.NewSelect(titles, func(title string) {
+		for _, g := range models.Guidebooks {
+			if g.Title() == title {
+				guidebook = g
+			}
+		}
+		reload()
+	})
+	searchEntry.OnChanged = func(string) { reload() }
+
+	// выбранное значение; иначе пользователь видит, почему операция недоступна
+	chosen := func(title string) (models.GuidebookEntry, bool) {
+		if selected == nil {
+			dialog.ShowInformation(title, "Выберите значение в списке", s.window)
+			return models.GuidebookEntry{}, false
+		}
+		return *selected, true
+	}
+	done := func(msg string) {
+		reload()
+		statusLabel.SetText(msg)
+	}
+
+	newValueEntry := widget.NewEntry()
+	newValueEntry.SetPlaceHolder("Новое значение")
+	addBtn := widget.NewButton("Добавить", func() {
+		name := strings.TrimSpace(newValueEntry.Text)
+		if err := s.db.AddGuidebookEntry(ctx, guidebook, name); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось добавить значение: %w", err), s.window)
+			return
+		}
+		newValueEntry.SetText("")
+		done(fmt.Sprintf("Значение «%s» добавлено в справочник «%s».", name, guidebook.Title()))
+	})
+	newValueEntry.OnSubmitted = func(string) { addBtn.OnTapped() }
+
+	renameBtn := widget.NewButton("Переименовать", func() {
+		entry, ok := chosen("Переименование")
+		if !ok {
+			return
+		}
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(entry.Name)
+		nameEntry.Validator = validation.NewRegexp(`\S`, "Значение не может быть пустым")
+		dialog.ShowForm("Переименование", "Переименовать", "Отмена",
+			[]*widget.FormItem{
+				widget.NewFormItem("Новое имя", nameEntry),
+				widget.NewFormItem("", widget.NewLabel(fmt.Sprintf("Отчёты, где указано «%s» (%d), получат новое имя.", entry.Name, entry.Reports))),
+			},
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				name := strings.TrimSpace(nameEntry.Text)
+				if err := s.db.RenameGuidebookEntry(ctx, guidebook, entry.Name, name); err != nil {
+					dialog.ShowError(fmt.Errorf("не удалось переименовать значение: %w", err), s.window)
+					return
+				}
+				done(fmt.Sprintf("Значение «%s» переименовано в «%s».", entry.Name, name))
+			}, s.window)
+	})
+
+	mergeBtn := widget.NewButton("Объединить с…", func() {
+		entry, ok := chosen("Объединение")
+		if !ok {
+			return
+		}
+		// цель ищется во всём справочнике, а не только среди найденных значений
+		all, err := s.db.ListGuidebook(ctx, guidebook, "")
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить справочник «%s»: %w", guidebook.Title(), err), s.window)
+			return
+		}
+		var targets []string
+		for _, it := range all {
+			if it.Name != entry.Name {
+				targets = append(targets, it.Name)
+			}
+		}
+		if len(targets) == 0 {
+			dialog.ShowInformation("Объединение", "В справочнике нет других значений", s.window)
+			return
+		}
+		targetSelect := widget.NewSelect(targets, nil)
+		targetSelect.PlaceHolder = "Выберите значение"
+		dialog.ShowForm("Объединение", "Объединить", "Отмена",
+			[]*widget.FormItem{
+				widget.NewFormItem("Оставить", targetSelect),
+				widget.NewFormItem("", widget.NewLabel(fmt.Sprintf("«%s» будет удалено, отчёты с ним (%d) получат выбранное значение.", entry.Name, entry.Reports))),
+			},
+			func(ok bool) {
+				if !ok || targetSelect.Selected == "" {
+					return
+				}
+				into := targetSelect.Selected
+				if err := s.db.MergeGuidebookEntries(ctx, guidebook, entry.Name, into); err != nil {
+					dialog.ShowError(fmt.Errorf("не удалось объединить значения: %w", err), s.window)
+					return
+				}
+				done(fmt.Sprintf("Значение «%s» объединено с «%s».", entry.Name, into))
+			}, s.window)
+	})
+
+	deleteBtn := widget.NewButton("Удалить", func() {
+		entry, ok := chosen("Удаление")
+		if !ok {
+			return
+		}
+		if entry.Reports > 0 {
+			dialog.ShowInformation("Удаление",
+				fmt.Sprintf("Значение «%s» указано в отчётах (%d). Переименуйте его или объедините с другим значением.", entry.Name, entry.Reports),
+				s.window)
+			return
+		}
+		dialog.ShowConfirm("Удаление", fmt.Sprintf("Удалить значение «%s» из справочника «%s»?", entry.Name, guidebook.Title()),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := s.db.DeleteGuidebookEntry(ctx, guidebook, entry.Name); err != nil {
+					dialog.ShowError(fmt.Errorf("не удалось удалить значение: %w", err), s.window)
+					return
+				}
+				done(fmt.Sprintf("Значение «%s» удалено.", entry.Name))
+			}, s.window)
+	})
+
+	backBtn := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
+	top := container.NewVBox(
+		backBtn,
+		widget.NewLabel("Управление справочниками"),
+		widget.NewSeparator(),
+		container.NewGridWithColumns(2, guidebookSelect, searchEntry),
+	)
+	bottom := container.NewVBox(
+		container.NewBorder(nil, nil, nil, addBtn, newValueEntry),
+		container.NewHBox(renameBtn, mergeBtn, deleteBtn),
+		statusLabel,
+	)
+	s.window.SetContent(container.NewBorder(top, bottom, nil, nil, list))
+	guidebookSelect.SetSelected(guidebook.Title())
+}
+
+// formatGuidebookEntry возвращает строку списка справочника.
+func formatGuidebookEntry(e models.GuidebookEntry) string {
+	if e.Reports == 0 {
+		return e.Name + " — не используется"
+	}
+	return fmt.Sprintf("%s — в отчётах: %d", e.Name, e.Reports)
+}
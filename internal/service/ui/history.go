@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// recordChange записывает изменения активного исследования в историю одной операцией.
+func (s *Service) recordChange(kind models.ChangeKind, title string) {
+	if _, err := s.memStorage.RecordChange(kind, title); err != nil {
+		s.zLog.Errorw("Change not recorded", "kind", kind, "title", title, "error", err)
+	}
+}
+
+// undo отменяет последнюю операцию активного исследования.
+func (s *Service) undo() {
+	c, err := s.memStorage.Undo()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("отмена: %w", err), s.window)
+		return
+	}
+	s.zLog.Infow("Change undone", "id", c.ID, "kind", c.Kind, "file", c.File)
+	s.historyChanged()
+}
+
+// redo повторяет последнюю отменённую операцию активного исследования.
+func (s *Service) redo() {
+	c, err := s.memStorage.Redo()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("повтор: %w", err), s.window)
+		return
+	}
+	s.zLog.Infow("Change redone", "id", c.ID, "kind", c.Kind, "file", c.File)
+	s.historyChanged()
+}
+
+// historyChanged обновляет то, что зависит от данных после отмены, повтора или удаления файла.
+func (s *Service) historyChanged() {
+	if err := s.refreshTableOneChart(); err != nil {
+		dialog.ShowError(fmt.Errorf("график блока 1 не обновлён: %w", err), s.window)
+	}
+}
+
+// showHistory показывает историю изменений активного исследования: операции, их файлы и число строк.
+// Последнюю операцию можно отменить и повторить, строки выбранного импорта — удалить.
+func (s *Service) showHistory() {
+	var changes []models.Change
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(changes) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(formatChange(changes[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+	reload := func() {
+		var err error
+		if changes, err = s.memStorage.History(); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить историю: %w", err), s.window)
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+	}
+	reload()
+
+	title := "История изменений: " + s.memStorage.ActiveResearch().Name
+	dlg := dialog.NewCustomWithoutButtons(title, container.NewBorder(
+		widget.NewLabel("Отменённые операции (↶) можно повторить, пока не выполнена новая."), nil, nil, nil, list), s.window)
+	closeBtn := widget.NewButton("Закрыть", dlg.Hide)
+	undoBtn := widget.NewButton("Отменить последнюю", func() {
+		s.undo()
+		reload()
+	})
+	redoBtn := widget.NewButton("Повторить", func() {
+		s.redo()
+		reload()
+	})
+	removeBtn := widget.NewButton("Удалить строки файла", func() {
+		if selected < 0 || changes[selected].Source == 0 || changes[selected].Kind == models.ChangeRemoveSource || changes[selected].Undone {
+			dialog.ShowInformation("Удаление файла", "Выберите выполненную операцию импорта", s.window)
+			return
+		}
+		c := changes[selected]
+		dialog.ShowConfirm("Удаление файла",
+			fmt.Sprintf("Удалить строки файла %s? Остальные импорты не изменятся, удаление можно отменить.", filepath.Base(c.File)),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				removed, err := s.memStorage.RemoveImportSource(c.Source)
+				if err != nil {
+					dialog.ShowError(err, s.window)
+					return
+				}
+				s.zLog.Infow("Import source removed", "source", c.Source, "file", c.File, "rows", removed.RowsDelta())
+				s.historyChanged()
+				reload()
+			}, s.window)
+	})
+	dlg.SetButtons([]fyne.CanvasObject{closeBtn, removeBtn, redoBtn, undoBtn})
+	dlg.Resize(fyne.NewSize(900, 480))
+	dlg.Show()
+}
+
+func formatChange(c models.Change) string {
+	mark := ""
+	if c.Undone {
+		mark = "↶ "
+	}
+	text := fmt.Sprintf("%s%s  %s: %s", mark, c.At.Local().Format("15:04:05"), c.Kind.Title(), c.Title)
+	if c.File != "" {
+		text += " — " + filepath.Base(c.File)
+	}
+	if n := c.RowsDelta(); n != 0 {
+		text += fmt.Sprintf(" (%+d строк)", n)
+	}
+	return text
+}
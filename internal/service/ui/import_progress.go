@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// importProgress показывает ход потокового импорта: долю файла, скорость и кнопку отмены.
+// Одновременно выполняется не больше одного импорта, иначе откат одного испортил бы данные другого.
+type importProgress struct {
+	label     *widget.Label
+	bar       *widget.ProgressBar
+	cancelBtn *widget.Button
+	box       *fyne.Container
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // nil, если импорт не идёт
+	locked []fyne.Disableable // кнопки открытого экрана, недоступные во время импорта
+}
+
+func newImportProgress() *importProgress {
+	p := &importProgress{
+		label: widget.NewLabel(""),
+		bar:   widget.NewProgressBar(),
+	}
+	p.cancelBtn = widget.NewButton("Отменить импорт", func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.cancel != nil {
+			p.cancel()
+			p.cancelBtn.Disable()
+			p.label.SetText(p.label.Text + " — отмена...")
+		}
+	})
+	p.box = container.NewVBox(p.label, container.NewBorder(nil, nil, nil, p.cancelBtn, p.bar))
+	p.box.Hide()
+	return p
+}
+
+// start показывает индикатор. Возвращает false, если другой импорт ещё не завершён.
+func (p *importProgress) start(fileName string, cancel context.CancelFunc) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return false
+	}
+	p.cancel = cancel
+	for _, o := range p.locked {
+		o.Disable()
+	}
+	p.label.SetText(fmt.Sprintf("Импорт файла: %s", fileName))
+	p.bar.SetValue(0)
+	p.cancelBtn.Enable()
+	p.box.Show()
+	return true
+}
+
+// update вызывается из горутины импорта, поэтому виджеты меняются через fyne.Do.
+func (p *importProgress) update(pr models.ImportProgress) {
+	fyne.Do(func() {
+		text := fmt.Sprintf("Строк: %d (принято %d), %.0f строк/с", pr.Rows, pr.Accepted, pr.RowsPerSecond())
+		if pr.Fraction >= 0 {
+			text = fmt.Sprintf("%.0f%% · %s", pr.Fraction*100, text)
+			p.bar.SetValue(pr.Fraction)
+		}
+		p.label.SetText(text)
+	})
+}
+
+func (p *importProgress) finish() {
+	p.mu.Lock()
+	p.cancel = nil
+	locked := p.locked
+	p.mu.Unlock()
+	fyne.Do(func() {
+		p.box.Hide()
+		for _, o := range locked {
+			o.Enable()
+		}
+	})
+}
+
+// running сообщает, что идёт импорт.
+func (p *importProgress) running() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cancel != nil
+}
+
+// lockWhileRunning делает objs недоступными, пока идёт импорт: они сменили бы исследование,
+// в которое пишутся его порции. Кнопки прежнего экрана заменяются.
+func (p *importProgress) lockWhileRunning(objs ...fyne.Disableable) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.locked = objs
+	if p.cancel != nil {
+		for _, o := range objs {
+			o.Disable()
+		}
+	}
+}
+
+// importBusy показывает сообщение и возвращает true, если идёт импорт: пока он не завершён,
+// активное исследование нельзя сменить, очистить или заменить открытыми данными.
+func (s *Service) importBusy(title string) bool {
+	if !s.importProgress.running() {
+		return false
+	}
+	dialog.ShowInformation(title, "Дождитесь завершения импорта или отмените его", s.window)
+	return true
+}
+
+// importFunc запускает потоковый импорт одного блока с заданными параметрами.
+type importFunc func(ctx context.Context, opts models.ImportOptions) (models.ImportReport, error)
+
+// runImport выполняет импорт в фоне, показывая прогресс. Порции сразу попадают в хранилище;
+// при ошибке, отмене или отказе пользователя после отчёта rollback возвращает блок к прежнему состоянию.
+// done, если задан, вызывается в UI-потоке после того, как импорт оставлен и записан в историю.
+func (s *Service) runImport(ctx context.Context, job importJob, opts models.ImportOptions, run importFunc, rollback func() error, done func()) {
+	typ, path := job.typ, job.path
+	ctx, cancel := context.WithCancel(ctx)
+	if !s.importProgress.start(filepath.Base(path), cancel) {
+		cancel()
+		dialog.ShowInformation("Импорт", "Дождитесь завершения текущего импорта или отмените его", s.window)
+		return
+	}
+
+	go func() {
+		defer cancel()
+		start := time.Now()
+		opts.OnProgress = s.importProgress.update
+		report, err := run(ctx, opts)
+		elapsed := time.Since(start)
+		s.importProgress.finish()
+
+		if err != nil {
+			if rbErr := rollback(); rbErr != nil {
+				s.zLog.Errorw(typ+" rollback failed", "error", rbErr)
+			}
+			if errors.Is(err, context.Canceled) {
+				s.zLog.Infow(typ+" import cancelled", "file", path, "rows", report.TotalRows, "duration", elapsed)
+				fyne.Do(func() {
+					dialog.ShowInformation("Импорт отменён",
+						fmt.Sprintf("Разобрано строк: %d. Данные файла не сохранены.", report.TotalRows), s.window)
+				})
+				return
+			}
+			s.zLog.Errorw(typ+" import failed", "error", err, "duration", elapsed)
+			fyne.Do(func() { dialog.ShowError(fmt.Errorf("Ошибка: %w", err), s.window) })
+			return
+		}
+		fyne.Do(func() { s.showImportReport(job, report, elapsed, rollback, done) })
+	}()
+}
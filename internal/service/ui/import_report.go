@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// importMode переводит состояние галочки «Строгий режим» в режим импорта.
+func importMode(strict bool) models.ImportMode {
+	if strict {
+		return models.ImportModeStrict
+	}
+	return models.ImportModeLenient
+}
+
+// showImportReport показывает итог импорта. Принятые строки уже лежат в хранилище; если в файле
+// были проблемы, пользователь видит список проблемных строк и решает, оставить ли принятые или откатить импорт.
+// done, если задан, вызывается после того, как импорт оставлен и записан в историю.
+func (s *Service) showImportReport(job importJob, report models.ImportReport, elapsed time.Duration, rollback func() error, done func()) {
+	typ := job.typ
+	s.zLog.Infow(typ+" import finished",
+		"count", report.AcceptedRows, "rejected", report.RejectedRows(),
+		"duration", elapsed, "header_rows", report.Mapping.HeaderRows)
+
+	timeNote := ""
+	if report.TimeCorrection != nil {
+		timeNote = "\nПоправка времени: " + report.TimeCorrection.String()
+		s.zLog.Infow(typ+" time corrected", "file", report.File, "correction", report.TimeCorrection.String())
+	}
+	if units := formatFieldUnits(report.Converted()); units != "" {
+		timeNote += "\n" + units
+	}
+	if len(report.UnorderedTime) > 0 {
+		timeNote += "\nМетки времени идут не по порядку (" + strings.Join(report.UnorderedTime, ", ") +
+			"): повторы проверены только среди соседних меток"
+		s.zLog.Infow(typ+" timestamps out of order", "file", report.File, "fields", report.UnorderedTime)
+	}
+
+	if !report.HasIssues() {
+		if err := s.recordImportSource(job, report, rollback); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if done != nil {
+			done()
+		}
+		dialog.ShowInformation(
+			"Готово",
+			fmt.Sprintf("%s: %d записей импортировано за %s\nФормат: %s%s\n\n%s",
+				typ, report.AcceptedRows, elapsed.Round(time.Millisecond), report.Format, timeNote, formatMapping(report.Mapping)),
+			s.window,
+		)
+		return
+	}
+
+	s.zLog.Infow(typ+" import has issues", "accepted", report.AcceptedRows, "rejected", report.RejectedRows(), "counts", report.IssueCounts)
+
+	var counts []string
+	for kind, n := range report.IssueCounts {
+		counts = append(counts, fmt.Sprintf("%s: %d", kind.Title(), n))
+	}
+	summary := fmt.Sprintf("Формат: %s%s\nСтрок данных: %d, принято: %d, отброшено: %d\n%s",
+		report.Format, timeNote, report.TotalRows, report.AcceptedRows, report.RejectedRows(), strings.Join(counts, "; "))
+	if report.Truncated {
+		summary += fmt.Sprintf("\nПоказаны первые %d проблем", models.MaxReportIssues)
+	}
+
+	issues := widget.NewList(
+		func() int { return len(report.Issues) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(report.Issues[id].String())
+		},
+	)
+	content := container.NewBorder(
+		widget.NewLabel(summary), nil, nil, nil,
+		container.NewScroll(issues),
+	)
+
+	dlg := dialog.NewCustomConfirm(
+		fmt.Sprintf("Отчёт импорта %s", typ),
+		fmt.Sprintf("Оставить %d строк", report.AcceptedRows), "Откатить импорт",
+		content,
+		func(ok bool) {
+			if ok {
+				if err := s.recordImportSource(job, report, rollback); err != nil {
+					dialog.ShowError(err, s.window)
+					return
+				}
+				if done != nil {
+					done()
+				}
+				return
+			}
+			if err := rollback(); err != nil {
+				s.zLog.Errorw(typ+" rollback failed", "error", err)
+				dialog.ShowError(fmt.Errorf("ошибка отката импорта: %w", err), s.window)
+				return
+			}
+			s.zLog.Infow(typ+" import rolled back after report", "file", report.File)
+		},
+		s.window,
+	)
+	dlg.Resize(fyne.NewSize(800, 500))
+	dlg.Show()
+}
+
+// recordImportSource записывает оставленный импорт в историю: строки помечаются файлом, а файл и параметры
+// импорта попадают в список импортированных. Повторный импорт заменяет строки прежнего. Если записать
+// или заменить не удалось, новые строки откатываются, а прежние остаются.
+func (s *Service) recordImportSource(job importJob, report models.ImportReport, rollback func() error) error {
+	src := models.ImportSource{
+		Block:          job.typ,
+		File:           report.File,
+		Hash:           job.hash,
+		Format:         report.Format,
+		Mode:           report.Mode,
+		Rows:           report.AcceptedRows,
+		ImportedAt:     time.Now().UTC(),
+		TimeCorrection: report.TimeCorrection,
+		Units:          report.Units,
+		Elevation:      job.elevation,
+	}
+	if job.replace == 0 {
+		if _, err := s.memStorage.CommitImport(src); err != nil {
+			s.zLog.Errorw("Failed to record import source", "file", report.File, "error", err)
+			// незаписанные строки остались бы без пометки и заблокировали отмену и правку
+			if rbErr := rollback(); rbErr != nil {
+				return fmt.Errorf("импорт не записан: %w; откат тоже не удался: %v", err, rbErr)
+			}
+			return fmt.Errorf("импорт не записан, строки файла удалены: %w", err)
+		}
+		return nil
+	}
+	if _, err := s.memStorage.ReplaceImport(job.replace, src); err != nil {
+		s.zLog.Errorw("Reimport failed", "file", report.File, "replace", job.replace, "error", err)
+		if rbErr := rollback(); rbErr != nil {
+			return fmt.Errorf("повторный импорт не выполнен: %w; откат тоже не удался: %v", err, rbErr)
+		}
+		return fmt.Errorf("повторный импорт не выполнен, прежние строки оставлены: %w", err)
+	}
+	s.zLog.Infow("File reimported", "file", report.File, "replace", job.replace, "rows", report.AcceptedRows)
+	s.historyChanged()
+	return nil
+}
+
+// formatMapping описывает для пользователя, какие колонки файла были использованы при импорте.
+func formatMapping(m models.ColumnMapping) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Шапка: строки %v, данные с строки %d\n", m.HeaderRows, m.DataStartRow)
+	for _, c := range m.Columns {
+		fmt.Fprintf(&b, "• %s ← %s «%s» (%.0f%%)\n", c.Tag, c.Column, c.Header, c.Score*100)
+	}
+	if len(m.Ignored) > 0 {
+		fmt.Fprintf(&b, "Не использованы: %s", strings.Join(m.Ignored, "; "))
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// liveDelay — сколько копятся уведомления хранилища перед обновлением экрана: импорт пишет данные
+// порциями, и перерисовывать всё после каждой незачем.
+const liveDelay = 300 * time.Millisecond
+
+// liveView — открытый экран, который обновляется при изменении данных.
+type liveView struct {
+	content fyne.CanvasObject
+	refresh func(models.DataBlocks)
+}
+
+// showLiveView показывает content в окне. Пока он открыт, refresh вызывается в главном потоке Fyne
+// с частями данных активного исследования, которые изменились.
+func (s *Service) showLiveView(content fyne.CanvasObject, refresh func(models.DataBlocks)) {
+	s.window.SetContent(content)
+	s.live = liveView{content: content, refresh: refresh}
+}
+
+// watchStorage подписывает интерфейс на изменения хранилища и возвращает функцию отписки.
+func (s *Service) watchStorage() func() {
+	s.liveResearch = s.memStorage.ActiveResearch().ID
+	return s.memStorage.Subscribe(0, models.DataAll, s.storageChanged)
+}
+
+// storageChanged копит изменения и через liveDelay передаёт их в главный поток. Вызывается
+// хранилищем в горутине, которая изменила данные.
+func (s *Service) storageChanged(c models.DataChange) {
+	blocks := c.Blocks
+	// у неактивных исследований на экране видна только строка в списке исследований
+	if c.Research != 0 && c.Research != s.memStorage.ActiveResearch().ID {
+		blocks = models.DataWorkspace
+	}
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	if s.liveBlocks == 0 {
+		time.AfterFunc(liveDelay, s.flushLive)
+	}
+	s.liveBlocks |= blocks
+}
+
+// flushLive передаёт накопленные изменения в главный поток.
+func (s *Service) flushLive() {
+	s.liveMu.Lock()
+	blocks := s.liveBlocks
+	s.liveBlocks = 0
+	s.liveMu.Unlock()
+	fyne.Do(func() { s.dataChanged(blocks) })
+}
+
+// dataChanged обновляет открытый экран и раздаваемый график; вызывается в главном потоке Fyne.
+func (s *Service) dataChanged(blocks models.DataBlocks) {
+	// после смены активного исследования на экране другие данные целиком
+	if id := s.memStorage.ActiveResearch().ID; id != s.liveResearch {
+		s.liveResearch = id
+		blocks |= models.DataResearch
+	}
+	if blocks.Has(models.DataWorkspace) {
+		s.updateTitle()
+	}
+	if s.live.content != nil {
+		if s.window.Content() == s.live.content {
+			s.live.refresh(blocks)
+		} else {
+			// экран закрыт: его виджеты больше не видны
+			s.live = liveView{}
+		}
+	}
+	s.refreshServedChart(blocks)
+}
+
+// openLiveChart строит график generate и раздаёт его веб-сервером. Когда меняются части данных
+// blocks, график перестраивается, а открытая в браузере страница перезагружается.
+func (s *Service) openLiveChart(blocks models.DataBlocks, generate func() (string, error)) error {
+	htmlPath, err := generate()
+	if err != nil {
+		return fmt.Errorf("ошибка генерации HTML графика: %w", err)
+	}
+	if err := s.openChart(htmlPath); err != nil {
+		return err
+	}
+	s.serverMutex.Lock()
+	s.chartBlocks, s.chartGenerate = blocks, generate
+	s.serverMutex.Unlock()
+	return nil
+}
+
+// refreshServedChart перестраивает раздаваемый график, если он зависит от изменённых данных.
+// Строки идущего импорта ещё могут откатиться, поэтому пока они не записаны в историю, график
+// только помечается устаревшим и перестраивается один раз — после записи импорта или его отката.
+func (s *Service) refreshServedChart(blocks models.DataBlocks) {
+	s.serverMutex.Lock()
+	defer s.serverMutex.Unlock()
+	if s.chartGenerate == nil {
+		return
+	}
+	if s.chartBlocks.Has(blocks) {
+		s.chartStale = true
+	}
+	if !s.chartStale || s.chartBuilding || s.memStorage.ActiveResearch().Pending {
+		return
+	}
+	s.chartStale, s.chartBuilding = false, true
+	go s.rebuildServedChart(s.chartGenerate, s.chartVersion)
+}
+
+// rebuildServedChart строит график generate в фоне: на большом блоке это долго, и главный поток
+// не должен ждать. Результат раздаётся, только если за это время не открыли другой график (version).
+// Если данные успели снова измениться, раздаваемый график строится ещё раз.
+func (s *Service) rebuildServedChart(generate func() (string, error), version int) {
+	for {
+		htmlPath, err := generate()
+
+		s.serverMutex.Lock()
+		if s.chartVersion == version {
+			if err != nil {
+				// например, данные удалены: в браузере остаётся прежний график
+				s.zLog.Infow("Served chart not refreshed", "error", err)
+			} else {
+				s.chartHtmlToServe = htmlPath
+				s.chartVersion++
+				s.zLog.Debugw("Served chart refreshed", "serving", htmlPath)
+			}
+		}
+		if s.chartGenerate == nil || !s.chartStale || s.memStorage.ActiveResearch().Pending {
+			s.chartBuilding = false
+			s.serverMutex.Unlock()
+			return
+		}
+		s.chartStale = false
+		generate, version = s.chartGenerate, s.chartVersion
+		s.serverMutex.Unlock()
+	}
+}
@@ -0,0 +1,323 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+	chartService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/chart"
+)
+
+// periodRow — строка редактора графика работы.
+type periodRow struct {
+	kind    *widget.Select
+	label   *widget.Entry
+	start   *widget.Entry
+	end     *widget.Entry
+	density *widget.Entry
+	box     fyne.CanvasObject
+}
+
+var periodKinds = []models.PeriodKind{models.PeriodWork, models.PeriodIdle}
+
+var gapPolicies = []models.GapPolicy{models.GapNaN, models.GapCarryForward, models.GapInterpolate}
+
+// showOperationConfigForm показывает редактор параметров гидростатики и графика работы скважины,
+// заполненный значениями cfg. Диалог закрывается только после успешной проверки, и тогда
+// новые параметры передаются в onSave; при ошибке введённые периоды не теряются.
+func (s *Service) showOperationConfigForm(cfg models.OperationConfig, onSave func(models.OperationConfig)) {
+	units := s.displayUnits()
+	dh := widget.NewEntry() // Δh (м)
+
+	gapTitles := make([]string, len(gapPolicies))
+	for i, p := range gapPolicies {
+		gapTitles[i] = p.Title()
+	}
+	gap := widget.NewSelect(gapTitles, nil)
+	gap.SetSelected(models.GapNaN.Title())
+
+	kindTitles := make([]string, len(periodKinds))
+	for i, k := range periodKinds {
+		kindTitles[i] = k.Title()
+	}
+
+	var rows []*periodRow
+	rowsBox := container.NewVBox()
+	addRow := func(p models.OperationPeriod) {
+		r := &periodRow{
+			kind:    widget.NewSelect(kindTitles, nil),
+			label:   widget.NewEntry(),
+			start:   widget.NewEntry(),
+			end:     widget.NewEntry(),
+			density: widget.NewEntry(),
+		}
+		r.kind.SetSelected(p.Kind.Title())
+		r.label.PlaceHolder = "Подпись"
+		r.start.PlaceHolder = "YYYY-MM-DD hh:mm:ss"
+		r.end.PlaceHolder = "YYYY-MM-DD hh:mm:ss"
+		r.density.PlaceHolder = units.Symbol(models.QuantityDensity)
+		r.label.SetText(p.Label)
+		r.start.SetText(formatFormTime(p.Start))
+		r.end.SetText(formatFormTime(p.End))
+		if p.Density > 0 {
+			r.density.SetText(formatFormFloat(units.FromStorage(models.QuantityDensity, p.Density)))
+		}
+
+		delBtn := widget.NewButton("✕", nil)
+		r.box = container.NewBorder(nil, nil, nil, delBtn,
+			container.NewGridWithColumns(5, r.kind, r.label, r.start, r.end, r.density))
+		delBtn.OnTapped = func() {
+			for i, row := range rows {
+				if row == r {
+					rows = append(rows[:i], rows[i+1:]...)
+					break
+				}
+			}
+			rowsBox.Remove(r.box)
+		}
+		rows = append(rows, r)
+		rowsBox.Add(r.box)
+	}
+
+	// заполняем ранее введёнными значениями, чтобы можно было поправить одно поле
+	if len(cfg.Periods) > 0 {
+		dh.SetText(formatFormFloat(cfg.DepthDiff))
+		if cfg.GapPolicy != "" {
+			gap.SetSelected(cfg.GapPolicy.Title())
+		}
+		for _, p := range cfg.Periods {
+			addRow(p)
+		}
+	} else {
+		addRow(models.OperationPeriod{Kind: models.PeriodWork})
+	}
+
+	addBtn := widget.NewButton("Добавить период", func() {
+		next := models.OperationPeriod{Kind: models.PeriodWork}
+		// новый период по умолчанию начинается там, где закончился последний, и меняет режим
+		if n := len(rows); n > 0 {
+			last := rows[n-1]
+			next.Start, _ = s.converter.ParseFlexibleTime(last.end.Text)
+			if last.kind.Selected == models.PeriodWork.Title() {
+				next.Kind = models.PeriodIdle
+			}
+		}
+		addRow(next)
+	})
+
+	// автоматическое определение заменяет периоды, плотности берутся из уже введённых периодов того же режима
+	detectBtn := widget.NewButton("Определить по данным", func() {
+		s.proposePeriods(func(cands []models.PeriodCandidate) {
+			densities := make(map[models.PeriodKind]float64)
+			for _, r := range rows {
+				kind := periodKinds[max(0, r.kind.SelectedIndex())]
+				if d, err := strconv.ParseFloat(strings.TrimSpace(r.density.Text), 64); err == nil && densities[kind] == 0 {
+					densities[kind] = units.ToStorage(models.QuantityDensity, d)
+				}
+			}
+			rows = nil
+			rowsBox.RemoveAll()
+			for _, c := range cands {
+				p := c.OperationPeriod
+				p.Density = densities[p.Kind]
+				addRow(p)
+			}
+		})
+	})
+
+	header := container.NewGridWithColumns(5,
+		widget.NewLabel("Режим"), widget.NewLabel("Подпись"),
+		widget.NewLabel("Начало"), widget.NewLabel("Конец"),
+		widget.NewLabel("Плотность, "+units.Symbol(models.QuantityDensity)))
+
+	form := widget.NewForm(
+		widget.NewFormItem("Δh (м)", dh),
+		widget.NewFormItem("Между периодами", gap),
+	)
+	content := container.NewBorder(
+		container.NewVBox(form, widget.NewSeparator(), widget.NewLabel("График работы скважины:"), header),
+		container.NewGridWithColumns(2, addBtn, detectBtn), nil, nil,
+		container.NewVScroll(rowsBox),
+	)
+
+	dlg := dialog.NewCustomWithoutButtons("Параметры гидростатики", content, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	saveBtn := widget.NewButton("Ок", func() {
+		var errs []string
+		depthDiff, err := strconv.ParseFloat(strings.TrimSpace(dh.Text), 64)
+		if err != nil {
+			errs = append(errs, "Δh (м): требуется число")
+		}
+
+		out := models.OperationConfig{
+			DepthDiff: depthDiff,
+			GapPolicy: gapPolicies[max(0, gap.SelectedIndex())],
+		}
+		for i, r := range rows {
+			p := models.OperationPeriod{
+				Kind:  periodKinds[max(0, r.kind.SelectedIndex())],
+				Label: strings.TrimSpace(r.label.Text),
+			}
+			if p.Label == "" {
+				p.Label = fmt.Sprintf("%s %d", p.Kind.Title(), i+1)
+			}
+			if p.Start, err = s.converter.ParseFlexibleTime(r.start.Text); err != nil {
+				errs = append(errs, fmt.Sprintf("Период %d, начало — %v", i+1, err))
+			}
+			if p.End, err = s.converter.ParseFlexibleTime(r.end.Text); err != nil {
+				errs = append(errs, fmt.Sprintf("Период %d, конец — %v", i+1, err))
+			}
+			if p.Density, err = strconv.ParseFloat(strings.TrimSpace(r.density.Text), 64); err != nil {
+				errs = append(errs, fmt.Sprintf("Период %d, плотность: требуется число", i+1))
+			}
+			p.Density = units.ToStorage(models.QuantityDensity, p.Density)
+			out.Periods = append(out.Periods, p)
+		}
+		if len(errs) == 0 {
+			if err := out.Normalize(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("Проверьте параметры:\n%s", strings.Join(errs, "\n")), s.window)
+			return
+		}
+
+		dlg.Hide()
+		onSave(out)
+	})
+	saveBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+	dlg.Resize(fyne.NewSize(900, 600))
+	dlg.Show()
+}
+
+// editOperationConfig открывает форму с текущими параметрами гидростатики и сохраняет новые.
+// Рзаб на ВДП пересчитывается при следующем чтении блока 1, уже построенный график обновляется сразу.
+func (s *Service) editOperationConfig() {
+	cfg, _, err := s.memStorage.GetOperationConfig()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить параметры гидростатики: %w", err), s.window)
+		return
+	}
+	s.showOperationConfigForm(cfg, func(cfg models.OperationConfig) {
+		if err := s.memStorage.PutOperationConfig(cfg); err != nil {
+			dialog.ShowError(fmt.Errorf("ошибка сохранения параметров: %w", err), s.window)
+			return
+		}
+		s.recordChange(models.ChangeSettings, "График работы и гидростатика")
+		s.zLog.Infow("Operation config updated", "config", cfg)
+		if err := s.refreshTableOneChart(); err != nil {
+			s.zLog.Errorw("TableOne chart refresh failed", "error", err)
+			dialog.ShowError(fmt.Errorf("параметры сохранены, но график не обновлён: %w", err), s.window)
+			return
+		}
+		dialog.ShowInformation("Готово", "Параметры гидростатики сохранены, Рзаб на ВДП пересчитано", s.window)
+	})
+}
+
+// proposePeriods определяет периоды работы и простоя по блокам 1 и 3 и показывает их с уверенностью.
+// Если пользователь соглашается, кандидаты передаются в apply — в редактор графика, где их можно поправить.
+func (s *Service) proposePeriods(apply func([]models.PeriodCandidate)) {
+	t1, err := s.tableOneMeasured()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 1: %w", err), s.window)
+		return
+	}
+	if len(t1) == 0 {
+		dialog.ShowInformation("Нет данных", "Сначала импортируйте Блок 1", s.window)
+		return
+	}
+	t3, err := s.memStorage.GetTableThreeData()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 3: %w", err), s.window)
+		return
+	}
+
+	go func() {
+		cands := s.periods.Detect(t1, t3)
+		s.zLog.Infow("Periods detected", "count", len(cands), "block1", len(t1), "block3", len(t3))
+		fyne.Do(func() {
+			if len(cands) == 0 {
+				dialog.ShowInformation("Периоды", "Не удалось выделить периоды по данным", s.window)
+				return
+			}
+			list := widget.NewList(
+				func() int { return len(cands) },
+				func() fyne.CanvasObject { return widget.NewLabel("") },
+				func(id widget.ListItemID, o fyne.CanvasObject) {
+					c := cands[id]
+					mark := ""
+					if c.Confidence < 0.5 {
+						mark = "⚠ "
+					}
+					o.(*widget.Label).SetText(fmt.Sprintf("%s%s: %s — %s, уверенность %.0f%% (%s)",
+						mark, c.Label, formatFormTime(c.Start), formatFormTime(c.End), c.Confidence*100, c.Reason))
+				},
+			)
+			source := "по давлению Блока 1"
+			if len(t3) > 0 {
+				source += " и дебитам Блока 3"
+			}
+			dlg := dialog.NewCustomConfirm("Предложенные периоды", "Подставить в график", "Отмена",
+				container.NewBorder(widget.NewLabel("Периоды определены "+source+". Проверьте границы и плотности."), nil, nil, nil, list),
+				func(ok bool) {
+					if ok {
+						apply(cands)
+					}
+				}, s.window)
+			dlg.Resize(fyne.NewSize(900, 400))
+			dlg.Show()
+		})
+	}()
+}
+
+// tableOneData возвращает записи блока 1 с Рзаб на ВДП, рассчитанным по текущим параметрам гидростатики.
+func (s *Service) tableOneData() ([]models.TableOne, error) {
+	data, err := s.tableOneMeasured()
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok, err := s.memStorage.GetOperationConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return data, nil
+	}
+	return calc.TableOneSeries(data, cfg), nil
+}
+
+// refreshTableOneChart перестраивает график блока 1, если он уже был построен:
+// его же забирает экспорт, и открытая в браузере страница покажет новые значения после обновления.
+func (s *Service) refreshTableOneChart() error {
+	if _, err := os.Stat(chartService.HTMLFileNameOne); os.IsNotExist(err) {
+		return nil
+	}
+	data, err := s.tableOneData()
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	_, err = s.chart.GenerateTableOneChart(data, s.displayUnits())
+	return err
+}
+
+func formatFormTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func formatFormFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,401 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/browser"
+	"github.com/samber/lo"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+)
+
+const defaultSmoothing = 0.2 // окно L по умолчанию: 0.1–0.5 — обычный диапазон для производной Бурде
+
+var transientTimes = []models.TransientTime{models.TimeElapsed, models.TimeSuperposition}
+
+// showBuildUpForm предлагает выбрать период простоя из графика работы и параметры производной,
+// затем строит диагностический log-log график КВД.
+func (s *Service) showBuildUpForm() {
+	_, idle, ok := s.idlePeriods()
+	if !ok {
+		return
+	}
+	period := idlePeriodSelect(idle)
+
+	timeTitles := make([]string, len(transientTimes))
+	for i, t := range transientTimes {
+		timeTitles[i] = t.Title()
+	}
+	timeFunc := widget.NewSelect(timeTitles, nil)
+	timeFunc.SetSelectedIndex(0)
+
+	smoothing := widget.NewEntry()
+	smoothing.SetText(formatFormFloat(defaultSmoothing))
+
+	form := widget.NewForm(
+		widget.NewFormItem("Период простоя", period),
+		widget.NewFormItem("Время", timeFunc),
+		widget.NewFormItem("Сглаживание L", smoothing),
+	)
+
+	dlg := dialog.NewCustomWithoutButtons("Анализ КВД", form, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	buildBtn := widget.NewButton("Построить", func() {
+		l, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(smoothing.Text), ",", "."), 64)
+		if err != nil || l < 0 {
+			dialog.ShowError(fmt.Errorf("сглаживание L: требуется неотрицательное число"), s.window)
+			return
+		}
+		opts := models.BuildUpOptions{
+			Smoothing: l,
+			Time:      transientTimes[max(0, timeFunc.SelectedIndex())],
+		}
+		p := idle[max(0, period.SelectedIndex())]
+		if err := s.buildUpChart(p, opts); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		dlg.Hide()
+	})
+	buildBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, buildBtn})
+	dlg.Resize(fyne.NewSize(600, 250))
+	dlg.Show()
+}
+
+// idlePeriods возвращает график работы и его периоды простоя. Если их нет, показывает подсказку и возвращает false.
+func (s *Service) idlePeriods() (models.OperationConfig, []models.OperationPeriod, bool) {
+	cfg, ok, err := s.memStorage.GetOperationConfig()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить график работы: %w", err), s.window)
+		return cfg, nil, false
+	}
+	var idle []models.OperationPeriod
+	for _, p := range cfg.Periods {
+		if p.Kind == models.PeriodIdle {
+			idle = append(idle, p)
+		}
+	}
+	if !ok || len(idle) == 0 {
+		dialog.ShowInformation("Нет периодов простоя",
+			"Задайте период простоя в графике работы (кнопка «График работы и гидростатика» на экране импорта)", s.window)
+		return cfg, nil, false
+	}
+	return cfg, idle, true
+}
+
+// idlePeriodSelect — выбор периода простоя, по умолчанию последнего.
+func idlePeriodSelect(idle []models.OperationPeriod) *widget.Select {
+	titles := make([]string, len(idle))
+	for i, p := range idle {
+		titles[i] = fmt.Sprintf("%s: %s — %s", p.Label, formatFormTime(p.Start), formatFormTime(p.End))
+	}
+	sel := widget.NewSelect(titles, nil)
+	sel.SetSelectedIndex(len(idle) - 1)
+	return sel
+}
+
+// buildUpChart рассчитывает Δp и производную для периода и открывает график в браузере.
+func (s *Service) buildUpChart(period models.OperationPeriod, opts models.BuildUpOptions) error {
+	t1, err := s.tableOneMeasured()
+	if err != nil {
+		return fmt.Errorf("не удалось получить данные Блока 1: %w", err)
+	}
+	t3, err := s.memStorage.GetTableThreeData()
+	if err != nil {
+		return fmt.Errorf("не удалось получить данные Блока 3: %w", err)
+	}
+	res, err := calc.BuildUp(t1, period, t3, opts)
+	if err != nil {
+		return err
+	}
+	s.zLog.Infow("Build-up analysed", "period", period.Label, "points", len(res.Points),
+		"time", opts.Time, "smoothing", opts.Smoothing)
+
+	htmlPath, err := s.chart.GenerateBuildUpChart(res, s.displayUnits())
+	if err != nil {
+		return fmt.Errorf("ошибка генерации HTML графика: %w", err)
+	}
+	return s.openChart(htmlPath)
+}
+
+// openChart раздаёт htmlPath локальным веб-сервером и открывает его в браузере.
+func (s *Service) openChart(htmlPath string) error {
+	s.serverMutex.Lock()
+	s.chartHtmlToServe = htmlPath
+	s.chartVersion++
+	// openLiveChart подключит перестроение после открытия; остальные графики строятся по форме один раз
+	s.chartBlocks, s.chartGenerate, s.chartStale = 0, nil, false
+	s.serverMutex.Unlock()
+	if err := s.startLocalWebServer(); err != nil {
+		s.serverMutex.Lock()
+		s.chartHtmlToServe = ""
+		s.serverMutex.Unlock()
+		return fmt.Errorf("ошибка запуска веб-сервера для графика: %w", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	s.serverMutex.Lock()
+	port := s.serverPort
+	s.serverMutex.Unlock()
+	if port == "" {
+		return fmt.Errorf("не удалось получить порт веб-сервера")
+	}
+	url := fmt.Sprintf("http://127.0.0.1:%s/", port)
+	s.zLog.Debugw("Opening chart via web server", "url", url, "serving", htmlPath)
+
+	if err := browser.OpenURL(url); err != nil {
+		return fmt.Errorf("не удалось открыть браузер: %w", err)
+	}
+	return nil
+}
+
+// reservoirEntries — поля ввода свойств пласта, общие для шапки отчёта и интерпретации КВД.
+type reservoirEntries struct {
+	thickness, porosity, viscosity, volumeFactor, compressibility, wellRadius *widget.Entry
+}
+
+func newReservoirEntries(r models.ReservoirParams) *reservoirEntries {
+	entry := func(v float64) *widget.Entry {
+		e := widget.NewEntry()
+		if v != 0 {
+			e.SetText(formatFormFloat(v))
+		}
+		return e
+	}
+	return &reservoirEntries{
+		thickness:       entry(r.Thickness),
+		porosity:        entry(r.Porosity),
+		viscosity:       entry(r.Viscosity),
+		volumeFactor:    entry(r.VolumeFactor),
+		compressibility: entry(r.Compressibility),
+		wellRadius:      entry(r.WellRadius),
+	}
+}
+
+func (e *reservoirEntries) fields() []struct {
+	title string
+	entry *widget.Entry
+} {
+	return []struct {
+		title string
+		entry *widget.Entry
+	}{
+		{"Толщина пласта h, м", e.thickness},
+		{"Пористость φ, доли ед.", e.porosity},
+		{"Вязкость μ, мПа·с", e.viscosity},
+		{"Объёмный коэффициент Bo, м³/м³", e.volumeFactor},
+		{"Сжимаемость ct, 1/МПа", e.compressibility},
+		{"Радиус скважины rw, м", e.wellRadius},
+	}
+}
+
+// formItems возвращает строки формы; suffix дописывается к подписям, например «(опц.)».
+func (e *reservoirEntries) formItems(suffix string) []*widget.FormItem {
+	var items []*widget.FormItem
+	for _, f := range e.fields() {
+		items = append(items, widget.NewFormItem(strings.TrimSpace(f.title+" "+suffix), f.entry))
+	}
+	return items
+}
+
+// parse читает введённые значения. Пустые поля допустимы, если optional, и дают ноль.
+func (e *reservoirEntries) parse(optional bool) (models.ReservoirParams, []string) {
+	var (
+		errs   []string
+		values [6]float64
+	)
+	for i, f := range e.fields() {
+		text := strings.ReplaceAll(strings.TrimSpace(f.entry.Text), ",", ".")
+		if text == "" {
+			if !optional {
+				errs = append(errs, f.title+": не задано")
+			}
+			continue
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || v <= 0 {
+			errs = append(errs, f.title+": требуется положительное число")
+			continue
+		}
+		values[i] = v
+	}
+	return models.ReservoirParams{
+		Thickness:       values[0],
+		Porosity:        values[1],
+		Viscosity:       values[2],
+		VolumeFactor:    values[3],
+		Compressibility: values[4],
+		WellRadius:      values[5],
+	}, errs
+}
+
+var semilogMethods = []models.SemilogMethod{models.MethodHorner, models.MethodMDH}
+
+// showSemilogForm предлагает выбрать период простоя, метод и участок радиального притока,
+// запрашивает свойства пласта (по умолчанию — из шапки отчёта) и строит полулогарифмический график.
+func (s *Service) showSemilogForm(ctx context.Context) {
+	_, idle, ok := s.idlePeriods()
+	if !ok {
+		return
+	}
+	report, err := s.memStorage.GetTableFiveData()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить шапку отчёта: %w", err), s.window)
+		return
+	}
+
+	period := idlePeriodSelect(idle)
+	methodTitles := make([]string, len(semilogMethods))
+	for i, m := range semilogMethods {
+		methodTitles[i] = m.Title()
+	}
+	method := widget.NewSelect(methodTitles, nil)
+	method.SetSelectedIndex(0)
+	from, to := widget.NewEntry(), widget.NewEntry()
+	from.PlaceHolder, to.PlaceHolder = "авто", "авто"
+	reservoir := newReservoirEntries(report.Reservoir())
+
+	form := widget.NewForm(
+		widget.NewFormItem("Период простоя", period),
+		widget.NewFormItem("Метод", method),
+		widget.NewFormItem("Радиальный приток с, ч", from),
+		widget.NewFormItem("Радиальный приток по, ч", to),
+	)
+	for _, item := range reservoir.formItems("") {
+		form.AppendItem(item)
+	}
+
+	dlg := dialog.NewCustomWithoutButtons("Интерпретация КВД", form, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	buildBtn := widget.NewButton("Рассчитать", func() {
+		opts := models.SemilogOptions{Method: semilogMethods[max(0, method.SelectedIndex())]}
+		var errs []string
+		opts.Reservoir, errs = reservoir.parse(false)
+		fromText, toText := strings.TrimSpace(from.Text), strings.TrimSpace(to.Text)
+		if fromText != "" || toText != "" {
+			if opts.FromHours, err = strconv.ParseFloat(strings.ReplaceAll(fromText, ",", "."), 64); err != nil {
+				errs = append(errs, "Радиальный приток с: требуется число или пусто для обоих полей")
+			}
+			if opts.ToHours, err = strconv.ParseFloat(strings.ReplaceAll(toText, ",", "."), 64); err != nil {
+				errs = append(errs, "Радиальный приток по: требуется число или пусто для обоих полей")
+			}
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("Проверьте параметры:\n%s", strings.Join(errs, "\n")), s.window)
+			return
+		}
+
+		units := s.displayUnits()
+		res, err := s.semilogAnalysis(ctx, idle[max(0, period.SelectedIndex())], opts, units)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		dlg.Hide()
+		s.showSemilogResult(res, units)
+	})
+	buildBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, buildBtn})
+	dlg.Resize(fyne.NewSize(600, 500))
+	dlg.Show()
+}
+
+// semilogAnalysis интерпретирует КВД, сохраняет результаты в шапку отчёта и открывает график.
+func (s *Service) semilogAnalysis(ctx context.Context, period models.OperationPeriod, opts models.SemilogOptions,
+	units models.UnitSystem) (models.SemilogResult, error) {
+	t1, err := s.tableOneMeasured()
+	if err != nil {
+		return models.SemilogResult{}, fmt.Errorf("не удалось получить данные Блока 1: %w", err)
+	}
+	t3, err := s.memStorage.GetTableThreeData()
+	if err != nil {
+		return models.SemilogResult{}, fmt.Errorf("не удалось получить данные Блока 3: %w", err)
+	}
+	report, err := s.memStorage.GetTableFiveData()
+	if err != nil {
+		return models.SemilogResult{}, fmt.Errorf("не удалось получить шапку отчёта: %w", err)
+	}
+
+	res, err := calc.Semilog(t1, period, t3, report, opts)
+	if err != nil {
+		return res, err
+	}
+	s.zLog.Infow("Semilog analysis done", "period", period.Label, "method", res.Method,
+		"from", res.FromHours, "to", res.ToHours, "kh", res.PermeabilityThickness, "skin", res.Skin)
+
+	if err := s.saveSemilogResult(ctx, report, res); err != nil {
+		return res, err
+	}
+
+	htmlPath, err := s.chart.GenerateSemilogChart(res, units)
+	if err != nil {
+		return res, fmt.Errorf("ошибка генерации HTML графика: %w", err)
+	}
+	return res, s.openChart(htmlPath)
+}
+
+// saveSemilogResult дописывает свойства пласта и результаты в шапку отчёта в памяти и, если отчёт
+// уже сохранён, в БД. Незаполненную шапку не трогает: архиватор по ней проверяет, составлена ли тех. карта.
+func (s *Service) saveSemilogResult(ctx context.Context, report models.TableFive, res models.SemilogResult) error {
+	if report == (models.TableFive{}) {
+		s.zLog.Infow("Semilog result not stored: report header is empty")
+		return nil
+	}
+	report.SetReservoir(res.Reservoir)
+	method := string(res.Method)
+	report.AnalysisMethod = &method
+	report.ExtrapolatedPressure = nil
+	if !math.IsNaN(res.PStarVDP) {
+		report.ExtrapolatedPressure = lo.ToPtr(res.PStarVDP)
+	}
+	report.PermeabilityThickness = lo.ToPtr(res.PermeabilityThickness)
+	report.Permeability = lo.ToPtr(res.Permeability)
+	report.Skin = lo.ToPtr(res.Skin)
+
+	if err := s.memStorage.PutTableFiveData(report); err != nil {
+		return fmt.Errorf("не удалось сохранить результаты в отчёт: %w", err)
+	}
+	s.recordChange(models.ChangeReport, "Результаты интерпретации")
+	if report.ID == 0 {
+		return nil
+	}
+	if err := s.db.UpdateReportAnalysis(ctx, report); err != nil {
+		return fmt.Errorf("результаты не сохранены в БД: %w", err)
+	}
+	return nil
+}
+
+// showSemilogResult показывает рассчитанные параметры пласта в единицах units.
+func (s *Service) showSemilogResult(res models.SemilogResult, units models.UnitSystem) {
+	kp, pUnit := units.Scale(models.QuantityPressure), units.Symbol(models.QuantityPressure)
+	lines := []string{
+		fmt.Sprintf("Участок радиального притока: %.3g–%.3g ч, R² = %.4f", res.FromHours, res.ToHours, res.R2),
+		fmt.Sprintf("q = %.2f %s, tp = %.1f ч", units.FromStorage(models.QuantityLiquidRate, res.Rate),
+			units.Symbol(models.QuantityLiquidRate), res.ProducingTime),
+		fmt.Sprintf("m = %.4g %s на цикл", res.Slope*kp, pUnit),
+	}
+	if res.Method == models.MethodHorner {
+		line := fmt.Sprintf("p* = %.4g %s", res.PStar*kp, pUnit)
+		if !math.IsNaN(res.PStarVDP) {
+			line += fmt.Sprintf(" (на ВДП %.4g %s)", res.PStarVDP*kp, pUnit)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines,
+		fmt.Sprintf("kh = %.4g мД·м, k = %.4g мД", res.PermeabilityThickness, res.Permeability),
+		fmt.Sprintf("Скин-фактор S = %.2f", res.Skin),
+	)
+	if res.Method == models.MethodMDH && res.ToHours > res.ProducingTime/10 {
+		lines = append(lines, "⚠ MDH применим при Δt ≪ tp, для этого участка точнее метод Хорнера")
+	}
+	dialog.ShowInformation(res.Method.Title()+": "+res.Period.Label, strings.Join(lines, "\n"), s.window)
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+)
+
+// showProductivityForm запрашивает пластовое давление и давление насыщения и рассчитывает
+// продуктивность по режимам. Pпл по умолчанию — p* на ВДП из интерпретации КВД, если она сохранена в отчёте.
+func (s *Service) showProductivityForm() {
+	_, ok, err := s.memStorage.GetOperationConfig()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить параметры гидростатики: %w", err), s.window)
+		return
+	}
+	if !ok {
+		dialog.ShowInformation("Нет Рзаб на ВДП",
+			"Задайте график работы и гидростатику (кнопка на экране импорта): продуктивность считается по Рзаб на ВДП", s.window)
+		return
+	}
+	report, err := s.memStorage.GetTableFiveData()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить шапку отчёта: %w", err), s.window)
+		return
+	}
+
+	units := s.displayUnits()
+	pUnit := units.Symbol(models.QuantityPressure)
+	reservoir := widget.NewEntry()
+	if report.ExtrapolatedPressure != nil {
+		reservoir.SetText(formatFormFloat(math.Round(units.FromStorage(models.QuantityPressure, *report.ExtrapolatedPressure)*1000) / 1000))
+	}
+	bubble := widget.NewEntry()
+	bubble.PlaceHolder = "нет — кривая Вогеля"
+
+	form := widget.NewForm(
+		widget.NewFormItem("Пластовое давление на ВДП, "+pUnit, reservoir),
+		widget.NewFormItem("Давление насыщения, "+pUnit, bubble),
+	)
+	if report.ExtrapolatedPressure != nil {
+		form.Append("", widget.NewLabel("Pпл подставлено из интерпретации КВД (p* на ВДП)"))
+	}
+
+	dlg := dialog.NewCustomWithoutButtons("Продуктивность и IPR", form, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	calcBtn := widget.NewButton("Рассчитать", func() {
+		var (
+			opts models.IPROptions
+			errs []string
+		)
+		if opts.ReservoirPressure, err = strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(reservoir.Text), ",", "."), 64); err != nil {
+			errs = append(errs, "Пластовое давление: требуется число")
+		}
+		if text := strings.TrimSpace(bubble.Text); text != "" {
+			if opts.BubblePointPressure, err = strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64); err != nil {
+				errs = append(errs, "Давление насыщения: требуется число или пусто")
+			}
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("Проверьте параметры:\n%s", strings.Join(errs, "\n")), s.window)
+			return
+		}
+		opts.ReservoirPressure = units.ToStorage(models.QuantityPressure, opts.ReservoirPressure)
+		if opts.BubblePointPressure != 0 {
+			opts.BubblePointPressure = units.ToStorage(models.QuantityPressure, opts.BubblePointPressure)
+		}
+
+		t1, err := s.tableOneData()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 1: %w", err), s.window)
+			return
+		}
+		t3, err := s.memStorage.GetTableThreeData()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 3: %w", err), s.window)
+			return
+		}
+		ipr, err := calc.Productivity(t1, t3, opts)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.zLog.Infow("Productivity calculated", "steps", len(ipr.Steps), "J", ipr.J, "qmax", ipr.QMax)
+		dlg.Hide()
+		s.showProductivityTable(ipr, units)
+	})
+	calcBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, calcBtn})
+	dlg.Resize(fyne.NewSize(500, 250))
+	dlg.Show()
+}
+
+// showProductivityTable показывает режимы с коэффициентами продуктивности в единицах units
+// и открывает график IPR по кнопке.
+func (s *Service) showProductivityTable(ipr models.IPR, units models.UnitSystem) {
+	pUnit, qUnit := units.Symbol(models.QuantityPressure), units.Symbol(models.QuantityLiquidRate)
+	kp, kq := units.Scale(models.QuantityPressure), units.Scale(models.QuantityLiquidRate)
+	headers := []string{"Начало", "Конец", "Qж, " + qUnit, "Рзаб, " + pUnit, "Депрессия", "Кпрод", "Замеров"}
+	cell := func(st models.ProductivityStep, col int) string {
+		switch col {
+		case 0:
+			return formatFormTime(st.Start)
+		case 1:
+			return formatFormTime(st.End)
+		case 2:
+			return fmt.Sprintf("%.2f", st.Rate*kq)
+		case 3:
+			return fmt.Sprintf("%.3f", st.Pwf*kp)
+		case 4:
+			return fmt.Sprintf("%.3f", st.Drawdown*kp)
+		case 5:
+			if math.IsNaN(st.PI) {
+				return "—"
+			}
+			return fmt.Sprintf("%.4g", st.PI*kq/kp)
+		default:
+			return strconv.Itoa(st.Samples)
+		}
+	}
+
+	table := widget.NewTable(
+		func() (int, int) { return len(ipr.Steps) + 1, len(headers) },
+		func() fyne.CanvasObject { return widget.NewLabel("00.00.0000 00:00:00") },
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			label := o.(*widget.Label)
+			if id.Row == 0 {
+				label.TextStyle = fyne.TextStyle{Bold: true}
+				label.SetText(headers[id.Col])
+				return
+			}
+			label.TextStyle = fyne.TextStyle{}
+			label.SetText(cell(ipr.Steps[id.Row-1], id.Col))
+		},
+	)
+
+	summary := widget.NewLabel(fmt.Sprintf("J = %.4g %s/%s, Qmax = %.4g %s", ipr.J*kq/kp, qUnit, pUnit, ipr.QMax*kq, qUnit))
+	chartBtn := widget.NewButton("Открыть график IPR", func() {
+		htmlPath, err := s.chart.GenerateIPRChart(ipr, units)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("ошибка генерации HTML графика: %w", err), s.window)
+			return
+		}
+		if err := s.openChart(htmlPath); err != nil {
+			dialog.ShowError(err, s.window)
+		}
+	})
+
+	dlg := dialog.NewCustom("Продуктивность по режимам", "Закрыть",
+		container.NewBorder(summary, chartBtn, nil, nil, table), s.window)
+	dlg.Resize(fyne.NewSize(1000, 500))
+	dlg.Show()
+}
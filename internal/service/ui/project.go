@@ -0,0 +1,381 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	projectService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/project"
+)
+
+// collectProject собирает активное исследование из хранилища.
+func (s *Service) collectProject() (models.Project, error) {
+	var (
+		p   models.Project
+		err error
+	)
+	if p.One, err = s.memStorage.GetTableOneData(); err != nil {
+		return p, err
+	}
+	if p.Two, err = s.memStorage.GetTableTwoData(); err != nil {
+		return p, err
+	}
+	if p.Three, err = s.memStorage.GetTableThreeData(); err != nil {
+		return p, err
+	}
+	if p.Four, err = s.memStorage.GetTableFourData(); err != nil {
+		return p, err
+	}
+	if p.Five, err = s.memStorage.GetTableFiveData(); err != nil {
+		return p, err
+	}
+	if cfg, ok, err := s.memStorage.GetOperationConfig(); err != nil {
+		return p, err
+	} else if ok {
+		p.OperationConfig = &cfg
+	}
+	if cfg, ok, err := s.memStorage.GetFilterConfig(); err != nil {
+		return p, err
+	} else if ok {
+		p.FilterConfig = &cfg
+	}
+	if p.DisplayUnits, err = s.memStorage.GetDisplayUnits(); err != nil {
+		return p, err
+	}
+	if p.Sources, err = s.memStorage.GetImportSources(); err != nil {
+		return p, err
+	}
+	if p.RowSources, err = s.memStorage.GetRowSources(); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// putProject заменяет данные и настройки активного исследования сессией из файла проекта.
+func (s *Service) putProject(p models.Project) error {
+	// данные открываются и записываются в историю одной операцией: её можно отменить целиком
+	if _, err := s.memStorage.OpenResearch(p, "Открытие проекта"); err != nil {
+		return err
+	}
+	if err := s.memStorage.PutDisplayUnits(p.DisplayUnits); err != nil {
+		return err
+	}
+	return s.refreshTableOneChart()
+}
+
+// setProjectPath запоминает файл проекта активного исследования и показывает его имя в заголовке окна.
+func (s *Service) setProjectPath(path string) {
+	id := s.memStorage.ActiveResearch().ID
+	s.projectMu.Lock()
+	if path == "" {
+		delete(s.projectPaths, id)
+	} else {
+		s.projectPaths[id] = path
+	}
+	s.projectMu.Unlock()
+	s.updateTitle()
+}
+
+// currentProjectPath возвращает файл проекта активного исследования; пусто — оно ещё не сохранялось.
+func (s *Service) currentProjectPath() string {
+	id := s.memStorage.ActiveResearch().ID
+	s.projectMu.Lock()
+	defer s.projectMu.Unlock()
+	return s.projectPaths[id]
+}
+
+// updateTitle показывает в заголовке окна активное исследование и его файл проекта.
+func (s *Service) updateTitle() {
+	title := s.appName + " — " + s.memStorage.ActiveResearch().Name
+	if path := s.currentProjectPath(); path != "" {
+		title += " — " + filepath.Base(path)
+	}
+	s.window.SetTitle(title)
+}
+
+// saveProject сохраняет сессию в файл текущего проекта; если его ещё нет — спрашивает, куда сохранить.
+func (s *Service) saveProject() {
+	path := s.currentProjectPath()
+	if path == "" {
+		s.saveProjectAs()
+		return
+	}
+	s.writeProject(path)
+}
+
+// saveProjectAs спрашивает папку и имя файла и сохраняет в него сессию; файл становится текущим проектом.
+// Диалог сохранения Fyne открывает выбранный файл на запись и тем самым обнуляет его ещё до записи
+// проекта, поэтому папка выбирается отдельно, а имя вводится в форме: до успешного сохранения
+// существующий файл не трогается.
+func (s *Service) saveProjectAs() {
+	name := fileSafeName(s.memStorage.ActiveResearch().Name) + models.ProjectExtension
+	d := dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if dir == nil {
+			return
+		}
+		s.askProjectName(dir.Path(), name)
+	}, s.window)
+	if path := s.currentProjectPath(); path != "" {
+		name = filepath.Base(path)
+		if dir, err := storage.ListerForURI(storage.NewFileURI(filepath.Dir(path))); err == nil {
+			d.SetLocation(dir)
+		}
+	}
+	d.SetConfirmText("Выбрать папку")
+	d.Show()
+}
+
+// askProjectName спрашивает имя файла проекта в папке dir и, если такой файл уже есть, подтверждение замены.
+func (s *Service) askProjectName(dir, name string) {
+	entry := widget.NewEntry()
+	entry.SetText(name)
+	dialog.ShowForm("Сохранение проекта", "Сохранить", "Отмена",
+		[]*widget.FormItem{widget.NewFormItem("Имя файла", entry)},
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			name := strings.TrimSpace(entry.Text)
+			if name == "" || strings.ContainsAny(name, `/\`) {
+				dialog.ShowError(fmt.Errorf("недопустимое имя файла %q", entry.Text), s.window)
+				return
+			}
+			if !strings.EqualFold(filepath.Ext(name), models.ProjectExtension) {
+				name += models.ProjectExtension
+			}
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err != nil {
+				s.writeProject(path)
+				return
+			}
+			dialog.ShowConfirm("Сохранение проекта", fmt.Sprintf("Файл %s уже существует. Заменить его?", name),
+				func(ok bool) {
+					if ok {
+						s.writeProject(path)
+					}
+				}, s.window)
+		}, s.window)
+}
+
+func (s *Service) writeProject(path string) {
+	p, err := s.collectProject()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось собрать данные проекта: %w", err), s.window)
+		return
+	}
+	s.runWithProgress("Сохранение проекта...", func() error {
+		return s.project.Save(path, p)
+	}, func() {
+		s.setProjectPath(path)
+		s.zLog.Infow("Project saved", "path", path, "rows", p.Rows())
+		dialog.ShowInformation("Проект сохранён", path, s.window)
+	})
+}
+
+// openProject спрашивает файл проекта и открывает его вместо текущих данных.
+func (s *Service) openProject() {
+	d := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if r == nil {
+			return
+		}
+		path := r.URI().Path()
+		r.Close()
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		s.confirmReplace(name, func() { s.loadProject(path, true) })
+	}, s.window)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{models.ProjectExtension}))
+	d.Show()
+}
+
+// restoreAutosave открывает файл автосохранения. Он не становится текущим проектом,
+// чтобы следующее «Сохранить» спросило, куда сохранить восстановленную сессию.
+func (s *Service) restoreAutosave() {
+	path, err := projectService.RecoveryPath()
+	if err != nil {
+		dialog.ShowError(err, s.window)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		dialog.ShowInformation("Автосохранение", "Файл автосохранения не найден", s.window)
+		return
+	}
+	dialog.ShowConfirm("Автосохранение",
+		fmt.Sprintf("Восстановить сессию, автосохранённую %s?", info.ModTime().Format(time.DateTime)),
+		func(ok bool) {
+			if ok {
+				s.confirmReplace("Автосохранение", func() { s.loadProject(path, false) })
+			}
+		}, s.window)
+}
+
+// confirmReplace готовит активное исследование к открытию данных под названием name и вызывает next.
+// Пустое исследование занимается сразу; если в активном уже есть данные, пользователь выбирает:
+// открыть в новом исследовании рядом с текущим или заменить данные активного.
+func (s *Service) confirmReplace(name string, next func()) {
+	if s.importBusy("Открытие") {
+		return
+	}
+	active := s.memStorage.ActiveResearch()
+	if active.Total() == 0 {
+		if err := s.memStorage.RenameResearch(active.ID, name); err != nil {
+			s.zLog.Errorw("Research not renamed", "id", active.ID, "error", err)
+		}
+		next()
+		return
+	}
+	dlg := dialog.NewCustomWithoutButtons("Открытие",
+		widget.NewLabel(fmt.Sprintf("В исследовании «%s» уже есть данные.\nОткрыть в новом исследовании или заменить его данные?", active.Name)),
+		s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	replaceBtn := widget.NewButton("Заменить", func() {
+		dlg.Hide()
+		if err := s.memStorage.RenameResearch(active.ID, name); err != nil {
+			s.zLog.Errorw("Research not renamed", "id", active.ID, "error", err)
+		}
+		s.setProjectPath("")
+		next()
+	})
+	newBtn := widget.NewButton("Новое исследование", func() {
+		dlg.Hide()
+		if _, err := s.memStorage.CreateResearch(name); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось создать исследование: %w", err), s.window)
+			return
+		}
+		s.updateTitle()
+		next()
+	})
+	newBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, replaceBtn, newBtn})
+	dlg.Show()
+}
+
+// loadProject читает файл проекта и кладёт его в хранилище; current — сделать файл текущим проектом.
+func (s *Service) loadProject(path string, current bool) {
+	var p models.Project
+	s.runWithProgress("Открытие проекта...", func() error {
+		var err error
+		p, err = s.project.Open(path)
+		return err
+	}, func() {
+		if err := s.putProject(p); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось открыть проект: %w", err), s.window)
+			return
+		}
+		if current {
+			s.setProjectPath(path)
+		} else {
+			s.setProjectPath("")
+		}
+		s.zLog.Infow("Project opened", "path", path, "rows", p.Rows(), "saved_at", p.SavedAt)
+		dialog.ShowInformation("Проект открыт", formatProjectSummary(p), s.window)
+	})
+}
+
+// startAutosave раз в interval сохраняет активное исследование в файл восстановления, если оно изменилось.
+// Файл проекта пользователя автосохранение не трогает.
+func (s *Service) startAutosave(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	path, err := projectService.RecoveryPath()
+	if err != nil {
+		s.zLog.Errorw("Autosave disabled", "error", err)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := ""
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			active := s.memStorage.ActiveResearch()
+			sig := projectSignature(active)
+			if active.Total() == 0 || sig == last {
+				continue
+			}
+			p, err := s.collectProject()
+			if err != nil {
+				s.zLog.Errorw("Autosave failed", "error", err)
+				continue
+			}
+			if err := s.project.Save(path, p); err != nil {
+				s.zLog.Errorw("Autosave failed", "path", path, "error", err)
+				continue
+			}
+			last = sig
+			s.zLog.Debugw("Session autosaved", "path", path, "rows", p.Rows())
+		}
+	}()
+}
+
+// projectSignature — дешёвый признак изменения сессии: активное исследование и счётчик его изменений.
+// Размеров блоков мало: правка значения, её отмена или повторный импорт файла того же размера
+// меняют замеры, не меняя числа строк.
+func projectSignature(r models.ResearchSummary) string {
+	return fmt.Sprintf("%d/%d", r.ID, r.Revision)
+}
+
+func formatProjectSummary(p models.Project) string {
+	lines := []string{
+		fmt.Sprintf("Сохранён: %s", p.SavedAt.Local().Format(time.DateTime)),
+		fmt.Sprintf("Блок 1: %d, Блок 2: %d, Блок 3: %d, Блок 4: %d строк", len(p.One), len(p.Two), len(p.Three), len(p.Four)),
+	}
+	if p.Five.FieldName != "" {
+		lines = append(lines, fmt.Sprintf("Отчёт: %s, скв. %d", p.Five.FieldName, p.Five.FieldNumber))
+	}
+	if len(p.Sources) > 0 {
+		lines = append(lines, "Импортированные файлы:")
+		for _, src := range p.Sources {
+			lines = append(lines, fmt.Sprintf("• %s: %s (%d строк)", src.Block, filepath.Base(src.File), src.Rows))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showProjectView показывает действия с файлом проекта.
+func (s *Service) showProjectView(ctx context.Context) {
+	back := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
+	current := "Проект ещё не сохранён"
+	if path := s.currentProjectPath(); path != "" {
+		current = "Текущий проект: " + path
+	}
+	// в файл проекта попадает одно исследование — активное
+	current = "Исследование: " + s.memStorage.ActiveResearch().Title() + "\n" + current
+	openBtn := widget.NewButton("Открыть…", s.openProject)
+	restoreBtn := widget.NewButton("Восстановить автосохранение", s.restoreAutosave)
+	s.importProgress.lockWhileRunning(openBtn, restoreBtn)
+	s.window.SetContent(container.NewBorder(back, nil, nil, nil,
+		container.NewVBox(
+			widget.NewLabel("Файл проекта"),
+			widget.NewLabel(current),
+			widget.NewSeparator(),
+			widget.NewButton("Сохранить", s.saveProject),
+			widget.NewButton("Сохранить как…", s.saveProjectAs),
+			openBtn,
+			restoreBtn,
+		),
+	))
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+var (
+	gridModes    = []models.GridMode{models.GridFixedStep, models.GridUnion}
+	aggregations = []models.Aggregation{
+		models.AggregationMean, models.AggregationLast, models.AggregationLinear, models.AggregationStepHold,
+	}
+	// rateChannels — величины блока 3: запись действует до следующей, поэтому по умолчанию удерживаются
+	rateChannels = []models.Channel{
+		models.ChannelLiquidFlowRate, models.ChannelWaterCut, models.ChannelGasFlowRate,
+		models.ChannelOilFlowRate, models.ChannelWaterFlowRate, models.ChannelGasFactor,
+	}
+)
+
+// showResampleForm запрашивает параметры общей сетки, выводит на неё блоки 1–3
+// и строит сводный график или сохраняет таблицу в Excel.
+func (s *Service) showResampleForm() {
+	gridTitles := make([]string, len(gridModes))
+	for i, m := range gridModes {
+		gridTitles[i] = m.Title()
+	}
+	aggTitles := make([]string, len(aggregations))
+	for i, a := range aggregations {
+		aggTitles[i] = a.Title()
+	}
+
+	stepEntry := widget.NewEntry()
+	stepEntry.SetText("1")
+	gridSelect := widget.NewSelect(gridTitles, func(title string) {
+		if title == models.GridUnion.Title() {
+			stepEntry.Disable()
+		} else {
+			stepEntry.Enable()
+		}
+	})
+	gridSelect.SetSelectedIndex(0)
+	aggSelect := widget.NewSelect(aggTitles, nil)
+	aggSelect.SetSelectedIndex(0)
+	holdRatesCheck := widget.NewCheck("Дебиты блока 3 — удержание значения до следующей записи", nil)
+	holdRatesCheck.SetChecked(true)
+	maxGapEntry := widget.NewEntry()
+	maxGapEntry.PlaceHolder = "без ограничения"
+	dropEmptyCheck := widget.NewCheck("Убрать узлы без данных", nil)
+	dropEmptyCheck.SetChecked(true)
+
+	form := widget.NewForm(
+		widget.NewFormItem("Сетка", gridSelect),
+		widget.NewFormItem("Шаг, мин", stepEntry),
+		widget.NewFormItem("Агрегация", aggSelect),
+		widget.NewFormItem("", holdRatesCheck),
+		widget.NewFormItem("Макс. разрыв, мин", maxGapEntry),
+		widget.NewFormItem("", dropEmptyCheck),
+	)
+
+	// frame собирает параметры и выравнивает блоки; ошибки показываются здесь же
+	frame := func() (models.Frame, bool) {
+		opts := models.ResampleOptions{
+			Grid:        gridModes[gridSelect.SelectedIndex()],
+			Aggregation: aggregations[aggSelect.SelectedIndex()],
+			DropEmpty:   dropEmptyCheck.Checked,
+		}
+		var errs []string
+		if opts.Grid == models.GridFixedStep {
+			step, err := parseMinutes(stepEntry.Text)
+			if err != nil || step <= 0 {
+				errs = append(errs, "Шаг: требуется положительное число минут")
+			}
+			opts.Step = step
+		}
+		if text := strings.TrimSpace(maxGapEntry.Text); text != "" {
+			maxGap, err := parseMinutes(text)
+			if err != nil || maxGap < 0 {
+				errs = append(errs, "Макс. разрыв: требуется число минут или пусто")
+			}
+			opts.MaxGap = maxGap
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("Проверьте параметры:\n%s", strings.Join(errs, "\n")), s.window)
+			return models.Frame{}, false
+		}
+		if holdRatesCheck.Checked {
+			opts.Overrides = make(map[models.Channel]models.Aggregation, len(rateChannels))
+			for _, ch := range rateChannels {
+				opts.Overrides[ch] = models.AggregationStepHold
+			}
+		}
+
+		t1, err := s.tableOneData()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 1: %w", err), s.window)
+			return models.Frame{}, false
+		}
+		t2, err := s.tableTwoData()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 2: %w", err), s.window)
+			return models.Frame{}, false
+		}
+		t3, err := s.memStorage.GetTableThreeData()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 3: %w", err), s.window)
+			return models.Frame{}, false
+		}
+		if len(t1)+len(t2)+len(t3) == 0 {
+			dialog.ShowInformation("Нет данных", "Импортируйте хотя бы один из блоков 1–3", s.window)
+			return models.Frame{}, false
+		}
+		f, err := s.resampler.Blocks(t1, t2, t3, opts)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return models.Frame{}, false
+		}
+		s.zLog.Infow("Blocks resampled", "grid", opts.Grid, "step", opts.Step, "aggregation", opts.Aggregation, "nodes", f.Len())
+		return f, true
+	}
+
+	dlg := dialog.NewCustomWithoutButtons("Сводный ряд блоков 1–3", form, s.window)
+	cancelBtn := widget.NewButton("Закрыть", dlg.Hide)
+	chartBtn := widget.NewButton("Построить график", func() {
+		f, ok := frame()
+		if !ok {
+			return
+		}
+		htmlPath, err := s.chart.GenerateFrameChart(f, s.displayUnits())
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("ошибка генерации HTML графика: %w", err), s.window)
+			return
+		}
+		if err := s.openChart(htmlPath); err != nil {
+			dialog.ShowError(err, s.window)
+		}
+	})
+	chartBtn.Importance = widget.HighImportance
+	saveBtn := widget.NewButton("Сохранить в Excel", func() {
+		f, ok := frame()
+		if !ok {
+			return
+		}
+		buf, err := s.archiver.FrameXLSX(f, s.displayUnits())
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		d := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, s.window)
+				return
+			}
+			if w == nil {
+				return
+			}
+			defer w.Close()
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				dialog.ShowError(fmt.Errorf("не удалось записать файл: %w", err), s.window)
+				return
+			}
+			dialog.ShowInformation("Сохранено", fmt.Sprintf("Узлов сетки: %d\n%s", f.Len(), w.URI().Path()), s.window)
+		}, s.window)
+		d.SetFileName("aligned_blocks_1_3.xlsx")
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".xlsx"}))
+		d.Show()
+	})
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn, chartBtn})
+	dlg.Resize(fyne.NewSize(560, 360))
+	dlg.Show()
+}
+
+// parseMinutes разбирает число минут, допускается десятичная запятая.
+func parseMinutes(text string) (time.Duration, error) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(v * float64(time.Minute)), nil
+}
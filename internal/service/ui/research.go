@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// runWithProgress выполняет work в горутине, пока открыт диалог ожидания с заголовком title.
+// done вызывается в главном потоке Fyne после закрытия диалога, если work не вернул ошибку.
+func (s *Service) runWithProgress(title string, work func() error, done func()) {
+	progress := dialog.NewCustomWithoutButtons(title, widget.NewProgressBarInfinite(), s.window)
+	progress.Show()
+	go func() {
+		err := work()
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, s.window)
+				return
+			}
+			done()
+		})
+	}()
+}
+
+// saveResearch сохраняет импортированные блоки 1–4 и график работы в БД к отчёту текущего исследования,
+// заменяя ранее сохранённые. Отчёт (Блок 5) должен быть уже сохранён: его ID связывает блоки.
+func (s *Service) saveResearch(ctx context.Context) {
+	report, err := s.memStorage.GetTableFiveData()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить шапку отчёта: %w", err), s.window)
+		return
+	}
+	if report.ID == 0 {
+		dialog.ShowInformation("Нет отчёта", "Сначала заполните и сохраните шапку отчёта (Блок 5)", s.window)
+		return
+	}
+
+	research := models.Research{Report: report}
+	// сохраняются исходные замеры: очистка и Рзаб на ВДП по графику работы пересчитываются после открытия
+	if research.One, err = s.memStorage.GetTableOneData(); err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 1: %w", err), s.window)
+		return
+	}
+	if research.Two, err = s.memStorage.GetTableTwoData(); err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 2: %w", err), s.window)
+		return
+	}
+	if research.Three, err = s.memStorage.GetTableThreeData(); err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 3: %w", err), s.window)
+		return
+	}
+	if research.Four, err = s.memStorage.GetTableFourData(); err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 4: %w", err), s.window)
+		return
+	}
+	if research.Rows() == 0 {
+		dialog.ShowInformation("Нет данных", "Импортируйте хотя бы один из блоков 1–4", s.window)
+		return
+	}
+	if cfg, ok, err := s.memStorage.GetOperationConfig(); err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить график работы: %w", err), s.window)
+		return
+	} else if ok {
+		research.Operation = &cfg
+	}
+
+	s.runWithProgress("Сохранение исследования...", func() error {
+		if err := s.db.SaveResearch(ctx, int64(report.ID), research); err != nil {
+			return fmt.Errorf("не удалось сохранить исследование: %w", err)
+		}
+		return nil
+	}, func() {
+		s.zLog.Infow("Research saved", "id", report.ID, "rows", research.Rows())
+		dialog.ShowInformation("Сохранено", fmt.Sprintf("Отчёт %d: %s", report.ID, formatResearchRows(research)), s.window)
+	})
+}
+
+// showResearchList показывает сохранённые в БД исследования: выбранное можно открыть или удалить.
+func (s *Service) showResearchList(ctx context.Context) {
+	reports, err := s.db.GetAllReports(ctx)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить список отчётов: %w", err), s.window)
+		return
+	}
+	if len(reports) == 0 {
+		dialog.ShowInformation("Исследования", "В базе нет сохранённых отчётов", s.window)
+		return
+	}
+
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(reports) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(formatReportTitle(reports[id]))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	dlg := dialog.NewCustomWithoutButtons("Сохранённые исследования", container.NewStack(list), s.window)
+	closeBtn := widget.NewButton("Закрыть", dlg.Hide)
+	deleteBtn := widget.NewButton("Удалить", func() {
+		if selected < 0 {
+			return
+		}
+		report := reports[selected]
+		dialog.ShowConfirm("Удаление", fmt.Sprintf("Удалить из базы %s вместе с блоками 1–4?", formatReportTitle(report)),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := s.db.DeleteResearch(ctx, int64(report.ID)); err != nil {
+					dialog.ShowError(fmt.Errorf("не удалось удалить исследование: %w", err), s.window)
+					return
+				}
+				s.zLog.Infow("Research deleted", "id", report.ID)
+				reports = append(reports[:selected], reports[selected+1:]...)
+				selected = -1
+				list.UnselectAll()
+				list.Refresh()
+			}, s.window)
+	})
+	openBtn := widget.NewButton("Открыть", func() {
+		if selected < 0 {
+			return
+		}
+		id := reports[selected].ID
+		dlg.Hide()
+		s.openResearch(ctx, int64(id))
+	})
+	openBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{closeBtn, deleteBtn, openBtn})
+	dlg.Resize(fyne.NewSize(640, 420))
+	dlg.Show()
+}
+
+// openResearch загружает исследование reportID из БД в память: в новое исследование или вместо активного.
+func (s *Service) openResearch(ctx context.Context, reportID int64) {
+	load := func() {
+		var research models.Research
+		s.runWithProgress("Загрузка исследования...", func() error {
+			var err error
+			if research, err = s.db.LoadResearch(ctx, reportID); err != nil {
+				return fmt.Errorf("не удалось загрузить исследование: %w", err)
+			}
+			return nil
+		}, func() {
+			if err := s.putResearch(research); err != nil {
+				dialog.ShowError(fmt.Errorf("не удалось открыть исследование: %w", err), s.window)
+				return
+			}
+			s.zLog.Infow("Research opened", "id", reportID, "rows", research.Rows())
+			msg := formatResearchRows(research)
+			if len(research.One) > 0 && research.Operation == nil {
+				// исследование сохранено без графика работы: Рзаб на ВДП посчитать не по чему
+				msg += "\n\nЗадайте график работы и гидростатику, чтобы рассчитать Рзаб на ВДП."
+			}
+			dialog.ShowInformation(formatReportTitle(research.Report), msg, s.window)
+		})
+	}
+
+	s.confirmReplace(fmt.Sprintf("Отчёт №%d", reportID), load)
+}
+
+// putResearch заменяет данные активного исследования блоками, отчётом и графиком работы из БД.
+func (s *Service) putResearch(r models.Research) error {
+	p := models.Project{One: r.One, Two: r.Two, Three: r.Three, Four: r.Four, Five: r.Report, OperationConfig: r.Operation}
+	// данные открываются и записываются в историю одной операцией: её можно отменить целиком
+	if _, err := s.memStorage.OpenResearch(p, fmt.Sprintf("Открытие отчёта №%d из БД", r.Report.ID)); err != nil {
+		return err
+	}
+	return s.refreshTableOneChart()
+}
+
+// formatReportTitle описывает отчёт одной строкой для списков и заголовков.
+func formatReportTitle(r models.TableFive) string {
+	return fmt.Sprintf("№%d: %s, скв. %d, %s, %s — %s", r.ID, r.FieldName, r.FieldNumber, r.ResearchType,
+		r.StartTime.Format("2006-01-02"), r.EndTime.Format("2006-01-02"))
+}
+
+func formatResearchRows(r models.Research) string {
+	return fmt.Sprintf("Блок 1: %d, Блок 2: %d, Блок 3: %d, Блок 4: %d строк", len(r.One), len(r.Two), len(r.Three), len(r.Four))
+}
@@ -6,21 +6,23 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/cockroachdb/errors"
 	archiverService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/export/archiver"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/export/minioExporter"
+	importerService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/importer"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/validation"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/config"
@@ -33,14 +35,41 @@ import (
 )
 
 type importer interface {
-	ParseBlockOneFile(path string, cfg models.OperationConfig) ([]models.TableOne, error)
-	ParseBlockTwoFile(path string) ([]models.TableTwo, error)
-	ParseBlockThreeFile(path string) ([]models.TableThree, error)
-	ParseBlockFourFile(path string) ([]models.TableFour, error)
+	StreamBlockOneFile(ctx context.Context, path string,
+		opts models.ImportOptions, sink func([]models.TableOne) error) (models.ImportReport, error)
+	StreamBlockTwoFile(ctx context.Context, path string,
+		opts models.ImportOptions, sink func([]models.TableTwo) error) (models.ImportReport, error)
+	StreamBlockThreeFile(ctx context.Context, path string,
+		opts models.ImportOptions, sink func([]models.TableThree) error) (models.ImportReport, error)
+	StreamBlockFourFile(ctx context.Context, path string,
+		opts models.ImportOptions, sink func([]models.TableFour) error) (models.ImportReport, error)
+	StreamSurveyFile(ctx context.Context, path string,
+		opts models.ImportOptions, sink func([]models.SurveyStation) error) (models.ImportReport, error)
+	FileHash(path string) (string, error)
+}
+
+type periodDetector interface {
+	Detect(t1 []models.TableOne, t3 []models.TableThree) []models.PeriodCandidate
+}
+
+type signalFilter interface {
+	TableOne(data []models.TableOne, opts models.FilterOptions) ([]models.TableOne, models.FilterReport, error)
+	TableTwo(data []models.TableTwo, opts models.FilterOptions) ([]models.TableTwo, models.FilterReport, error)
+}
+
+type projectStore interface {
+	Save(path string, p models.Project) error
+	Open(path string) (models.Project, error)
+}
+
+type resampler interface {
+	Blocks(t1 []models.TableOne, t2 []models.TableTwo, t3 []models.TableThree, opts models.ResampleOptions) (models.Frame, error)
+	Series(t1 []models.TableOne, t2 []models.TableTwo, t3 []models.TableThree, ch models.Channel) (models.Series, bool)
 }
 
 type converterService interface {
 	ParseFlexibleTime(raw string) (time.Time, error)
+	ParseFlexibleTimeIn(raw string, loc *time.Location) (time.Time, error)
 }
 
 type archiver interface { // Локальное определение интерфейса, чтобы было понятно, чего мы ожидаем
@@ -50,6 +79,7 @@ type archiver interface { // Локальное определение инте
 		t3 []models.TableThree,
 		t4 []models.TableFour,
 		t5 models.TableFive,
+		units models.UnitSystem,
 	) (*bytes.Buffer, error)
 }
 
@@ -61,6 +91,10 @@ type Service struct {
 	memStorage       inmemoryStorage.InMemoryBlocksStorage
 	db               *database.Service
 	converter        converterService
+	periods          periodDetector
+	resampler        resampler
+	filter           signalFilter
+	project          projectStore
 	chart            chartService.Service
 	archiver         archiverService.Archiver
 	exporter         *minioExporter.Client
@@ -68,13 +102,30 @@ type Service struct {
 	serverListener   net.Listener
 	serverPort       string
 	chartHtmlToServe string
-
-	loadingLabel *widget.Label
-	progressBar  *widget.ProgressBarInfinite
+	chartVersion     int                    // растёт при каждой смене раздаваемого файла: по нему страница перезагружается
+	chartBlocks      models.DataBlocks      // от каких данных зависит раздаваемый график
+	chartGenerate    func() (string, error) // перестраивает раздаваемый график; nil — график не обновляется
+	chartStale       bool                   // данные графика изменились, а перестроить его ещё нельзя
+	chartBuilding    bool                   // график перестраивается в фоне
+
+	live         liveView // открытый экран, обновляемый при изменении данных
+	liveMu       sync.Mutex
+	liveBlocks   models.DataBlocks // изменения, ещё не переданные в главный поток
+	liveResearch int               // активное исследование на момент последнего обновления
+
+	loadingLabel   *widget.Label
+	progressBar    *widget.ProgressBarInfinite
+	importProgress *importProgress
+	timeCorrection models.TimeCorrection // последняя поправка времени: подставляется в форму следующего импорта
+
+	appName      string
+	autosave     time.Duration
+	projectMu    sync.Mutex
+	projectPaths map[int]string // файл проекта по ID исследования; нет записи — исследование ещё не сохранялось
 }
 
-func NewService(cfg config.UI, zLog logger.Logger, imp importer, converter converterService,
-	memBlocksStorage inmemoryStorage.InMemoryBlocksStorage, db *database.Service, chart chartService.Service,
+func NewService(cfg config.UI, zLog logger.Logger, imp importer, converter converterService, periods periodDetector,
+	resampler resampler, filter signalFilter, project projectStore, memBlocksStorage inmemoryStorage.InMemoryBlocksStorage, db *database.Service, chart chartService.Service,
 	archiver archiverService.Archiver, exporter *minioExporter.Client) *Service {
 
 	a := app.New()
@@ -87,18 +138,26 @@ func NewService(cfg config.UI, zLog logger.Logger, imp importer, converter conve
 	progressBr.Hide()
 
 	return &Service{
-		app:          a,
-		window:       win,
-		zLog:         zLog,
-		importer:     imp,
-		memStorage:   memBlocksStorage,
-		db:           db,
-		converter:    converter,
-		chart:        chart,
-		archiver:     archiver,
-		exporter:     exporter,
-		loadingLabel: loadingLbl,
-		progressBar:  progressBr,
+		app:            a,
+		window:         win,
+		zLog:           zLog,
+		importer:       imp,
+		memStorage:     memBlocksStorage,
+		db:             db,
+		converter:      converter,
+		periods:        periods,
+		resampler:      resampler,
+		filter:         filter,
+		project:        project,
+		chart:          chart,
+		archiver:       archiver,
+		exporter:       exporter,
+		loadingLabel:   loadingLbl,
+		progressBar:    progressBr,
+		importProgress: newImportProgress(),
+		appName:        cfg.Name,
+		autosave:       time.Duration(cfg.AutosaveMinutes) * time.Minute,
+		projectPaths:   make(map[int]string),
 	}
 }
 
@@ -121,6 +180,7 @@ func (s *Service) startLocalWebServer() error {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.serveChartHTML)
+	mux.HandleFunc("/version", s.serveChartVersion)
 
 	go func() {
 		if err := http.Serve(s.serverListener, mux); err != nil && err != http.ErrServerClosed {
@@ -137,14 +197,41 @@ func (s *Service) startLocalWebServer() error {
 
 func (s *Service) serveChartHTML(w http.ResponseWriter, r *http.Request) {
 	s.serverMutex.Lock()
-	html := s.chartHtmlToServe
+	html, version := s.chartHtmlToServe, s.chartVersion
 	s.serverMutex.Unlock()
 
 	if html == "" {
 		http.Error(w, "График еще не сгенерирован", http.StatusNotFound)
 		return
 	}
-	http.ServeFile(w, r, html)
+	page, err := os.ReadFile(html)
+	if err != nil {
+		http.Error(w, "Не удалось прочитать график", http.StatusInternalServerError)
+		return
+	}
+	// страница опрашивает /version и перезагружается, когда график перестроен
+	reload := fmt.Sprintf(liveReloadScript, version)
+	if i := bytes.LastIndex(page, []byte("</body>")); i >= 0 {
+		page = append(page[:i:i], append([]byte(reload), page[i:]...)...)
+	} else {
+		page = append(page, reload...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(page)
+}
+
+// liveReloadScript перезагружает страницу графика, когда номер версии на сервере меняется.
+const liveReloadScript = `<script>(function(){var v="%d";setInterval(function(){
+fetch("/version",{cache:"no-store"}).then(function(r){return r.text()}).then(function(t){if(t!==v){location.reload()}}).catch(function(){})
+},2000)})();</script>`
+
+func (s *Service) serveChartVersion(w http.ResponseWriter, r *http.Request) {
+	s.serverMutex.Lock()
+	version := s.chartVersion
+	s.serverMutex.Unlock()
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprint(w, version)
 }
 
 // --- Навигация между разделами ---
@@ -160,6 +247,20 @@ func (s *Service) Run(ctx context.Context) error {
 		s.serverMutex.Unlock()
 	})
 
+	s.window.Canvas().AddShortcut(
+		&desktop.CustomShortcut{KeyName: fyne.KeyS, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { s.saveProject() })
+	s.window.Canvas().AddShortcut(
+		&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { s.undo() })
+	s.window.Canvas().AddShortcut(
+		&desktop.CustomShortcut{KeyName: fyne.KeyY, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) { s.redo() })
+	s.startAutosave(ctx, s.autosave)
+
+	s.updateTitle()
+	// экраны и открытые графики обновляются сами, когда меняются данные
+	defer s.watchStorage()()
 	s.showMainMenu(ctx)
 	s.window.ShowAndRun()
 	return nil
@@ -167,7 +268,8 @@ func (s *Service) Run(ctx context.Context) error {
 
 // --- Построение содержимого импортов ---
 
-func (s *Service) buildImportContent(ctx context.Context) fyne.CanvasObject {
+// buildImportContent возвращает экран импорта и функцию, обновляющую его сводку по исследованию.
+func (s *Service) buildImportContent(ctx context.Context) (fyne.CanvasObject, func(models.DataBlocks)) {
 	// 1) путь
 	pathEntry := widget.NewEntry()
 	pathEntry.PlaceHolder = "Файл не выбран"
@@ -183,15 +285,21 @@ func (s *Service) buildImportContent(ctx context.Context) fyne.CanvasObject {
 			defer r.Close()
 			pathEntry.SetText(r.URI().Path())
 		}, s.window)
-		d.SetFilter(storage.NewExtensionFileFilter([]string{".xlsx"}))
+		d.SetFilter(storage.NewExtensionFileFilter(importerService.SupportedExtensions()))
 		d.Show()
 	})
 
 	// 2) тип документа
-	docTypes := []string{"TableOne", "TableTwo", "TableThree", "TableFour"}
+	docTypes := []string{"TableOne", "TableTwo", "TableThree", "TableFour", surveyDocType}
 	typeSelect := widget.NewSelect(docTypes, nil)
 	typeSelect.PlaceHolder = "Выберите тип документа"
 
+	strictCheck := widget.NewCheck("Строгий режим: прервать импорт на первой ошибочной строке", nil)
+	timeCheck := widget.NewCheck("Поправка времени прибора: часовой пояс, сдвиг, дрейф часов", nil)
+	// единицы файла; данные переводятся в единицы хранения при импорте
+	fileUnits := newUnitSystemForm(models.StorageUnits,
+		models.QuantityPressure, models.QuantityTemperature, models.QuantityLiquidRate, models.QuantityGasRate)
+
 	// 3) Import
 	importBtn := widget.NewButton("Import", func() {
 		path := pathEntry.Text
@@ -200,16 +308,35 @@ func (s *Service) buildImportContent(ctx context.Context) fyne.CanvasObject {
 			dialog.ShowInformation("Ошибка", "Сначала выберите файл и тип документа", s.window)
 			return
 		}
-		if typ == "TableOne" {
-			showTableOneForm(s, path)
-		} else {
-			s.doGenericImport(path, typ)
+		start := func(opts models.ImportOptions) {
+			s.checkImportSource(importJob{typ: typ, path: path}, func(job importJob) { s.startImport(ctx, job, opts) })
+		}
+		opts := models.ImportOptions{Mode: importMode(strictCheck.Checked), Units: fileUnits.units()}
+		// в инклинометрии нет меток времени
+		if timeCheck.Checked && typ != "TableFour" && typ != surveyDocType {
+			s.askTimeCorrection(filepath.Base(path), func(tc models.TimeCorrection) {
+				opts.Time = &tc
+				start(opts)
+			})
+			return
 		}
+		start(opts)
 	})
 
+	// Параметры гидростатики и график работы можно поправить в любой момент без повторного импорта
+	opConfigBtn := widget.NewButton("График работы и гидростатика (Блок 1)", s.editOperationConfig)
+	// Очистка тоже не меняет импортированные замеры: её можно настроить и отключить в любой момент
+	filterBtn := widget.NewButton("Очистка сигнала (Блоки 1 и 2)", s.editFilterConfig)
+	historyBtn := widget.NewButton("История изменений: отмена, повтор, удаление файла", s.showHistory)
+	sourcesBtn := widget.NewButton("Импортированные файлы: удаление и повторный импорт", func() { s.showImportSources(ctx) })
+
 	// 4) Очистка хранилища
-	clearBtn := widget.NewButton("Очистить хранилище", func() {
-		dialog.ShowConfirm("Подтверждение", "Удалить все данные?", func(ok bool) {
+	clearBtn := widget.NewButton("Очистить исследование", func() {
+		if s.importBusy("Очистка") {
+			return
+		}
+		msg := fmt.Sprintf("Удалить все данные исследования «%s»?", s.memStorage.ActiveResearch().Name)
+		dialog.ShowConfirm("Подтверждение", msg, func(ok bool) {
 			if !ok {
 				return
 			}
@@ -217,7 +344,8 @@ func (s *Service) buildImportContent(ctx context.Context) fyne.CanvasObject {
 				dialog.ShowError(fmt.Errorf("ошибка очистки: %w", err), s.window)
 				return
 			}
-			dialog.ShowInformation("Очищено", "Данные удалены", s.window)
+			s.recordChange(models.ChangeClear, "Очистка исследования")
+			dialog.ShowInformation("Очищено", "Данные удалены. Очистку можно отменить в истории изменений", s.window)
 		}, s.window)
 	})
 
@@ -257,23 +385,44 @@ func (s *Service) buildImportContent(ctx context.Context) fyne.CanvasObject {
 
 	*/
 
+	s.importProgress.lockWhileRunning(clearBtn)
+
+	// данные попадают в активное исследование; переключить его можно в разделе «Исследования»
+	researchLabel := widget.NewLabel("")
+	refresh := func(models.DataBlocks) {
+		r := s.memStorage.ActiveResearch()
+		r.Active = false // здесь оно всегда активное, отметка в строке не нужна
+		researchLabel.SetText("Исследование: " + formatResearchSummary(r))
+	}
+	refresh(models.DataAll)
+
 	// Собираем всё в VBox
 	return container.NewVBox(
 		widget.NewLabel("Импорт данных"),
+		researchLabel,
 		widget.NewSeparator(),
 		widget.NewLabel("1. Выберите файл и тип:"),
 		container.New(&ratioLayout{ratio: 0.7}, pathEntry, chooseBtn),
 		typeSelect,
+		strictCheck,
+		timeCheck,
+		widget.NewLabel("Единицы в файле (единица из заголовка колонки, например «Рзаб, бар», имеет приоритет):"),
+		fileUnits.grid(),
 		widget.NewSeparator(),
 		widget.NewLabel("2. Действия:"),
 		importBtn,
+		opConfigBtn,
+		filterBtn,
+		sourcesBtn,
+		historyBtn,
 		clearBtn,
 		widget.NewSeparator(),
 		//	archiveBtn,
 		archiveStatusLabel,
+		s.importProgress.box,
 		s.loadingLabel,
 		s.progressBar,
-	)
+	), refresh
 }
 
 // --- Далее ваши методы ---
@@ -300,129 +449,6 @@ func (r *ratioLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
 	return fyne.NewSize(0, h)
 }
 
-// showTableOneForm показывает форму параметров гидростатики и по клику "Ок" запускает импорт.
-func showTableOneForm(s *Service, path string) {
-	ws := widget.NewEntry() // Работа: c
-	we := widget.NewEntry() // Работа: по
-	is := widget.NewEntry() // Простой: c (необязательно)
-	ie := widget.NewEntry() // Простой: по (необязательно)
-	wr := widget.NewEntry() // Плотность (работа)
-	ir := widget.NewEntry() // Плотность (простои) (необязательно)
-	dh := widget.NewEntry() // Δh (м)
-	unit := widget.NewSelect([]string{"kgf/cm2", "bar", "atm"}, nil)
-	unit.SetSelected("kgf/cm2")
-
-	ws.PlaceHolder = "YYYY-MM-DD"
-	we.PlaceHolder = "YYYY-MM-DD"
-	is.PlaceHolder = "YYYY-MM-DD"
-	ie.PlaceHolder = "YYYY-MM-DD"
-
-	items := []*widget.FormItem{
-		{Text: "Работа: c", Widget: ws},
-		{Text: "Работа: по", Widget: we},
-		{Text: "Простой: c (необязательно)", Widget: is},
-		{Text: "Простой: по (необязательно)", Widget: ie},
-		{Text: "Плотность (работа)", Widget: wr},
-		{Text: "Плотность (простои, по умолчанию = плотность работы)", Widget: ir},
-		{Text: "Δh (м)", Widget: dh},
-		{Text: "Единица давления", Widget: unit},
-	}
-
-	dlg := dialog.NewForm("Параметры гидростатики", "Ок", "Отмена", items,
-		func(ok bool) {
-			if !ok {
-				return // пользователь отменил
-			}
-
-			var missing []string
-			if strings.TrimSpace(ws.Text) == "" {
-				missing = append(missing, "Работа: c")
-			}
-			if strings.TrimSpace(we.Text) == "" {
-				missing = append(missing, "Работа: по")
-			}
-			if strings.TrimSpace(wr.Text) == "" {
-				missing = append(missing, "Плотность (работа)")
-			}
-			if strings.TrimSpace(dh.Text) == "" {
-				missing = append(missing, "Δh (м)")
-			}
-			if unit.Selected == "" {
-				missing = append(missing, "Единица давления")
-			}
-			if len(missing) > 0 {
-				dialog.ShowInformation(
-					"Ошибка",
-					"Пожалуйста, заполните обязательные поля:\n• "+strings.Join(missing, "\n• "),
-					s.window,
-				)
-				return
-			}
-
-			var parseErrs []string
-
-			workStart, err := s.converter.ParseFlexibleTime(ws.Text)
-			if err != nil {
-				parseErrs = append(parseErrs, fmt.Sprintf("Работа: c — %v", err))
-			}
-			workEnd, err := s.converter.ParseFlexibleTime(we.Text)
-			if err != nil {
-				parseErrs = append(parseErrs, fmt.Sprintf("Работа: по — %v", err))
-			}
-
-			var idleStart, idleEnd time.Time
-			if strings.TrimSpace(is.Text) != "" {
-				idleStart, err = s.converter.ParseFlexibleTime(is.Text)
-				if err != nil {
-					parseErrs = append(parseErrs, fmt.Sprintf("Простой: c — %v", err))
-				}
-			}
-			if strings.TrimSpace(ie.Text) != "" {
-				idleEnd, err = s.converter.ParseFlexibleTime(ie.Text)
-				if err != nil {
-					parseErrs = append(parseErrs, fmt.Sprintf("Простой: по — %v", err))
-				}
-			}
-
-			if len(parseErrs) > 0 {
-				dialog.ShowError(
-					fmt.Errorf("Неверный формат даты/времени:\n%s", strings.Join(parseErrs, "\n")),
-					s.window,
-				)
-				return
-			}
-
-			// дальше парсим плотности и формируем cfg как раньше
-			workDens, _ := strconv.ParseFloat(wr.Text, 64)
-			var idleDens float64
-			if strings.TrimSpace(ir.Text) == "" {
-				idleDens = workDens
-			} else {
-				idleDens, _ = strconv.ParseFloat(ir.Text, 64)
-			}
-			depthDiff, _ := strconv.ParseFloat(dh.Text, 64)
-
-			cfg := models.OperationConfig{
-				WorkStart:    workStart,
-				WorkEnd:      workEnd,
-				IdleStart:    idleStart,
-				IdleEnd:      idleEnd,
-				WorkDensity:  workDens,
-				IdleDensity:  idleDens,
-				DepthDiff:    depthDiff,
-				PressureUnit: unit.Selected,
-			}
-
-			fileName := filepath.Base(path)
-			s.showLoadingIndicator(fileName)
-
-			go s.doTableOneImport(path, cfg)
-
-		}, s.window)
-
-	dlg.Show()
-}
-
 // --- НОВАЯ ФУНКЦИЯ: Показ формы для Блока 5 ---
 func (s *Service) showBlockFiveForm(ctx context.Context) {
 	s.zLog.Debugw("Opening Block 5 form")
@@ -488,6 +514,8 @@ func (s *Service) showBlockFiveForm(ctx context.Context) {
 	densityOilEntry := widget.NewEntry()
 	densityLiquidStoppedEntry := widget.NewEntry()
 	densityLiquidWorkingEntry := widget.NewEntry()
+	reservoirEntries := newReservoirEntries(models.ReservoirParams{})
+	extrapolateCheck := widget.NewCheck("Продолжать ствол по касательной за пределы инклинометрии", nil)
 
 	researchTypeSelect := widget.NewSelect(researchTypeNames, nil)
 	researchTypeSelect.PlaceHolder = "Выберите вид исследования"
@@ -524,9 +552,13 @@ func (s *Service) showBlockFiveForm(ctx context.Context) {
 		widget.NewFormItem("Плотность нефти (kg/m³)", densityOilEntry),
 		widget.NewFormItem("Плотность жидкости в простое (kg/m³)", densityLiquidStoppedEntry),
 		widget.NewFormItem("Плотность жидкости в работе (kg/m³)", densityLiquidWorkingEntry),
+		widget.NewFormItem("Экстраполяция", extrapolateCheck),
+	}
+	formItems = append(formItems, reservoirEntries.formItems("(опц.)")...)
+	formItems = append(formItems,
 
 		widget.NewFormItem("", widget.NewLabel("Остальные параметры (TVD, ΔP и т.д.) рассчитываются автоматически.")),
-	}
+	)
 
 	// 5. Показ диалога и обработчик Save
 	formDialog := dialog.NewForm(
@@ -589,6 +621,10 @@ func (s *Service) showBlockFiveForm(ctx context.Context) {
 			if report.DensityLiquidWorking, err = strconv.ParseFloat(densityLiquidWorkingEntry.Text, 64); err != nil {
 				convErrs = append(convErrs, fmt.Sprintf("Плотность жидкости в работе: %v", err))
 			}
+			// Свойства пласта необязательны: пустое поле — NULL в отчёте
+			reservoir, errs := reservoirEntries.parse(true)
+			convErrs = append(convErrs, errs...)
+			report.SetReservoir(reservoir)
 
 			if len(convErrs) > 0 {
 				dialog.ShowError(fmt.Errorf("Ошибки конвертации:\n%s", strings.Join(convErrs, "\n")), s.window)
@@ -604,17 +640,25 @@ func (s *Service) showBlockFiveForm(ctx context.Context) {
 			}
 
 			//Вычисляем
-			report = calc.TableFive(report, tFour)
-			//Кладем в память
-			_ = s.memStorage.PutTableFiveData(report)
-			//Кладем в бд
-			id, err := s.db.SaveReport(ctx, report)
-			if err != nil {
-				s.zLog.Errorw("Failed to save report (Block 5)", "error", err)
-				dialog.ShowError(fmt.Errorf("ошибка сохранения: %w", err), s.window)
+			report, issues := calc.TableFive(report, tFour, models.SurveyOptions{Extrapolate: extrapolateCheck.Checked})
+			if len(issues) > 0 {
+				s.zLog.Infow("Survey issues in Block 5", "issues", len(issues), "errors", models.HasSurveyErrors(issues))
+			}
+			if models.HasSurveyErrors(issues) {
+				dialog.ShowError(fmt.Errorf("Отметки по инклинометрии не рассчитаны, отчёт не сохранён:\n%s", surveyIssuesText(issues)), s.window)
 				return
 			}
-			dialog.ShowInformation("Успех", fmt.Sprintf("ID отчёта: %d", id), s.window)
+			if len(issues) > 0 {
+				dialog.ShowConfirm("Проверка инклинометрии",
+					surveyIssuesText(issues)+"\n\nСохранить отчёт?",
+					func(ok bool) {
+						if ok {
+							s.saveReport(ctx, report)
+						}
+					}, s.window)
+				return
+			}
+			s.saveReport(ctx, report)
 		},
 		s.window,
 	)
@@ -622,6 +666,47 @@ func (s *Service) showBlockFiveForm(ctx context.Context) {
 	formDialog.Show()
 }
 
+// saveReport кладёт рассчитанную шапку отчёта в память и в БД.
+func (s *Service) saveReport(ctx context.Context, report models.TableFive) {
+	// пока строки импорта не записаны, шапка в исследование не попадёт: не сохраняем её и в БД
+	if s.memStorage.ActiveResearch().Pending {
+		dialog.ShowInformation("Шапка отчёта", "Дождитесь завершения импорта и примите или откатите его", s.window)
+		return
+	}
+	//Кладем в бд
+	id, err := s.db.SaveReport(ctx, report)
+	if err == nil {
+		// ID нужен, чтобы дописать в отчёт результаты интерпретации КВД
+		report.ID = int(id)
+	}
+	//Кладем в память, даже если БД недоступна
+	if putErr := s.memStorage.PutTableFiveData(report); putErr != nil {
+		s.zLog.Errorw("Report (Block 5) not stored in research", "error", putErr)
+		dialog.ShowError(fmt.Errorf("шапка отчёта не сохранена в исследовании: %w", putErr), s.window)
+		return
+	}
+	s.recordChange(models.ChangeReport, "Шапка отчёта (Блок 5)")
+	if err != nil {
+		s.zLog.Errorw("Failed to save report (Block 5)", "error", err)
+		dialog.ShowError(fmt.Errorf("ошибка сохранения: %w", err), s.window)
+		return
+	}
+	dialog.ShowInformation("Успех", fmt.Sprintf("ID отчёта: %d", id), s.window)
+}
+
+// surveyIssuesText перечисляет проблемы инклинометрии построчно: сначала ошибки, затем предупреждения.
+func surveyIssuesText(issues []models.SurveyIssue) string {
+	lines := make([]string, 0, len(issues))
+	for _, errs := range []bool{true, false} {
+		for _, i := range issues {
+			if i.Error == errs {
+				lines = append(lines, "• "+i.String())
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // --- Методы для индикатора загрузки ---
 func (s *Service) showLoadingIndicator(fileName string) {
 	s.zLog.Debugw("Showing loading indicator", "file", fileName)
@@ -640,119 +725,61 @@ func (s *Service) hideLoadingIndicator(err error) {
 	}
 }
 
-// doTableOneImport делает парсинг TableOne, сохраняет, логирует и выводит результат.
-func (s *Service) doTableOneImport(path string, cfg models.OperationConfig) {
-
-	var finalErr error
-	defer func() {
-		s.hideLoadingIndicator(finalErr)
-	}()
-
-	start := time.Now()
-	data, err := s.importer.ParseBlockOneFile(path, cfg)
-	count := len(data)
+// importTableOne импортирует блок 1. Если параметры гидростатики ещё не заданы, после импорта
+// открывается редактор графика работы: периоды можно определить по только что загруженным данным.
+func (s *Service) importTableOne(ctx context.Context, job importJob, opts models.ImportOptions) {
+	_, ok, err := s.memStorage.GetOperationConfig()
 	if err != nil {
-		finalErr = err
-		s.zLog.Errorw("TableOne import failed", "error", err, "duration", time.Since(start))
+		dialog.ShowError(fmt.Errorf("не удалось получить параметры гидростатики: %w", err), s.window)
 		return
 	}
-	if err2 := s.memStorage.PutTableOneData(data); err2 != nil {
-		finalErr = fmt.Errorf("ошибка сохранения: %w", err2)
-		s.zLog.Errorw("TableOne save failed", "error", err2)
-		return
+	var done func()
+	if !ok {
+		done = s.editOperationConfig
 	}
-
-	// Если дошли сюда, ошибок не было (importErr == nil)
-	elapsed := time.Since(start)
-	s.zLog.Infow("TableOne import success", "count", count, "duration", elapsed)
-	go func() {
-		time.Sleep(100 * time.Millisecond)
-		dialog.ShowInformation(
-			"Готово",
-			fmt.Sprintf("TableOne: %d записей импортировано за %s", count, elapsed.Round(time.Millisecond)),
-			s.window,
-		)
-	}()
+	s.doTableOneImport(ctx, job, opts, done)
 }
 
-// doGenericImport обрабатывает TableTwo/TableThree.
-func (s *Service) doGenericImport(path, typ string) {
-	fileName := filepath.Base(path)
-	s.showLoadingIndicator(fileName)
+// doTableOneImport запускает потоковый импорт TableOne прямо в хранилище.
+func (s *Service) doTableOneImport(ctx context.Context, job importJob, opts models.ImportOptions, done func()) {
+	before := s.memStorage.CountBlockOne()
+	s.runImport(ctx, job, opts,
+		func(ctx context.Context, opts models.ImportOptions) (models.ImportReport, error) {
+			return s.importer.StreamBlockOneFile(ctx, job.path, opts, s.memStorage.PutTableOneData)
+		},
+		func() error { return s.memStorage.TruncateTableOneData(before) },
+		done,
+	)
+}
 
-	// Запускаем сам импорт в горутине
-	go func() {
-		var finalErr error
-		var count int
-		start := time.Now()
-
-		defer func() {
-			s.hideLoadingIndicator(finalErr)
-		}()
-
-		switch typ {
-		case "TableTwo":
-			var data []models.TableTwo
-			data, finalErr = s.importer.ParseBlockTwoFile(path)
-			count = len(data)
-			if finalErr == nil {
-				finalErr = s.memStorage.PutTableTwoData(data)
-			}
-		case "TableThree":
-			var data []models.TableThree
-			data, finalErr = s.importer.ParseBlockThreeFile(path)
-			count = len(data)
-			if finalErr == nil {
-				finalErr = s.memStorage.PutTableThreeData(data)
-			}
-		case "TableFour":
-			var data []models.TableFour
-			data, finalErr = s.importer.ParseBlockFourFile(path)
-			count = len(data)
-			if finalErr == nil {
-				//	id, dbErr := s.db.SaveBlockFour(ctx, data)
-				//	if dbErr == nil {
-				//		s.memStorage.SetResearchID(id)
-				finalErr = s.memStorage.PutTableFourData(data)
-				//	} else {
-				//		finalErr = dbErr
-				//	}
-			}
+// doGenericImport обрабатывает TableTwo/TableThree/TableFour.
+func (s *Service) doGenericImport(ctx context.Context, job importJob, base models.ImportOptions) {
+	var (
+		run      importFunc
+		rollback func() error
+	)
+	path := job.path
+	switch job.typ {
+	case "TableTwo":
+		before := s.memStorage.CountBlockTwo()
+		run = func(ctx context.Context, opts models.ImportOptions) (models.ImportReport, error) {
+			return s.importer.StreamBlockTwoFile(ctx, path, opts, s.memStorage.PutTableTwoData)
 		}
-
-		if finalErr != nil {
-			s.zLog.Errorw(typ+" import failed", "error", finalErr, "duration", time.Since(start))
-			return
+		rollback = func() error { return s.memStorage.TruncateTableTwoData(before) }
+	case "TableThree":
+		before := s.memStorage.CountBlockThree()
+		run = func(ctx context.Context, opts models.ImportOptions) (models.ImportReport, error) {
+			return s.importer.StreamBlockThreeFile(ctx, path, opts, s.memStorage.PutTableThreeData)
 		}
-
-		// Успех
-		elapsed := time.Since(start)
-		s.zLog.Infow(typ+" import success", "count", count, "duration", elapsed)
-
-		// Показываем сообщение об успехе ПОСЛЕ скрытия индикатора
-		// Запускаем это тоже в горутине, чтобы не блокировать выход из текущей
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			dialog.ShowInformation(
-				"Готово",
-				fmt.Sprintf("%s: %d записей импортировано за %s", typ, count, elapsed.Round(time.Millisecond)),
-				s.window,
-			)
-		}()
-	}()
-}
-
-func (s *Service) addGuidebookEntry(ctx context.Context, guidebookType string, name string) error {
-	switch guidebookType {
-	case "Месторождение":
-		return s.db.SaveOilFields(ctx, []models.OilField{{Name: name}})
-	case "Продуктивный горизонт":
-		return s.db.SaveProductiveHorizons(ctx, []models.ProductiveHorizon{{Name: name}})
-	case "Тип прибора":
-		return s.db.SaveInstrumentTypes(ctx, []models.InstrumentType{{Name: name}})
-	case "Вид исследования":
-		return s.db.SaveResearchTypes(ctx, []models.ResearchType{{Name: name}})
+		rollback = func() error { return s.memStorage.TruncateTableThreeData(before) }
+	case "TableFour":
+		before := s.memStorage.CountBlockFour()
+		run = func(ctx context.Context, opts models.ImportOptions) (models.ImportReport, error) {
+			return s.importer.StreamBlockFourFile(ctx, path, opts, s.memStorage.PutTableFourData)
+		}
+		rollback = func() error { return s.memStorage.TruncateTableFourData(before) }
 	default:
-		return errors.New("неизвестный тип справочника")
+		return
 	}
+	s.runImport(ctx, job, base, run, rollback, nil)
 }
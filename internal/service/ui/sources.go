@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// importJob — импортируемый файл и то, что о нём нужно записать в список импортированных.
+type importJob struct {
+	typ, path string
+	hash      string   // SHA-256 содержимого; пусто — не посчитан
+	replace   int      // ID прежнего импорта, строки которого заменяет этот; 0 — новый импорт
+	elevation *float64 // альтитуда стола ротора для инклинометрии по углам
+}
+
+// checkImportSource считает хеш файла и, если такой файл уже импортирован в активное исследование,
+// спрашивает, продолжать ли. next вызывается с job, в котором заполнен хеш.
+func (s *Service) checkImportSource(job importJob, next func(importJob)) {
+	var duplicate *models.ImportSource
+	s.runWithProgress("Проверка файла", func() error {
+		hash, err := s.importer.FileHash(job.path)
+		if err != nil {
+			return fmt.Errorf("файл %s: %w", filepath.Base(job.path), err)
+		}
+		job.hash = hash
+		sources, err := s.memStorage.GetImportSources()
+		if err != nil {
+			return fmt.Errorf("не удалось получить импортированные файлы: %w", err)
+		}
+		for _, src := range sources {
+			if src.Hash == hash {
+				duplicate = &src
+				break
+			}
+		}
+		return nil
+	}, func() {
+		if duplicate == nil {
+			next(job)
+			return
+		}
+		s.zLog.Infow("Duplicate import", "file", job.path, "previous", duplicate.File, "block", duplicate.Block)
+		msg := fmt.Sprintf("Этот файл уже импортирован: %s, %s, %s.\nСтроки задвоятся. Импортировать ещё раз?",
+			filepath.Base(duplicate.File), duplicate.Block, duplicate.ImportedAt.Local().Format("02.01.2006 15:04"))
+		if duplicate.ID == job.replace {
+			msg = fmt.Sprintf("Файл %s не изменился с прошлого импорта. Импортировать заново?", filepath.Base(job.path))
+		}
+		dialog.ShowConfirm("Повторный импорт", msg, func(ok bool) {
+			if ok {
+				next(job)
+			}
+		}, s.window)
+	})
+}
+
+// startImport запускает импорт файла job в блок по типу документа.
+func (s *Service) startImport(ctx context.Context, job importJob, opts models.ImportOptions) {
+	switch job.typ {
+	case "TableOne":
+		s.importTableOne(ctx, job, opts)
+	case surveyDocType:
+		s.importSurvey(ctx, job, opts.Mode)
+	default:
+		s.doGenericImport(ctx, job, opts)
+	}
+}
+
+// reimport импортирует файл src заново с прежними параметрами; новые строки заменяют строки src.
+func (s *Service) reimport(ctx context.Context, src models.ImportSource) {
+	opts := models.ImportOptions{Mode: src.Mode, Units: src.FileUnits(), Time: src.TimeCorrection}
+	job := importJob{typ: src.Block, path: src.File, replace: src.ID, elevation: src.Elevation}
+	s.checkImportSource(job, func(job importJob) { s.startImport(ctx, job, opts) })
+}
+
+// blockIndex возвращает номер блока (0–3) типа документа; инклинометрия по углам — Блок 4.
+func blockIndex(typ string) int {
+	switch typ {
+	case "TableOne":
+		return 0
+	case "TableTwo":
+		return 1
+	case "TableThree":
+		return 2
+	default:
+		return 3
+	}
+}
+
+// showImportSources показывает импортированные файлы активного исследования по блокам.
+// Строки выбранного файла можно удалить или заменить повторным импортом, не трогая остальные.
+func (s *Service) showImportSources(ctx context.Context) {
+	var (
+		sources  [4][]models.ImportSource
+		rows     map[int]int // число строк по ID импорта
+		selected *models.ImportSource
+		lists    [4]*widget.List
+	)
+	reload := func() {
+		all, err := s.memStorage.GetImportSources()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить импортированные файлы: %w", err), s.window)
+		}
+		origins, err := s.memStorage.GetRowSources()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить пометки строк: %w", err), s.window)
+		}
+		sources, rows = [4][]models.ImportSource{}, make(map[int]int)
+		for _, src := range all {
+			b := blockIndex(src.Block)
+			sources[b] = append(sources[b], src)
+		}
+		for _, block := range origins {
+			for _, id := range block {
+				rows[id]++
+			}
+		}
+		selected = nil
+		for _, list := range lists {
+			list.UnselectAll()
+			list.Refresh()
+		}
+	}
+
+	tabs := container.NewAppTabs()
+	for b := range lists {
+		lists[b] = widget.NewList(
+			func() int { return len(sources[b]) },
+			func() fyne.CanvasObject { return widget.NewLabel("") },
+			func(id widget.ListItemID, o fyne.CanvasObject) {
+				src := sources[b][id]
+				o.(*widget.Label).SetText(formatImportSource(src, rows[src.ID]))
+			},
+		)
+		lists[b].OnSelected = func(id widget.ListItemID) {
+			src := sources[b][id]
+			selected = &src
+		}
+		tabs.Append(container.NewTabItem(fmt.Sprintf("Блок %d", b+1), lists[b]))
+	}
+	reload()
+	tabs.OnSelected = func(*container.TabItem) {
+		selected = nil
+		for _, list := range lists {
+			list.UnselectAll()
+		}
+	}
+
+	// выбранный файл, строки которого помечены; иначе пользователь видит, почему операция недоступна
+	chosen := func(title string) (models.ImportSource, bool) {
+		if selected == nil {
+			dialog.ShowInformation(title, "Выберите файл", s.window)
+			return models.ImportSource{}, false
+		}
+		if rows[selected.ID] == 0 {
+			dialog.ShowInformation(title, "Строки этого файла не помечены: он открыт из БД или старого файла проекта", s.window)
+			return models.ImportSource{}, false
+		}
+		return *selected, true
+	}
+
+	title := "Импортированные файлы: " + s.memStorage.ActiveResearch().Name
+	dlg := dialog.NewCustomWithoutButtons(title, container.NewBorder(
+		widget.NewLabel("Удаление и повторный импорт меняют только строки выбранного файла и записываются в историю."),
+		nil, nil, nil, tabs), s.window)
+	closeBtn := widget.NewButton("Закрыть", dlg.Hide)
+	removeBtn := widget.NewButton("Удалить", func() {
+		src, ok := chosen("Удаление файла")
+		if !ok {
+			return
+		}
+		dialog.ShowConfirm("Удаление файла",
+			fmt.Sprintf("Удалить %d строк файла %s?", rows[src.ID], filepath.Base(src.File)),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if _, err := s.memStorage.RemoveImportSource(src.ID); err != nil {
+					dialog.ShowError(err, s.window)
+					return
+				}
+				s.zLog.Infow("Import source removed", "source", src.ID, "file", src.File)
+				s.historyChanged()
+				reload()
+			}, s.window)
+	})
+	reimportBtn := widget.NewButton("Импортировать заново", func() {
+		src, ok := chosen("Повторный импорт")
+		if !ok {
+			return
+		}
+		dlg.Hide()
+		s.reimport(ctx, src)
+	})
+	dlg.SetButtons([]fyne.CanvasObject{closeBtn, removeBtn, reimportBtn})
+	dlg.Resize(fyne.NewSize(900, 480))
+	dlg.Show()
+}
+
+func formatImportSource(src models.ImportSource, rows int) string {
+	mode := "мягкий"
+	if src.Mode == models.ImportModeStrict {
+		mode = "строгий"
+	}
+	text := fmt.Sprintf("%s  %s — %d строк", src.ImportedAt.Local().Format("02.01 15:04:05"), filepath.Base(src.File), rows)
+	if rows != src.Rows {
+		text += fmt.Sprintf(" из %d", src.Rows)
+	}
+	text += fmt.Sprintf(", %s, режим %s", src.Format, mode)
+	if src.TimeCorrection != nil {
+		text += ", поправка " + src.TimeCorrection.String()
+	}
+	if len(src.Hash) >= 8 {
+		text += ", sha256 " + src.Hash[:8]
+	}
+	return text
+}
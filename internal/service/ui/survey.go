@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+)
+
+// surveyDocType — тип документа для инклинометрии с углами: MD, зенитный угол, азимут.
+const surveyDocType = "TableFour (MD, угол, азимут)"
+
+// importSurvey спрашивает альтитуду стола ротора и импортирует замеры углов. Траектория считается
+// после чтения всего файла и заменяет собой порцию блока 4, поэтому откат просто отрезает её.
+func (s *Service) importSurvey(ctx context.Context, job importJob, mode models.ImportMode) {
+	path := job.path
+	elevation := widget.NewEntry()
+	elevation.SetText("0")
+	if job.elevation != nil {
+		elevation.SetText(formatFormFloat(*job.elevation))
+	}
+	form := widget.NewForm(widget.NewFormItem("Альтитуда стола ротора (КБ), м", elevation))
+	form.Append("", widget.NewLabel("TVDSS = TVD − альтитуда. TVD, смещения и интенсивность\nрассчитываются методом минимальной кривизны."))
+
+	dlg := dialog.NewCustomWithoutButtons("Инклинометрия по углам", form, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	okBtn := widget.NewButton("Импортировать", func() {
+		kb, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(elevation.Text), ",", "."), 64)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("альтитуда: требуется число"), s.window)
+			return
+		}
+		dlg.Hide()
+
+		before := s.memStorage.CountBlockFour()
+		job.elevation = &kb
+		s.runImport(ctx, job, models.ImportOptions{Mode: mode},
+			func(ctx context.Context, opts models.ImportOptions) (models.ImportReport, error) {
+				var stations []models.SurveyStation
+				report, err := s.importer.StreamSurveyFile(ctx, path, opts, func(chunk []models.SurveyStation) error {
+					stations = append(stations, chunk...)
+					return nil
+				})
+				if err != nil {
+					return report, err
+				}
+				survey, err := calc.Trajectory(stations, kb)
+				if err != nil {
+					return report, err
+				}
+				s.zLog.Infow("Survey trajectory computed", "stations", len(stations), "elevation", kb,
+					"tvd", survey[len(survey)-1].TrueVerticalDepth)
+				return report, s.memStorage.PutTableFourData(survey)
+			},
+			func() error { return s.memStorage.TruncateTableFourData(before) },
+			nil,
+		)
+	})
+	okBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, okBtn})
+	dlg.Resize(fyne.NewSize(500, 220))
+	dlg.Show()
+}
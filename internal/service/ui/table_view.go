@@ -0,0 +1,520 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+)
+
+// dataColumn — колонка таблицы блока.
+type dataColumn struct {
+	title string
+	width float32
+	value func(i int) string             // текст ячейки строки i блока
+	less  func(a, b int) bool            // порядок строк a и b по колонке
+	edit  func(i int, text string) error // правка значения строки i; nil — колонка расчётная
+}
+
+// dataTable — снимок строк блока для таблицы. Правки и удаление идут через хранилище,
+// после них снимок строится заново.
+type dataTable struct {
+	rows    int
+	columns []dataColumn
+	time    func(i int) time.Time // время строки i для фильтра; nil — у блока нет времени, у блока 2 — время Ртр
+	remove  func(idx []int) error
+}
+
+// dataBlockTitles — блоки, строки которых показывает таблица; номер в срезе — номер блока минус один.
+var dataBlockTitles = []string{"Блок 1: Рзаб, Тзаб", "Блок 2: Ртр, Рзтр, Рлин", "Блок 3: дебиты", "Блок 4: инклинометрия"}
+
+// dataBlockChanges — части данных, от которых зависит таблица блока: строки, гидростатика и единицы.
+var dataBlockChanges = [4]models.DataBlocks{
+	models.DataBlockOne | models.DataSettings,
+	models.DataBlockTwo | models.DataSettings,
+	models.DataBlockThree | models.DataSettings,
+	models.DataBlockFour,
+}
+
+// showDataView показывает строки блока активного исследования в таблице. Строки можно сортировать
+// по колонке, отбирать по времени, править по одному значению и удалять; правки попадают в историю.
+// Таблица рисует только видимые строки, поэтому выдерживает сотни тысяч записей.
+func (s *Service) showDataView(ctx context.Context) {
+	back := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
+
+	var (
+		block    int
+		tbl      dataTable
+		view     []int // номера строк блока в порядке показа
+		sortCol  = -1
+		sortDesc bool
+		from, to time.Time
+		cell     = widget.TableCellID{Row: -1, Col: -1}
+	)
+
+	status := widget.NewLabel("")
+	cellLabel := widget.NewLabel("Выберите ячейку")
+	valueEntry := widget.NewEntry()
+	valueEntry.Disable()
+
+	table := widget.NewTable(
+		func() (int, int) { return len(view), len(tbl.columns) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.Truncation = fyne.TextTruncateEllipsis
+			return l
+		},
+		func(id widget.TableCellID, o fyne.CanvasObject) {
+			if id.Row >= len(view) || id.Col >= len(tbl.columns) {
+				return
+			}
+			o.(*widget.Label).SetText(tbl.columns[id.Col].value(view[id.Row]))
+		},
+	)
+	table.ShowHeaderRow = true
+	table.CreateHeader = func() fyne.CanvasObject { return widget.NewButton("", nil) }
+
+	// apply отбирает строки по времени и сортирует их по выбранной колонке
+	apply := func() {
+		view = make([]int, 0, tbl.rows)
+		for i := 0; i < tbl.rows; i++ {
+			if tbl.time != nil {
+				t := tbl.time(i)
+				if !from.IsZero() && t.Before(from) || !to.IsZero() && t.After(to) {
+					continue
+				}
+			}
+			view = append(view, i)
+		}
+		if sortCol >= 0 && sortCol < len(tbl.columns) {
+			less := tbl.columns[sortCol].less
+			sort.SliceStable(view, func(a, b int) bool {
+				if sortDesc {
+					return less(view[b], view[a])
+				}
+				return less(view[a], view[b])
+			})
+		}
+		status.SetText(fmt.Sprintf("Показано строк: %d из %d", len(view), tbl.rows))
+		cell = widget.TableCellID{Row: -1, Col: -1}
+		cellLabel.SetText("Выберите ячейку")
+		valueEntry.SetText("")
+		valueEntry.Disable()
+		table.UnselectAll()
+		table.Refresh()
+	}
+	reload := func() {
+		t, err := s.dataTable(block)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить строки: %w", err), s.window)
+			return
+		}
+		tbl = t
+		for c, col := range tbl.columns {
+			table.SetColumnWidth(c, col.width)
+		}
+		apply()
+	}
+
+	table.UpdateHeader = func(id widget.TableCellID, o fyne.CanvasObject) {
+		btn := o.(*widget.Button)
+		if id.Col < 0 || id.Col >= len(tbl.columns) {
+			btn.SetText("")
+			btn.OnTapped = nil
+			return
+		}
+		text := tbl.columns[id.Col].title
+		switch {
+		case id.Col == sortCol && sortDesc:
+			text += " ▼"
+		case id.Col == sortCol:
+			text += " ▲"
+		}
+		btn.SetText(text)
+		btn.OnTapped = func() {
+			// повторное нажатие меняет направление
+			if sortCol == id.Col {
+				sortDesc = !sortDesc
+			} else {
+				sortCol, sortDesc = id.Col, false
+			}
+			apply()
+		}
+	}
+	table.OnSelected = func(id widget.TableCellID) {
+		if id.Row < 0 || id.Row >= len(view) || id.Col < 0 || id.Col >= len(tbl.columns) {
+			return
+		}
+		cell = id
+		col := tbl.columns[id.Col]
+		cellLabel.SetText(fmt.Sprintf("Строка %d, %s:", view[id.Row]+1, col.title))
+		valueEntry.SetText(col.value(view[id.Row]))
+		if col.edit == nil {
+			valueEntry.Disable()
+			return
+		}
+		valueEntry.Enable()
+		s.window.Canvas().Focus(valueEntry)
+	}
+
+	save := func() {
+		if cell.Row < 0 || tbl.columns[cell.Col].edit == nil {
+			return
+		}
+		i, col := view[cell.Row], tbl.columns[cell.Col]
+		if err := col.edit(i, valueEntry.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.recordChange(models.ChangeEdit, fmt.Sprintf("Блок %d, строка %d: %s", block+1, i+1, col.title))
+		reload()
+	}
+	valueEntry.OnSubmitted = func(string) { save() }
+	saveBtn := widget.NewButton("Сохранить", save)
+	saveBtn.Importance = widget.HighImportance
+
+	remove := func(idx []int, title string) {
+		dialog.ShowConfirm("Удаление строк", fmt.Sprintf("%s? Удаление можно отменить в истории изменений.", title),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := tbl.remove(idx); err != nil {
+					dialog.ShowError(err, s.window)
+					return
+				}
+				s.recordChange(models.ChangeEdit, fmt.Sprintf("Блок %d: удалено строк %d", block+1, len(idx)))
+				reload()
+			}, s.window)
+	}
+	removeRowBtn := widget.NewButton("Удалить строку", func() {
+		if cell.Row < 0 {
+			dialog.ShowInformation("Удаление строк", "Выберите ячейку в строке", s.window)
+			return
+		}
+		i := view[cell.Row]
+		remove([]int{i}, fmt.Sprintf("Удалить строку %d", i+1))
+	})
+	removeShownBtn := widget.NewButton("Удалить показанные", func() {
+		if len(view) == 0 || len(view) == tbl.rows {
+			dialog.ShowInformation("Удаление строк", "Отберите строки по времени: удаляются только показанные", s.window)
+			return
+		}
+		remove(append([]int(nil), view...), fmt.Sprintf("Удалить %d показанных строк", len(view)))
+	})
+
+	fromEntry, toEntry := widget.NewEntry(), widget.NewEntry()
+	fromEntry.SetPlaceHolder("с, например 2024-01-31 08:00")
+	toEntry.SetPlaceHolder("по")
+	filterBtn := widget.NewButton("Отобрать", func() {
+		var err error
+		parse := func(text string) time.Time {
+			text = strings.TrimSpace(text)
+			if text == "" || err != nil {
+				return time.Time{}
+			}
+			var t time.Time
+			t, err = s.converter.ParseFlexibleTime(text)
+			return t
+		}
+		f, t := parse(fromEntry.Text), parse(toEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("границы времени: %w", err), s.window)
+			return
+		}
+		from, to = f, t
+		apply()
+	})
+	resetBtn := widget.NewButton("Все строки", func() {
+		fromEntry.SetText("")
+		toEntry.SetText("")
+		from, to = time.Time{}, time.Time{}
+		apply()
+	})
+	timeFilter := container.NewBorder(nil, nil, widget.NewLabel("Время:"),
+		container.NewHBox(filterBtn, resetBtn), container.NewGridWithColumns(2, fromEntry, toEntry))
+
+	blockSelect := widget.NewSelect(dataBlockTitles, nil)
+	blockSelect.OnChanged = func(string) {
+		block = blockSelect.SelectedIndex()
+		sortCol, sortDesc = -1, false
+		// у инклинометрии нет времени
+		if block == 3 {
+			timeFilter.Hide()
+		} else {
+			timeFilter.Show()
+		}
+		reload()
+	}
+	blockSelect.SetSelectedIndex(0)
+
+	top := container.NewVBox(
+		back,
+		widget.NewLabel("Таблицы блоков: "+s.memStorage.ActiveResearch().Title()),
+		container.NewBorder(nil, nil, nil, status, blockSelect),
+		timeFilter,
+	)
+	editor := container.NewBorder(nil, nil, cellLabel, container.NewHBox(saveBtn, removeRowBtn, removeShownBtn), valueEntry)
+	// таблица следит за данными: импорт, отмена в истории и правки в другом окне видны сразу
+	s.showLiveView(container.NewBorder(top, editor, nil, nil, table), func(changed models.DataBlocks) {
+		if changed.Has(dataBlockChanges[block]) {
+			reload()
+		}
+	})
+}
+
+// dataTable возвращает снимок строк блока block (0–3) активного исследования в единицах отображения.
+func (s *Service) dataTable(block int) (dataTable, error) {
+	units := s.displayUnits()
+	switch block {
+	case 0:
+		return s.tableOneTable(units)
+	case 1:
+		return s.tableTwoTable(units)
+	case 2:
+		return s.tableThreeTable(units)
+	default:
+		return s.tableFourTable()
+	}
+}
+
+// tableOneTable показывает измеренные значения блока 1 и Рзаб на ВДП, рассчитанное calc.TableOne
+// по параметрам гидростатики.
+func (s *Service) tableOneTable(units models.UnitSystem) (dataTable, error) {
+	data, err := s.memStorage.GetTableOneData()
+	if err != nil {
+		return dataTable{}, err
+	}
+	cfg, ok, err := s.memStorage.GetOperationConfig()
+	if err != nil {
+		return dataTable{}, err
+	}
+	vdp := make([]float64, len(data))
+	for i, rec := range data {
+		vdp[i] = math.NaN()
+		if ok {
+			vdp[i] = calc.TableOne(rec, cfg).PressureAtVDP
+		}
+	}
+	update := func(i int, set func(*models.TableOne)) error {
+		row := data[i]
+		set(&row)
+		return s.memStorage.UpdateTableOneRow(i, row)
+	}
+	pressure, temperature := units.Symbol(models.QuantityPressure), units.Symbol(models.QuantityTemperature)
+	return dataTable{
+		rows: len(data),
+		columns: []dataColumn{
+			indexColumn(),
+			s.timeColumn("Дата, время", func(i int) time.Time { return data[i].Timestamp },
+				func(i int, t time.Time) error { return update(i, func(r *models.TableOne) { r.Timestamp = t }) }),
+			valueColumn("Рзаб, "+pressure, func(i int) float64 { return units.FromStorage(models.QuantityPressure, data[i].PressureDepth) },
+				func(i int, v float64) error {
+					return update(i, func(r *models.TableOne) { r.PressureDepth = units.ToStorage(models.QuantityPressure, v) })
+				}),
+			valueColumn("Тзаб, "+temperature, func(i int) float64 { return units.FromStorage(models.QuantityTemperature, data[i].TemperatureDepth) },
+				func(i int, v float64) error {
+					return update(i, func(r *models.TableOne) { r.TemperatureDepth = units.ToStorage(models.QuantityTemperature, v) })
+				}),
+			valueColumn("Рзаб на ВДП, "+pressure, func(i int) float64 { return units.FromStorage(models.QuantityPressure, vdp[i]) }, nil),
+		},
+		time:   func(i int) time.Time { return data[i].Timestamp },
+		remove: s.memStorage.DeleteTableOneRows,
+	}, nil
+}
+
+func (s *Service) tableTwoTable(units models.UnitSystem) (dataTable, error) {
+	data, err := s.memStorage.GetTableTwoData()
+	if err != nil {
+		return dataTable{}, err
+	}
+	update := func(i int, set func(*models.TableTwo)) error {
+		row := data[i]
+		set(&row)
+		return s.memStorage.UpdateTableTwoRow(i, row)
+	}
+	pressure := units.Symbol(models.QuantityPressure)
+	// pair возвращает колонки времени и давления одного замера
+	pair := func(name string, ts func(*models.TableTwo) *time.Time, p func(*models.TableTwo) *float64) []dataColumn {
+		return []dataColumn{
+			s.timeColumn("Время "+name, func(i int) time.Time { return *ts(&data[i]) },
+				func(i int, t time.Time) error { return update(i, func(r *models.TableTwo) { *ts(r) = t }) }),
+			valueColumn(name+", "+pressure, func(i int) float64 { return units.FromStorage(models.QuantityPressure, *p(&data[i])) },
+				func(i int, v float64) error {
+					return update(i, func(r *models.TableTwo) { *p(r) = units.ToStorage(models.QuantityPressure, v) })
+				}),
+		}
+	}
+	columns := []dataColumn{indexColumn()}
+	columns = append(columns, pair("Ртр",
+		func(r *models.TableTwo) *time.Time { return &r.TimestampTubing },
+		func(r *models.TableTwo) *float64 { return &r.PressureTubing })...)
+	columns = append(columns, pair("Рзтр",
+		func(r *models.TableTwo) *time.Time { return &r.TimestampAnnulus },
+		func(r *models.TableTwo) *float64 { return &r.PressureAnnulus })...)
+	columns = append(columns, pair("Рлин",
+		func(r *models.TableTwo) *time.Time { return &r.TimestampLinear },
+		func(r *models.TableTwo) *float64 { return &r.PressureLinear })...)
+	return dataTable{
+		rows:    len(data),
+		columns: columns,
+		time:    func(i int) time.Time { return data[i].TimestampTubing },
+		remove:  s.memStorage.DeleteTableTwoRows,
+	}, nil
+}
+
+// tableThreeTable показывает дебиты блока 3; после правки Qж, W или Qг расчётные дебиты
+// пересчитываются calc.TableThree.
+func (s *Service) tableThreeTable(units models.UnitSystem) (dataTable, error) {
+	data, err := s.memStorage.GetTableThreeData()
+	if err != nil {
+		return dataTable{}, err
+	}
+	update := func(i int, set func(*models.TableThree)) error {
+		row := data[i]
+		set(&row)
+		return s.memStorage.UpdateTableThreeRow(i, calc.TableThree(row))
+	}
+	rate := func(v *float64) float64 { return units.FromStorage(models.QuantityLiquidRate, optional(v)) }
+	liquid, gas := units.Symbol(models.QuantityLiquidRate), units.Symbol(models.QuantityGasRate)
+	return dataTable{
+		rows: len(data),
+		columns: []dataColumn{
+			indexColumn(),
+			s.timeColumn("Дата, время", func(i int) time.Time { return data[i].Timestamp },
+				func(i int, t time.Time) error { return update(i, func(r *models.TableThree) { r.Timestamp = t }) }),
+			valueColumn("Qж, "+liquid, func(i int) float64 { return units.FromStorage(models.QuantityLiquidRate, data[i].LiquidFlowRate) },
+				func(i int, v float64) error {
+					return update(i, func(r *models.TableThree) { r.LiquidFlowRate = units.ToStorage(models.QuantityLiquidRate, v) })
+				}),
+			valueColumn("W, %", func(i int) float64 { return data[i].WaterCut },
+				func(i int, v float64) error {
+					if v < 0 || v > 100 {
+						return fmt.Errorf("обводненность должна быть от 0 до 100%%")
+					}
+					return update(i, func(r *models.TableThree) { r.WaterCut = v })
+				}),
+			valueColumn("Qг, "+gas, func(i int) float64 { return units.FromStorage(models.QuantityGasRate, data[i].GasFlowRate) },
+				func(i int, v float64) error {
+					return update(i, func(r *models.TableThree) { r.GasFlowRate = units.ToStorage(models.QuantityGasRate, v) })
+				}),
+			valueColumn("Qн, "+liquid, func(i int) float64 { return rate(data[i].OilFlowRate) }, nil),
+			valueColumn("Qв, "+liquid, func(i int) float64 { return rate(data[i].WaterFlowRate) }, nil),
+			valueColumn("ГФ, м3/м3", func(i int) float64 { return optional(data[i].GasFactor) }, nil),
+		},
+		time:   func(i int) time.Time { return data[i].Timestamp },
+		remove: s.memStorage.DeleteTableThreeRows,
+	}, nil
+}
+
+// tableFourTable показывает инклинометрию. Править можно глубины; углы и смещения, рассчитанные
+// по замерам углов, только показываются.
+func (s *Service) tableFourTable() (dataTable, error) {
+	data, err := s.memStorage.GetTableFourData()
+	if err != nil {
+		return dataTable{}, err
+	}
+	depth := func(title string, field func(*models.TableFour) *float64) dataColumn {
+		return valueColumn(title, func(i int) float64 { return *field(&data[i]) },
+			func(i int, v float64) error {
+				row := data[i]
+				*field(&row) = v
+				return s.memStorage.UpdateTableFourRow(i, row)
+			})
+	}
+	return dataTable{
+		rows: len(data),
+		columns: []dataColumn{
+			indexColumn(),
+			depth("MD, м", func(r *models.TableFour) *float64 { return &r.MeasuredDepth }),
+			depth("TVD, м", func(r *models.TableFour) *float64 { return &r.TrueVerticalDepth }),
+			depth("TVDSS, м", func(r *models.TableFour) *float64 { return &r.TrueVerticalDepthSubSea }),
+			valueColumn("Зенит, °", func(i int) float64 { return optional(data[i].Inclination) }, nil),
+			valueColumn("Азимут, °", func(i int) float64 { return optional(data[i].Azimuth) }, nil),
+			valueColumn("Север, м", func(i int) float64 { return optional(data[i].Northing) }, nil),
+			valueColumn("Восток, м", func(i int) float64 { return optional(data[i].Easting) }, nil),
+			valueColumn("Интенсивность, °/10 м", func(i int) float64 { return optional(data[i].DoglegSeverity) }, nil),
+		},
+		remove: s.memStorage.DeleteTableFourRows,
+	}, nil
+}
+
+// indexColumn — номер строки в блоке, как в хранилище: по нему видно место строки после сортировки.
+func indexColumn() dataColumn {
+	return dataColumn{
+		title: "№",
+		width: 80,
+		value: func(i int) string { return strconv.Itoa(i + 1) },
+		less:  func(a, b int) bool { return a < b },
+	}
+}
+
+// timeColumn — колонка времени; set, если задан, сохраняет разобранное время строки.
+func (s *Service) timeColumn(title string, get func(i int) time.Time, set func(i int, t time.Time) error) dataColumn {
+	col := dataColumn{
+		title: title,
+		width: 180,
+		value: func(i int) string { return formatFormTime(get(i)) },
+		less:  func(a, b int) bool { return get(a).Before(get(b)) },
+	}
+	if set != nil {
+		col.edit = func(i int, text string) error {
+			t, err := s.converter.ParseFlexibleTime(strings.TrimSpace(text))
+			if err != nil {
+				return fmt.Errorf("%s: %w", title, err)
+			}
+			return set(i, t)
+		}
+	}
+	return col
+}
+
+// valueColumn — числовая колонка; NaN показывается пустой ячейкой и при сортировке идёт первым.
+// set, если задан, сохраняет введённое число.
+func valueColumn(title string, get func(i int) float64, set func(i int, v float64) error) dataColumn {
+	col := dataColumn{
+		title: title,
+		width: 130,
+		value: func(i int) string {
+			v := get(i)
+			if math.IsNaN(v) {
+				return ""
+			}
+			// после перевода единиц остаются хвосты вроде 1.0197162129779282
+			return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+		},
+		less: func(a, b int) bool {
+			va, vb := get(a), get(b)
+			return va < vb || math.IsNaN(va) && !math.IsNaN(vb)
+		},
+	}
+	if set != nil {
+		col.edit = func(i int, text string) error {
+			v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("%s: требуется число", title)
+			}
+			return set(i, v)
+		}
+	}
+	return col
+}
+
+// optional возвращает значение расчётного поля или NaN, если оно не рассчитано.
+func optional(v *float64) float64 {
+	if v == nil {
+		return math.NaN()
+	}
+	return *v
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// timeZones — часовые пояса для выбора; можно ввести и любой другой пояс IANA.
+var timeZones = []string{
+	"UTC",
+	"Europe/Kaliningrad",
+	"Europe/Moscow",
+	"Europe/Samara",
+	"Asia/Yekaterinburg",
+	"Asia/Omsk",
+	"Asia/Novosibirsk",
+	"Asia/Krasnoyarsk",
+	"Asia/Irkutsk",
+	"Asia/Yakutsk",
+	"Asia/Vladivostok",
+}
+
+// askTimeCorrection запрашивает поправку меток времени файла и передаёт её в apply.
+// Форма заполняется поправкой предыдущего импорта.
+func (s *Service) askTimeCorrection(fileName string, apply func(models.TimeCorrection)) {
+	prev := s.timeCorrection
+
+	zone := widget.NewSelectEntry(timeZones)
+	zone.SetText(prev.Zone)
+	if prev.Zone == "" {
+		zone.SetText("UTC")
+	}
+	offset := widget.NewEntry()
+	offset.PlaceHolder = "0"
+	if prev.Offset != 0 {
+		offset.SetText(formatFormFloat(prev.Offset.Minutes()))
+	}
+
+	refs := make([][2]*widget.Entry, 2)
+	for i := range refs {
+		refs[i] = [2]*widget.Entry{widget.NewEntry(), widget.NewEntry()}
+		refs[i][0].PlaceHolder = "время по прибору"
+		refs[i][1].PlaceHolder = "эталонное время"
+		if len(prev.Drift) == 2 {
+			loc, _ := prev.Location()
+			refs[i][0].SetText(formatFormTime(prev.Drift[i].Device.In(loc)))
+			refs[i][1].SetText(formatFormTime(prev.Drift[i].Reference.In(loc)))
+		}
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Часовой пояс файла", zone),
+		widget.NewFormItem("Сдвиг, мин", offset),
+		widget.NewFormItem("Точка 1: прибор", refs[0][0]),
+		widget.NewFormItem("Точка 1: эталон", refs[0][1]),
+		widget.NewFormItem("Точка 2: прибор", refs[1][0]),
+		widget.NewFormItem("Точка 2: эталон", refs[1][1]),
+	)
+	form.Append("", widget.NewLabel("Метки без пояса читаются как местное время выбранного пояса.\n"+
+		"Дрейф: в один момент записаны показания часов прибора и эталонных (SCADA);\n"+
+		"между точками поправка меняется линейно. Дрейф заменяет сдвиг."))
+
+	dlg := dialog.NewCustomWithoutButtons("Поправка времени: "+fileName, form, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	okBtn := widget.NewButton("Импортировать", func() {
+		tc := models.TimeCorrection{Zone: strings.TrimSpace(zone.Text)}
+		if tc.Zone == "UTC" {
+			tc.Zone = ""
+		}
+		loc, err := tc.Location()
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+
+		var errs []string
+		if text := strings.TrimSpace(offset.Text); text != "" {
+			minutes, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", "."), 64)
+			if err != nil {
+				errs = append(errs, "Сдвиг: требуется число минут")
+			}
+			tc.Offset = time.Duration(minutes * float64(time.Minute))
+		}
+
+		filled := 0
+		for _, pair := range refs {
+			for _, e := range pair {
+				if strings.TrimSpace(e.Text) != "" {
+					filled++
+				}
+			}
+		}
+		switch filled {
+		case 0:
+		case 4:
+			for i, pair := range refs {
+				device, errD := s.converter.ParseFlexibleTimeIn(strings.TrimSpace(pair[0].Text), loc)
+				reference, errR := s.converter.ParseFlexibleTimeIn(strings.TrimSpace(pair[1].Text), loc)
+				if errD != nil || errR != nil {
+					errs = append(errs, fmt.Sprintf("Точка %d: неверная дата/время", i+1))
+					continue
+				}
+				tc.Drift = append(tc.Drift, models.TimeReference{Device: device, Reference: reference})
+			}
+		default:
+			errs = append(errs, "Дрейф: заполните обе опорные точки или оставьте их пустыми")
+		}
+		if len(errs) > 0 {
+			dialog.ShowError(fmt.Errorf("Проверьте параметры:\n%s", strings.Join(errs, "\n")), s.window)
+			return
+		}
+		if err := tc.Validate(); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+
+		s.timeCorrection = tc
+		s.zLog.Infow("Time correction set", "file", fileName, "correction", tc.String())
+		dlg.Hide()
+		apply(tc)
+	})
+	okBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, okBtn})
+	dlg.Resize(fyne.NewSize(600, 420))
+	dlg.Show()
+}
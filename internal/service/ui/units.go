@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// displayUnits возвращает единицы, в которых графики, формы и экспорт показывают величины.
+func (s *Service) displayUnits() models.UnitSystem {
+	units, err := s.memStorage.GetDisplayUnits()
+	if err != nil {
+		s.zLog.Errorw("Display units unavailable, using storage units", "error", err)
+		return models.StorageUnits
+	}
+	return units
+}
+
+// unitSelect возвращает выбор единицы величины q с выбранной current.
+// Выбранная единица читается через selectedUnit.
+func unitSelect(q models.Quantity, current models.Unit) *widget.Select {
+	units := models.UnitsOf(q)
+	symbols := make([]string, len(units))
+	index := 0
+	for i, u := range units {
+		symbols[i] = u.Symbol()
+		if u == current {
+			index = i
+		}
+	}
+	sel := widget.NewSelect(symbols, nil)
+	sel.SetSelectedIndex(index)
+	return sel
+}
+
+// selectedUnit возвращает единицу, выбранную в unitSelect величины q.
+func selectedUnit(q models.Quantity, sel *widget.Select) models.Unit {
+	return models.UnitsOf(q)[max(0, sel.SelectedIndex())]
+}
+
+// unitSystemForm — набор выборов единиц по величинам.
+type unitSystemForm struct {
+	quantities []models.Quantity
+	selects    []*widget.Select
+}
+
+func newUnitSystemForm(units models.UnitSystem, quantities ...models.Quantity) *unitSystemForm {
+	f := &unitSystemForm{quantities: quantities}
+	for _, q := range quantities {
+		f.selects = append(f.selects, unitSelect(q, units.Unit(q)))
+	}
+	return f
+}
+
+// set выбирает единицы системы units.
+func (f *unitSystemForm) set(units models.UnitSystem) {
+	for i, q := range f.quantities {
+		for k, u := range models.UnitsOf(q) {
+			if u == units.Unit(q) {
+				f.selects[i].SetSelectedIndex(k)
+			}
+		}
+	}
+}
+
+// units собирает выбранные единицы; величины без выбора остаются в единицах хранения.
+func (f *unitSystemForm) units() models.UnitSystem {
+	out := models.StorageUnits
+	for i, q := range f.quantities {
+		out = out.With(q, selectedUnit(q, f.selects[i]))
+	}
+	return out
+}
+
+// items возвращает строки формы: по строке на величину.
+func (f *unitSystemForm) items() []*widget.FormItem {
+	items := make([]*widget.FormItem, len(f.quantities))
+	for i, q := range f.quantities {
+		items[i] = widget.NewFormItem(q.Title(), f.selects[i])
+	}
+	return items
+}
+
+// grid возвращает выборы одной строкой с подписями, для компактных форм.
+func (f *unitSystemForm) grid() fyne.CanvasObject {
+	cells := make([]fyne.CanvasObject, 0, len(f.quantities))
+	for i, q := range f.quantities {
+		cells = append(cells, container.NewBorder(nil, nil, widget.NewLabel(q.Title()+":"), nil, f.selects[i]))
+	}
+	return container.NewGridWithColumns(len(cells), cells...)
+}
+
+// editDisplayUnits открывает выбор системы единиц отображения. Данные в хранилище не меняются:
+// они всегда в единицах хранения, а графики, формы и экспорт переводят их при выводе.
+func (s *Service) editDisplayUnits() {
+	current := s.displayUnits()
+	form := newUnitSystemForm(current, models.Quantities...)
+
+	presetNames := make([]string, len(models.UnitPresets))
+	for i, p := range models.UnitPresets {
+		presetNames[i] = p.Name
+	}
+	preset := widget.NewSelect(presetNames, func(name string) {
+		for _, p := range models.UnitPresets {
+			if p.Name == name {
+				form.set(p.Units)
+			}
+		}
+	})
+	preset.PlaceHolder = "Свой набор"
+	for _, p := range models.UnitPresets {
+		if p.Units == current.Resolved() {
+			preset.SetSelected(p.Name)
+		}
+	}
+
+	items := append([]*widget.FormItem{widget.NewFormItem("Набор", preset)}, form.items()...)
+	content := container.NewVBox(
+		widget.NewForm(items...),
+		widget.NewLabel(fmt.Sprintf("Данные хранятся в единицах: %s.\nВыбор влияет на графики, формы и экспорт.",
+			models.StorageUnits.String())),
+	)
+
+	dlg := dialog.NewCustomWithoutButtons("Единицы измерения", content, s.window)
+	cancelBtn := widget.NewButton("Отмена", dlg.Hide)
+	saveBtn := widget.NewButton("Сохранить", func() {
+		units := form.units()
+		if err := s.memStorage.PutDisplayUnits(units); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось сохранить единицы: %w", err), s.window)
+			return
+		}
+		s.zLog.Infow("Display units changed", "units", units.String())
+		dlg.Hide()
+		if err := s.refreshTableOneChart(); err != nil {
+			dialog.ShowError(fmt.Errorf("единицы сохранены, но график блока 1 не обновлён: %w", err), s.window)
+		}
+	})
+	saveBtn.Importance = widget.HighImportance
+	dlg.SetButtons([]fyne.CanvasObject{cancelBtn, saveBtn})
+	dlg.Resize(fyne.NewSize(520, 380))
+	dlg.Show()
+}
+
+// formatFieldUnits описывает единицы колонок файла для отчёта импорта; пусто — перевод не понадобился.
+func formatFieldUnits(units []models.FieldUnit) string {
+	if len(units) == 0 {
+		return ""
+	}
+	parts := make([]string, len(units))
+	for i, u := range units {
+		source := "параметры импорта"
+		if u.FromHeader {
+			source = "заголовок"
+		}
+		parts[i] = fmt.Sprintf("%s: %s → %s (%s)", u.Field, u.Unit.Symbol(),
+			models.StorageUnits.Unit(models.FieldQuantities[u.Field]).Symbol(), source)
+	}
+	return "Переведены единицы: " + strings.Join(parts, "; ")
+}
@@ -6,12 +6,10 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/data/validation"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
 	chartService "github.com/lifedaemon-kill/burovichok-desktop/internal/service/chart"
-	"github.com/pkg/browser"
 
 	"os"
 	"strings"
@@ -26,6 +24,9 @@ func (s *Service) showMainMenu(ctx context.Context) {
 	chartsBtn := widget.NewButton("Создание графиков", func() { s.showChartsView(ctx) })
 	exportBtn := widget.NewButton("Экспортирование данных", func() { s.showExportView() })
 	guidebooksBtn := widget.NewButton("Редактирование справочников", func() { s.showGuidebookView(ctx) })
+	projectBtn := widget.NewButton("Файл проекта: сохранить / открыть", func() { s.showProjectView(ctx) })
+	workspaceBtn := widget.NewButton("Исследования: переключение и сравнение", func() { s.showWorkspaceView(ctx) })
+	tablesBtn := widget.NewButton("Таблицы блоков: просмотр и правка", func() { s.showDataView(ctx) })
 
 	// NewGridWrap принимает размер ячейки — и «упаковывает» каждый элемент в box этого размера
 	grid := container.NewGridWrap(cell,
@@ -34,6 +35,9 @@ func (s *Service) showMainMenu(ctx context.Context) {
 		chartsBtn,
 		exportBtn,
 		guidebooksBtn,
+		projectBtn,
+		workspaceBtn,
+		tablesBtn,
 	)
 
 	s.window.SetContent(container.NewCenter(grid))
@@ -41,8 +45,8 @@ func (s *Service) showMainMenu(ctx context.Context) {
 
 func (s *Service) showImportView(ctx context.Context) {
 	back := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
-	content := s.buildImportContent(ctx)
-	s.window.SetContent(container.NewBorder(back, nil, nil, nil, content))
+	content, refresh := s.buildImportContent(ctx)
+	s.showLiveView(container.NewBorder(back, nil, nil, nil, content), refresh)
 }
 
 func (s *Service) showReportsView(ctx context.Context) {
@@ -51,11 +55,16 @@ func (s *Service) showReportsView(ctx context.Context) {
 	reportBtn := widget.NewButton("Заполнить Шапку Отчета (Блок 5)", func() {
 		s.showBlockFiveForm(ctx)
 	})
+	// Блоки 1–4 сохраняются к отчёту, чтобы исследование можно было открыть без повторного импорта
+	saveBtn := widget.NewButton("Сохранить исследование в БД (Блоки 1–4)", func() { s.saveResearch(ctx) })
+	openBtn := widget.NewButton("Открыть сохранённое исследование", func() { s.showResearchList(ctx) })
 	s.window.SetContent(container.NewBorder(back, nil, nil, nil,
 		container.NewVBox(
 			widget.NewLabel("Отчёты"),
 			widget.NewSeparator(),
 			reportBtn,
+			saveBtn,
+			openBtn,
 		),
 	))
 }
@@ -63,175 +72,80 @@ func (s *Service) showReportsView(ctx context.Context) {
 func (s *Service) showChartsView(ctx context.Context) {
 	back := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
 	chartBtn1 := widget.NewButton("2. Интерактивный График Pзаб/Тзаб (Блок 1)", func() {
-		// вставьте сюда тот же код из Run для chartBtn1
-		blockOneData, err := s.memStorage.GetTableOneData()
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 1: %w", err), s.window)
-			return
-		}
-		if len(blockOneData) == 0 {
+		if s.memStorage.CountBlockOne() == 0 {
 			dialog.ShowInformation("Нет данных", "Недостаточно данных для построения графика", s.window)
 			return
 		}
-		htmlPath, err := s.chart.GenerateTableOneChart(blockOneData)
+		// график следит за данными блока 1, очисткой, гидростатикой и единицами
+		err := s.openLiveChart(models.DataBlockOne|models.DataSettings, func() (string, error) {
+			blockOneData, err := s.tableOneData()
+			if err != nil {
+				return "", fmt.Errorf("не удалось получить данные Блока 1: %w", err)
+			}
+			return s.chart.GenerateTableOneChart(blockOneData, s.displayUnits())
+		})
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("ошибка генерации HTML графика: %w", err), s.window)
-			return
-		}
-		s.serverMutex.Lock()
-		s.chartHtmlToServe = htmlPath
-		s.serverMutex.Unlock()
-		if err := s.startLocalWebServer(); err != nil {
-			s.serverMutex.Lock()
-			s.chartHtmlToServe = ""
-			s.serverMutex.Unlock()
-			dialog.ShowError(fmt.Errorf("ошибка запуска веб-сервера для графика: %w", err), s.window)
-			return
-		}
-		time.Sleep(150 * time.Millisecond)
-		s.serverMutex.Lock()
-		port := s.serverPort
-		s.serverMutex.Unlock()
-		if port == "" {
-			dialog.ShowError(fmt.Errorf("не удалось получить порт веб-сервера"), s.window)
-			return
-		}
-		url := fmt.Sprintf("http://127.0.0.1:%s/", port)
-		s.zLog.Debugw("Opening chart via web server", "url", url, "serving", htmlPath) // Логгируем
-
-		if err := browser.OpenURL(url); err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось открыть браузер: %w", err), s.window)
+			dialog.ShowError(err, s.window)
 		}
 	})
 
 	chartBtn2 := widget.NewButton("1. Интерактивный График Ртр, Рзтр, Рлин (Блок 2)", func() {
-		// 1) Создаём select
-		unitSelect := widget.NewSelect([]string{"kgf/cm2", "bar", "atm"}, func(string) {})
-		unitSelect.PlaceHolder = "Выберите единицу"
-
-		// 2) Упаковываем в контейнер
-		content := container.NewVBox(
-			widget.NewLabel("Единица измерения:"),
-			unitSelect,
-		)
-
-		// 3) Создаём диалог с «OK»/«Отмена»
-		dlg := dialog.NewCustomConfirm(
-			"Выбор единицы",
-			"OK", "Отмена",
-			content,
-			func(ok bool) {
-				if !ok {
-					// Отмена
-					return
-				}
-				unit := unitSelect.Selected
-				if unit == "" {
-					dialog.ShowInformation("Не выбрана единица",
-						"Пожалуйста, выберите единицу измерения",
-						s.window)
-					return
-				}
-
-				// 4) Ваш существующий код построения графика
-				blockTwoData, err := s.memStorage.GetTableTwoData()
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 2: %w", err), s.window)
-					return
-				}
-				if len(blockTwoData) == 0 {
-					dialog.ShowInformation("Нет данных",
-						"Недостаточно данных для построения графика",
-						s.window)
-					return
-				}
-				htmlPath, err := s.chart.GenerateTableTwoChart(blockTwoData, unit)
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("ошибка генерации HTML графика: %w", err), s.window)
-					return
-				}
-				s.serverMutex.Lock()
-				s.chartHtmlToServe = htmlPath
-				s.serverMutex.Unlock()
-				if err := s.startLocalWebServer(); err != nil {
-					s.serverMutex.Lock()
-					s.chartHtmlToServe = ""
-					s.serverMutex.Unlock()
-					dialog.ShowError(fmt.Errorf("ошибка запуска веб-сервера: %w", err), s.window)
-					return
-				}
-				// Небольшая задержка, чтобы сервер успел подняться
-				time.Sleep(150 * time.Millisecond)
-				s.serverMutex.Lock()
-				port := s.serverPort
-				s.serverMutex.Unlock()
-				if port == "" {
-					dialog.ShowError(fmt.Errorf("не удалось получить порт веб-сервера"), s.window)
-					return
-				}
-				url := fmt.Sprintf("http://127.0.0.1:%s/", port)
-				s.zLog.Debugw("Opening chart via web server", "url", url, "serving", htmlPath) // Логгируем
-
-				if err := browser.OpenURL(url); err != nil {
-					dialog.ShowError(fmt.Errorf("не удалось открыть браузер: %w", err), s.window)
-				}
-			},
-			s.window,
-		)
-
-		// Опционально: сразу задаём размер диалога, чтобы Select не обрезался
-		dlg.Resize(fyne.NewSize(240, 120))
-		dlg.Show()
-	})
-
-	chartBtn3 := widget.NewButton("3 Интерактивный график Дебитов (Блок 3)", func() {
-		blockThreeData, err := s.memStorage.GetTableThreeData()
-		if err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось получить данные Блока 3: %w", err), s.window)
-			return
-		}
-		if len(blockThreeData) == 0 {
+		if s.memStorage.CountBlockTwo() == 0 {
 			dialog.ShowInformation("Нет данных", "Недостаточно данных для построения графика", s.window)
 			return
 		}
-		htmlPath, err := s.chart.GenerateTableThreeChart(blockThreeData)
+		err := s.openLiveChart(models.DataBlockTwo|models.DataSettings, func() (string, error) {
+			blockTwoData, err := s.tableTwoData()
+			if err != nil {
+				return "", fmt.Errorf("не удалось получить данные Блока 2: %w", err)
+			}
+			return s.chart.GenerateTableTwoChart(blockTwoData, s.displayUnits())
+		})
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("ошибка генерации HTML графика: %w", err), s.window)
-			return
+			dialog.ShowError(err, s.window)
 		}
-		s.serverMutex.Lock()
-		s.chartHtmlToServe = htmlPath
-		s.serverMutex.Unlock()
-		if err := s.startLocalWebServer(); err != nil {
-			s.serverMutex.Lock()
-			s.chartHtmlToServe = ""
-			s.serverMutex.Unlock()
-			dialog.ShowError(fmt.Errorf("ошибка запуска веб-сервера для графика: %w", err), s.window)
+	})
+
+	chartBtn3 := widget.NewButton("3 Интерактивный график Дебитов (Блок 3)", func() {
+		if s.memStorage.CountBlockThree() == 0 {
+			dialog.ShowInformation("Нет данных", "Недостаточно данных для построения графика", s.window)
 			return
 		}
-		time.Sleep(150 * time.Millisecond)
-		s.serverMutex.Lock()
-		port := s.serverPort
-		s.serverMutex.Unlock()
-		if port == "" {
-			dialog.ShowError(fmt.Errorf("не удалось получить порт веб-сервера"), s.window)
-			return
+		err := s.openLiveChart(models.DataBlockThree|models.DataSettings, func() (string, error) {
+			blockThreeData, err := s.memStorage.GetTableThreeData()
+			if err != nil {
+				return "", fmt.Errorf("не удалось получить данные Блока 3: %w", err)
+			}
+			return s.chart.GenerateTableThreeChart(blockThreeData, s.displayUnits())
+		})
+		if err != nil {
+			dialog.ShowError(err, s.window)
 		}
-		url := fmt.Sprintf("http://127.0.0.1:%s/", port)
-		s.zLog.Debugw("Opening chart via web server", "url", url, "serving", htmlPath) // Логгируем
+	})
 
-		if err := browser.OpenURL(url); err != nil {
-			dialog.ShowError(fmt.Errorf("не удалось открыть браузер: %w", err), s.window)
+	// Переключатель исходных и очищенных рядов блоков 1 и 2 действует и на экспорт
+	cleanCheck := widget.NewCheck("Очищенные данные (Блоки 1 и 2)", nil)
+	if cfg, ok, err := s.memStorage.GetFilterConfig(); err == nil && ok {
+		cleanCheck.SetChecked(cfg.UseClean)
+	}
+	cleanCheck.OnChanged = func(useClean bool) {
+		if err := s.setUseClean(useClean); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось переключить очистку: %w", err), s.window)
 		}
-	})
+	}
 
 	s.window.SetContent(container.NewBorder(back, nil, nil, nil,
 		container.NewVBox(
 			widget.NewLabel("Графики"),
+			container.NewGridWithColumns(2, cleanCheck, widget.NewButton("Единицы измерения", s.editDisplayUnits)),
 			widget.NewSeparator(),
 			chartBtn2,
 			chartBtn1,
 			chartBtn3,
+			widget.NewButton("4. Диагностический график КВД (Блоки 1 и 3)", s.showBuildUpForm),
+			widget.NewButton("5. Интерпретация КВД: Хорнер / MDH", func() { s.showSemilogForm(ctx) }),
+			widget.NewButton("6. Продуктивность и IPR (Блоки 1 и 3)", s.showProductivityForm),
+			widget.NewButton("7. Сводный ряд на общей сетке времени (Блоки 1–3)", s.showResampleForm),
 		),
 	))
 }
@@ -239,27 +153,22 @@ func (s *Service) showChartsView(ctx context.Context) {
 func (s *Service) showExportView() {
 	s.zLog.Debugw("Open export view")
 
-	t1, _ := s.memStorage.GetTableOneData()
-	t2, _ := s.memStorage.GetTableTwoData()
+	t1, _ := s.tableOneData()
+	t2, _ := s.tableTwoData()
 	t3, _ := s.memStorage.GetTableThreeData()
 	t4, _ := s.memStorage.GetTableFourData()
 	t5, _ := s.memStorage.GetTableFiveData()
 
-	// Проверяем, есть ли элементы в директории
-	entries, err := os.ReadDir(chartService.HtmlChartsDirectory)
-	if err != nil {
-		s.zLog.Errorw("Директория "+chartService.HtmlChartsDirectory+" не создалась", err)
-		dialog.ShowError(fmt.Errorf("отсутствует директория с графиками"), s.window)
-		return
-	}
-	//Проверяем, что все три графика нарисованы
+	//Проверяем, что все три графика нарисованы; график КВД необязателен
 	//TODO надо удалять старые иначе именно они будут отправляться
-	if len(entries) != 3 {
-		s.zLog.Errorw("Директория "+chartService.HtmlChartsDirectory+" содержит не правильное число графиков", err)
-		dialog.ShowError(fmt.Errorf("директория "+chartService.HtmlChartsDirectory+" содержит не правильное число графиков, должно быть 3"), s.window)
-		return
+	for _, name := range []string{chartService.HTMLFileNameOne, chartService.HTMLFileNameTwo, chartService.HTMLFileNameThree} {
+		if _, err := os.Stat(name); err != nil {
+			s.zLog.Errorw("График не построен", "file", name, "error", err)
+			dialog.ShowError(fmt.Errorf("не построен график %s, должны быть построены все три графика", name), s.window)
+			return
+		}
 	}
-	arch, err := s.archiver.Archive(t1, t2, t3, t4, t5)
+	arch, err := s.archiver.Archive(t1, t2, t3, t4, t5, s.displayUnits())
 
 	if err != nil {
 		s.zLog.Errorw("Ошибка инициализации архива в буфер")
@@ -312,66 +221,3 @@ func (s *Service) showExportView() {
 	}() // Конец горутины
 
 }
-
-func (s *Service) showGuidebookView(ctx context.Context) {
-	s.zLog.Debugw("Opening Guidebook Management view")
-
-	backBtn := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
-
-	// Определяем типы справочников, которые можно редактировать
-	guidebookTypes := []string{
-		"Месторождение",         // -> oilfield
-		"Продуктивный горизонт", // -> productive_horizon
-		"Тип прибора",           // -> instrument_type
-		"Вид исследования",      // -> research_type
-	}
-	guidebookTypeSelect := widget.NewSelect(guidebookTypes, nil)
-	guidebookTypeSelect.PlaceHolder = "Выберите тип справочника"
-
-	newValueEntry := widget.NewEntry()
-	newValueEntry.PlaceHolder = "Введите новое значение"
-	newValueEntry.Validator = validation.NewRegexp(`.+`, "Поле не может быть пустым")
-
-	statusLabel := widget.NewLabel("")
-
-	addBtn := widget.NewButton("Добавить значение", func() {
-		statusLabel.SetText("")
-		selectedType := guidebookTypeSelect.Selected
-		newValue := strings.TrimSpace(newValueEntry.Text)
-
-		if selectedType == "" || newValue == "" {
-			dialog.ShowInformation("Ошибка", "Выберите тип справочника и введите непустое значение.", s.window)
-			return
-		}
-
-		err := newValueEntry.Validate()
-		if err != nil {
-			statusLabel.SetText("Ошибка валидации: " + err.Error())
-			return
-		}
-
-		err = s.addGuidebookEntry(ctx, selectedType, newValue)
-		if err != nil {
-			// Обрабатываем возможную ошибку уникальности (если уберем ON CONFLICT) или другую ошибку БД
-			// В случае ON CONFLICT DO NOTHING ошибки не будет при дубликате
-			s.zLog.Errorw("Failed to add guidebook entry", "type", selectedType, "value", newValue, "error", err)
-			dialog.ShowError(fmt.Errorf("Не удалось добавить значение: %w", err), s.window)
-			statusLabel.SetText("Ошибка при добавлении.")
-		} else {
-			s.zLog.Infow("Successfully added guidebook entry", "type", selectedType, "value", newValue)
-			statusLabel.SetText(fmt.Sprintf("Значение '%s' добавлено в '%s'.", newValue, selectedType))
-			newValueEntry.SetText("")
-		}
-	})
-
-	content := container.NewVBox(
-		widget.NewLabel("Управление справочниками"),
-		widget.NewSeparator(),
-		guidebookTypeSelect,
-		newValueEntry,
-		addBtn,
-		statusLabel,
-	)
-
-	s.window.SetContent(container.NewBorder(backBtn, nil, nil, nil, content))
-}
@@ -0,0 +1,405 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/service/calc"
+)
+
+// compareChannels — величины, которые можно сравнить между исследованиями.
+var compareChannels = []models.Channel{
+	models.ChannelPressureDepth, models.ChannelPressureAtVDP, models.ChannelTemperatureDepth,
+	models.ChannelPressureTubing, models.ChannelPressureAnnulus, models.ChannelPressureLinear,
+	models.ChannelLiquidFlowRate, models.ChannelOilFlowRate, models.ChannelWaterFlowRate,
+	models.ChannelWaterCut, models.ChannelGasFlowRate, models.ChannelGasFactor,
+}
+
+// showWorkspaceView показывает исследования в памяти: активное можно переключить, выбранные отмеченные —
+// сравнить на одном графике или выгрузить.
+func (s *Service) showWorkspaceView(ctx context.Context) {
+	back := widget.NewButton("◀ Домой", func() { s.showMainMenu(ctx) })
+
+	researches, err := s.memStorage.ListResearches()
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("не удалось получить список исследований: %w", err), s.window)
+		return
+	}
+	checked := map[int]bool{} // отмеченные для сравнения и выгрузки, по ID
+	selected := -1
+
+	var list *widget.List
+	reload := func() {
+		if researches, err = s.memStorage.ListResearches(); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось получить список исследований: %w", err), s.window)
+			return
+		}
+		selected = -1
+		list.UnselectAll()
+		list.Refresh()
+		s.updateTitle()
+	}
+	list = widget.NewList(
+		func() int { return len(researches) },
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, widget.NewCheck("", nil), nil, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			r := researches[id]
+			row := o.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(formatResearchSummary(r))
+			check := row.Objects[1].(*widget.Check)
+			check.OnChanged = nil
+			check.SetChecked(checked[r.ID])
+			check.OnChanged = func(on bool) { checked[r.ID] = on }
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	// marked возвращает отмеченные исследования в порядке списка
+	marked := func() []models.ResearchSummary {
+		var out []models.ResearchSummary
+		for _, r := range researches {
+			if checked[r.ID] {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+
+	newBtn := widget.NewButton("Новое", func() {
+		if s.importBusy("Новое исследование") {
+			return
+		}
+		if _, err := s.memStorage.CreateResearch(""); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось создать исследование: %w", err), s.window)
+			return
+		}
+		reload()
+		s.activeResearchChanged()
+	})
+	activateBtn := widget.NewButton("Сделать активным", func() {
+		if selected < 0 || s.importBusy("Переключение исследования") {
+			return
+		}
+		if err := s.memStorage.SetActiveResearch(researches[selected].ID); err != nil {
+			dialog.ShowError(fmt.Errorf("не удалось переключить исследование: %w", err), s.window)
+			return
+		}
+		reload()
+		s.activeResearchChanged()
+	})
+	activateBtn.Importance = widget.HighImportance
+	renameBtn := widget.NewButton("Переименовать", func() {
+		if selected < 0 {
+			return
+		}
+		r := researches[selected]
+		entry := widget.NewEntry()
+		entry.SetText(r.Name)
+		dialog.ShowForm("Переименование", "Сохранить", "Отмена",
+			[]*widget.FormItem{widget.NewFormItem("Название", entry)},
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				if err := s.memStorage.RenameResearch(r.ID, entry.Text); err != nil {
+					dialog.ShowError(err, s.window)
+					return
+				}
+				reload()
+			}, s.window)
+	})
+	removeBtn := widget.NewButton("Убрать из памяти", func() {
+		if selected < 0 {
+			return
+		}
+		r := researches[selected]
+		dialog.ShowConfirm("Удаление", fmt.Sprintf("Убрать из памяти исследование «%s»? Несохранённые данные будут потеряны.", r.Name),
+			func(ok bool) {
+				if !ok || r.Active && s.importBusy("Удаление") {
+					return
+				}
+				if err := s.memStorage.RemoveResearch(r.ID); err != nil {
+					dialog.ShowError(err, s.window)
+					return
+				}
+				s.projectMu.Lock()
+				delete(s.projectPaths, r.ID)
+				s.projectMu.Unlock()
+				delete(checked, r.ID)
+				s.zLog.Infow("Research removed from workspace", "id", r.ID, "name", r.Name)
+				reload()
+				if r.Active {
+					s.activeResearchChanged()
+				}
+			}, s.window)
+	})
+	compareBtn := widget.NewButton("Сравнить отмеченные…", func() {
+		if rs := marked(); len(rs) > 0 {
+			s.showComparisonForm(rs)
+			return
+		}
+		dialog.ShowInformation("Сравнение", "Отметьте исследования для сравнения", s.window)
+	})
+	exportBtn := widget.NewButton("Выгрузить отмеченные в ZIP…", func() {
+		if rs := marked(); len(rs) > 0 {
+			s.exportResearches(rs)
+			return
+		}
+		dialog.ShowInformation("Выгрузка", "Отметьте исследования для выгрузки", s.window)
+	})
+
+	s.importProgress.lockWhileRunning(newBtn, activateBtn, removeBtn)
+
+	actions := container.NewVBox(
+		widget.NewLabel("Активное исследование получает импорт, графики, расчёты и файл проекта."),
+		container.NewGridWithColumns(4, newBtn, activateBtn, renameBtn, removeBtn),
+		container.NewGridWithColumns(2, compareBtn, exportBtn),
+	)
+	// строки списка обновляются сами, например во время импорта; выбор остаётся, пока порядок тот же
+	s.showLiveView(container.NewBorder(
+		container.NewVBox(back, widget.NewLabel("Исследования в памяти")), actions, nil, nil, list),
+		func(models.DataBlocks) {
+			n := len(researches)
+			if researches, err = s.memStorage.ListResearches(); err != nil {
+				s.zLog.Errorw("Research list not refreshed", "error", err)
+				return
+			}
+			if len(researches) != n {
+				selected = -1
+				list.UnselectAll()
+			}
+			list.Refresh()
+		})
+}
+
+// activeResearchChanged обновляет то, что зависит от активного исследования: заголовок окна и график блока 1.
+func (s *Service) activeResearchChanged() {
+	s.updateTitle()
+	s.zLog.Infow("Active research changed", "id", s.memStorage.ActiveResearch().ID)
+	if err := s.refreshTableOneChart(); err != nil {
+		dialog.ShowError(fmt.Errorf("график блока 1 не обновлён: %w", err), s.window)
+	}
+}
+
+// researchBlocks возвращает блоки 1–3 исследования так же, как для активного их берут графики и расчёты:
+// с очисткой, если она включена, и с Рзаб на ВДП по параметрам гидростатики.
+func (s *Service) researchBlocks(p models.Project) ([]models.TableOne, []models.TableTwo, []models.TableThree, error) {
+	t1, t2 := p.One, p.Two
+	if cfg := p.FilterConfig; cfg != nil && cfg.UseClean {
+		var err error
+		if t1, _, err = s.filter.TableOne(t1, cfg.Options); err != nil {
+			return nil, nil, nil, err
+		}
+		if t2, _, err = s.filter.TableTwo(t2, cfg.Options); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if p.OperationConfig != nil {
+		t1 = calc.TableOneSeries(t1, *p.OperationConfig)
+	}
+	return t1, t2, p.Three, nil
+}
+
+// comparison собирает величину ch отмеченных исследований. skipped — исследования, в которых её нет.
+func (s *Service) comparison(rs []models.ResearchSummary, ch models.Channel, relative bool) (models.Comparison, []string, error) {
+	c := models.Comparison{Channel: ch, Relative: relative}
+	var skipped []string
+	for _, r := range rs {
+		p, err := s.memStorage.GetResearch(r.ID)
+		if err != nil {
+			return c, nil, err
+		}
+		t1, t2, t3, err := s.researchBlocks(p)
+		if err != nil {
+			return c, nil, fmt.Errorf("исследование «%s»: %w", r.Name, err)
+		}
+		sr, ok := s.resampler.Series(t1, t2, t3, ch)
+		if !ok {
+			skipped = append(skipped, r.Name)
+			continue
+		}
+		c.Series = append(c.Series, models.ComparisonSeries{Research: r.Title(), Times: sr.Times, Values: sr.Values})
+	}
+	if len(c.Series) == 0 {
+		return c, skipped, fmt.Errorf("ни в одном из отмеченных исследований нет величины «%s»", ch.Title())
+	}
+	return c, skipped, nil
+}
+
+// showComparisonForm предлагает выбрать величину и ось времени и строит сравнение исследований rs.
+func (s *Service) showComparisonForm(rs []models.ResearchSummary) {
+	units := s.displayUnits()
+	labels := make([]string, len(compareChannels))
+	for i, ch := range compareChannels {
+		labels[i] = ch.Label(units)
+	}
+	channelSelect := widget.NewSelect(labels, nil)
+	channelSelect.SetSelectedIndex(0)
+	relativeCheck := widget.NewCheck("Время от начала каждого исследования (для исследований разных дат)", nil)
+	relativeCheck.SetChecked(true)
+
+	names := make([]string, len(rs))
+	for i, r := range rs {
+		names[i] = r.Title()
+	}
+	content := container.NewVBox(
+		widget.NewLabel("Исследования: "+strings.Join(names, "; ")),
+		widget.NewForm(widget.NewFormItem("Величина", channelSelect)),
+		relativeCheck,
+	)
+
+	// build собирает сравнение; ошибки показываются здесь же
+	build := func() (models.Comparison, bool) {
+		c, skipped, err := s.comparison(rs, compareChannels[channelSelect.SelectedIndex()], relativeCheck.Checked)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return c, false
+		}
+		if len(skipped) > 0 {
+			s.zLog.Infow("Researches without channel skipped", "channel", c.Channel, "skipped", skipped)
+			dialog.ShowInformation("Сравнение", fmt.Sprintf("Нет величины «%s» в исследованиях: %s",
+				c.Channel.Title(), strings.Join(skipped, "; ")), s.window)
+		}
+		return c, true
+	}
+
+	dlg := dialog.NewCustomWithoutButtons("Сравнение исследований", content, s.window)
+	closeBtn := widget.NewButton("Закрыть", dlg.Hide)
+	chartBtn := widget.NewButton("Построить график", func() {
+		c, ok := build()
+		if !ok {
+			return
+		}
+		htmlPath, err := s.chart.GenerateComparisonChart(c, s.displayUnits())
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("ошибка генерации HTML графика: %w", err), s.window)
+			return
+		}
+		if err := s.openChart(htmlPath); err != nil {
+			dialog.ShowError(err, s.window)
+		}
+	})
+	chartBtn.Importance = widget.HighImportance
+	saveBtn := widget.NewButton("Сохранить в Excel", func() {
+		c, ok := build()
+		if !ok {
+			return
+		}
+		buf, err := s.archiver.ComparisonXLSX(c, s.displayUnits())
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		d := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, s.window)
+				return
+			}
+			if w == nil {
+				return
+			}
+			defer w.Close()
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				dialog.ShowError(fmt.Errorf("не удалось записать файл: %w", err), s.window)
+				return
+			}
+			dialog.ShowInformation("Сохранено", fmt.Sprintf("Исследований: %d\n%s", len(c.Series), w.URI().Path()), s.window)
+		}, s.window)
+		d.SetFileName("comparison.xlsx")
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".xlsx"}))
+		d.Show()
+	})
+	dlg.SetButtons([]fyne.CanvasObject{closeBtn, saveBtn, chartBtn})
+	dlg.Resize(fyne.NewSize(560, 260))
+	dlg.Show()
+}
+
+// exportResearches выгружает каждое исследование rs в свой ZIP-архив блоков 1–5 в выбранной папке.
+func (s *Service) exportResearches(rs []models.ResearchSummary) {
+	dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if dir == nil {
+			return
+		}
+		var written, failed []string
+		s.runWithProgress("Выгрузка исследований...", func() error {
+			for _, r := range rs {
+				path, err := s.exportResearch(r, dir.Path())
+				if err != nil {
+					s.zLog.Errorw("Research export failed", "id", r.ID, "error", err)
+					failed = append(failed, fmt.Sprintf("%s: %v", r.Name, err))
+					continue
+				}
+				written = append(written, path)
+			}
+			return nil
+		}, func() {
+			msg := fmt.Sprintf("Выгружено: %d из %d", len(written), len(rs))
+			if len(written) > 0 {
+				msg += "\n" + strings.Join(written, "\n")
+			}
+			if len(failed) > 0 {
+				msg += "\n\nНе выгружены:\n" + strings.Join(failed, "\n")
+			}
+			dialog.ShowInformation("Выгрузка", msg, s.window)
+		})
+	}, s.window)
+}
+
+func (s *Service) exportResearch(r models.ResearchSummary, dir string) (string, error) {
+	p, err := s.memStorage.GetResearch(r.ID)
+	if err != nil {
+		return "", err
+	}
+	t1, t2, t3, err := s.researchBlocks(p)
+	if err != nil {
+		return "", err
+	}
+	buf, err := s.archiver.Archive(t1, t2, t3, p.Four, p.Five, s.displayUnits())
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fileSafeName(r.Name)+".zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("не удалось записать %s: %w", path, err)
+	}
+	return path, nil
+}
+
+func formatResearchSummary(r models.ResearchSummary) string {
+	mark := ""
+	if r.Active {
+		mark = "▶ "
+	}
+	return fmt.Sprintf("%s%s — Блок 1: %d, Блок 2: %d, Блок 3: %d, Блок 4: %d строк",
+		mark, r.Title(), r.Rows[0], r.Rows[1], r.Rows[2], r.Rows[3])
+}
+
+// fileSafeName заменяет в названии символы, недопустимые в именах файлов.
+func fileSafeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?* `, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "research"
+	}
+	return name
+}
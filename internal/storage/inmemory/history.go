@@ -0,0 +1,380 @@
+package inmemory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// maxHistory — сколько операций хранится для отмены. Снимки держат в памяти удалённые строки,
+// поэтому старые операции забываются.
+const maxHistory = 50
+
+// change — записанная операция: описание и состояния исследования до и после неё. Правка строк
+// записывается самими правками (edits), без снимков: иначе каждая правка одной ячейки держала бы
+// в истории копию всего блока.
+type change struct {
+	info          models.Change
+	before, after state
+	edits         []rowEdit
+}
+
+// rowEdit — замена одной строки блока. put записывает в st на место строки её прежнее значение
+// (undo) или новое; срез блока в st должен быть уже скопирован, см. withEdits.
+type rowEdit struct {
+	block int // 0–3: блок 1–4
+	put   func(st *state, undo bool)
+}
+
+// blockData — флаги изменения блоков 1–4 по номеру блока.
+var blockData = [4]models.DataBlocks{models.DataBlockOne, models.DataBlockTwo, models.DataBlockThree, models.DataBlockFour}
+
+// withEdits возвращает состояние с применёнными правками строк, при undo — с отменёнными в обратном
+// порядке. Каждый затронутый блок копируется один раз: прежний массив видят снимки истории и читатели.
+func (st state) withEdits(edits []rowEdit, undo bool) state {
+	var copied [4]bool
+	for k := range edits {
+		e := edits[k]
+		if undo {
+			e = edits[len(edits)-1-k]
+		}
+		if !copied[e.block] {
+			st.copyBlock(e.block)
+			copied[e.block] = true
+		}
+		e.put(&st, undo)
+	}
+	return st
+}
+
+// copyBlock заменяет срез блока block (0–3) копией.
+func (st *state) copyBlock(block int) {
+	switch block {
+	case 0:
+		st.blockOne = slices.Clone(st.blockOne)
+	case 1:
+		st.blockTwo = slices.Clone(st.blockTwo)
+	case 2:
+		st.blockThree = slices.Clone(st.blockThree)
+	case 3:
+		st.blockFour = slices.Clone(st.blockFour)
+	}
+}
+
+// editsOnly сообщает, что после последней записи исследование изменили только правки строк r.edits:
+// тогда операция записывается правками, иначе — снимками.
+func (r *research) editsOnly() bool {
+	if len(r.edits) == 0 || r.state.lens() != r.base.lens() {
+		return false
+	}
+	var touched models.DataBlocks
+	for _, e := range r.edits {
+		touched |= blockData[e.block]
+	}
+	return r.state.diff(r.base) == touched
+}
+
+// lens возвращает число строк блоков 1–4.
+func (st state) lens() [4]int {
+	return [4]int{len(st.blockOne), len(st.blockTwo), len(st.blockThree), len(st.blockFour)}
+}
+
+// clip ограничивает ёмкость срезов их длиной: следующий append скопирует данные в новый массив
+// и не перезапишет строки, которые видят снимки истории.
+func (st state) clip() state {
+	st.blockOne = st.blockOne[:len(st.blockOne):len(st.blockOne)]
+	st.blockTwo = st.blockTwo[:len(st.blockTwo):len(st.blockTwo)]
+	st.blockThree = st.blockThree[:len(st.blockThree):len(st.blockThree)]
+	st.blockFour = st.blockFour[:len(st.blockFour):len(st.blockFour)]
+	st.sources = st.sources[:len(st.sources):len(st.sources)]
+	for b := range st.origins {
+		st.origins[b] = st.origins[b][:len(st.origins[b]):len(st.origins[b])]
+	}
+	return st
+}
+
+// pending сообщает, что после последней записи в историю есть изменения. Строки не меняются
+// на месте, поэтому хватает сравнить срезы и настройки.
+func (r *research) pending() bool {
+	return r.state.diff(r.base) != 0
+}
+
+// record записывает изменения после последней записи как операцию; вызывается под блокировкой записи.
+func (r *research) record(info models.Change) models.Change {
+	info.ID = r.nextChange
+	info.At = time.Now().UTC()
+	after, before := r.state.lens(), r.base.lens()
+	for b := range info.Rows {
+		info.Rows[b] = after[b] - before[b]
+	}
+	r.nextChange++
+	c := change{info: info, before: r.base, after: r.state}
+	if r.editsOnly() {
+		c = change{info: info, edits: r.edits}
+	}
+	r.edits = nil
+	r.done = append(r.done, c)
+	if len(r.done) > maxHistory {
+		r.done = append([]change(nil), r.done[len(r.done)-maxHistory:]...)
+	}
+	// новая операция делает отменённые неповторимыми: их состояние больше не продолжает текущее
+	r.undone = nil
+	r.base = r.state
+	return info
+}
+
+// restore делает st текущим состоянием и точкой следующей записи.
+func (r *research) restore(st state) {
+	r.state = st.clip()
+	r.base = r.state
+	r.edits = nil
+}
+
+// target возвращает состояние после отмены операции c (undo) или её повтора.
+func (c change) target(cur state, undo bool) state {
+	switch {
+	case c.edits != nil:
+		return cur.withEdits(c.edits, undo)
+	case undo:
+		return c.before
+	default:
+		return c.after
+	}
+}
+
+// RecordChange записывает изменения активного исследования после последней записи одной операцией.
+// Настройки и шапка отчёта строк не меняют: незаписанные строки при них — это идущий импорт, и его
+// строки ушли бы в чужую операцию, а сам импорт остался бы без строк.
+func (s *Storage) RecordChange(kind models.ChangeKind, title string) (models.Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.cur
+	if (kind == models.ChangeSettings || kind == models.ChangeReport) && r.state.diff(r.base).Has(models.DataRows) {
+		return models.Change{}, errors.New("есть незаписанные строки: дождитесь завершения импорта")
+	}
+	return r.record(models.Change{Kind: kind, Title: title}), nil
+}
+
+// OpenResearch заменяет данные и настройки активного исследования данными p — проектом или исследованием
+// из БД — и записывает это операцией title. Единицы отображения общие для всех исследований и здесь
+// не меняются. Пометки строк p, если они есть, должны совпадать по длине с блоками.
+func (s *Storage) OpenResearch(p models.Project, title string) (models.Change, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	if err := r.editable(); err != nil {
+		return models.Change{}, err
+	}
+	st := emptyState()
+	st.blockOne = append(st.blockOne, p.One...)
+	st.blockTwo = append(st.blockTwo, p.Two...)
+	st.blockThree = append(st.blockThree, p.Three...)
+	st.blockFour = append(st.blockFour, p.Four...)
+	st.blockFive = p.Five
+	if p.OperationConfig != nil {
+		cfg := *p.OperationConfig
+		st.opConfig = &cfg
+	}
+	if p.FilterConfig != nil {
+		cfg := *p.FilterConfig
+		st.filter = &cfg
+	}
+	st.sources = append([]models.ImportSource(nil), p.Sources...)
+	lens := st.lens()
+	for b, origins := range p.RowSources {
+		switch len(origins) {
+		case 0:
+			// в старых файлах проекта и в БД пометок нет: строки остаются без файла
+			st.origins[b] = make([]int, lens[b])
+		case lens[b]:
+			st.origins[b] = append([]int(nil), origins...)
+		default:
+			return models.Change{}, errors.Newf("пометок строк блока %d: %d, строк: %d", b+1, len(origins), lens[b])
+		}
+	}
+	for _, src := range st.sources {
+		r.nextSource = max(r.nextSource, src.ID+1)
+	}
+	s.changed(r.id, r.state.diff(st))
+	r.state = st
+	return r.record(models.Change{Kind: models.ChangeOpen, Title: title}), nil
+}
+
+// CommitImport записывает оставленный импорт: строки, добавленные после последней записи, помечаются
+// новым ID импорта, файл попадает в список импортированных.
+func (s *Storage) CommitImport(src models.ImportSource) (models.Change, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	src.ID = r.tagPending()
+	r.sources = append(r.sources, src)
+	s.changed(r.id, models.DataSources)
+	return r.record(models.Change{Kind: models.ChangeImport, Title: src.Block, Source: src.ID, File: src.File}), nil
+}
+
+// ReplaceImport записывает повторный импорт файла: строки, добавленные после последней записи,
+// помечаются новым ID, а строки прежнего импорта id удаляются. Файл занимает место прежнего в списке.
+func (s *Storage) ReplaceImport(id int, src models.ImportSource) (models.Change, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	pos := r.sourceIndex(id)
+	if pos < 0 {
+		return models.Change{}, errors.Newf("импорт %d не найден", id)
+	}
+	if !r.tagged(id) {
+		return models.Change{}, errors.Newf("строки файла %s не помечены: он открыт из БД или старого файла проекта", r.sources[pos].File)
+	}
+	before := r.state
+	src.ID = r.tagPending()
+	r.removeRows(id)
+	sources := make([]models.ImportSource, len(r.sources))
+	copy(sources, r.sources)
+	sources[pos] = src
+	r.sources = sources
+	s.changed(r.id, before.diff(r.state))
+	return r.record(models.Change{Kind: models.ChangeReimport, Title: src.Block, Source: src.ID, File: src.File}), nil
+}
+
+// tagPending помечает строки, добавленные после последней записи, новым ID импорта и возвращает его.
+func (r *research) tagPending() int {
+	id := r.nextSource
+	r.nextSource++
+	// строки после base не видны ни одному снимку, их пометки можно менять на месте
+	for b := range r.origins {
+		for i := len(r.base.origins[b]); i < len(r.origins[b]); i++ {
+			if r.origins[b][i] == 0 {
+				r.origins[b][i] = id
+			}
+		}
+	}
+	return id
+}
+
+// sourceIndex возвращает позицию импорта id в списке файлов или -1.
+func (r *research) sourceIndex(id int) int {
+	for i, src := range r.sources {
+		if src.ID == id && id != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeRows заменяет блоки срезами без строк импорта id.
+func (r *research) removeRows(id int) {
+	r.blockOne, r.origins[0] = without(r.blockOne, r.origins[0], id)
+	r.blockTwo, r.origins[1] = without(r.blockTwo, r.origins[1], id)
+	r.blockThree, r.origins[2] = without(r.blockThree, r.origins[2], id)
+	r.blockFour, r.origins[3] = without(r.blockFour, r.origins[3], id)
+}
+
+// RemoveImportSource удаляет из активного исследования строки импорта id и сам файл из списка.
+func (s *Storage) RemoveImportSource(id int) (models.Change, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	if r.pending() {
+		return models.Change{}, errors.New("дождитесь завершения импорта")
+	}
+	pos := r.sourceIndex(id)
+	if pos < 0 {
+		return models.Change{}, errors.Newf("импорт %d не найден", id)
+	}
+	src := r.sources[pos]
+	if !r.tagged(id) {
+		return models.Change{}, errors.Newf("строки файла %s не помечены: он открыт из БД или старого файла проекта", src.File)
+	}
+	before := r.state
+	r.removeRows(id)
+	sources := make([]models.ImportSource, 0, len(r.sources)-1)
+	r.sources = append(append(sources, r.sources[:pos]...), r.sources[pos+1:]...)
+	s.changed(r.id, before.diff(r.state))
+	return r.record(models.Change{Kind: models.ChangeRemoveSource, Title: src.Block, Source: id, File: src.File}), nil
+}
+
+// tagged сообщает, что хотя бы одна строка помечена импортом id.
+func (r *research) tagged(id int) bool {
+	for _, origins := range r.origins {
+		for _, o := range origins {
+			if o == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// without возвращает новые срезы строк и пометок без строк импорта id; исходные не меняются.
+func without[T any](data []T, origins []int, id int) ([]T, []int) {
+	outData := make([]T, 0, len(data))
+	outOrigins := make([]int, 0, len(origins))
+	for i, o := range origins {
+		if o != id {
+			outData = append(outData, data[i])
+			outOrigins = append(outOrigins, o)
+		}
+	}
+	return outData, outOrigins
+}
+
+// Undo отменяет последнюю операцию активного исследования и возвращает её.
+func (s *Storage) Undo() (models.Change, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	if r.pending() {
+		return models.Change{}, errors.New("есть незаписанные изменения: дождитесь завершения импорта")
+	}
+	if len(r.done) == 0 {
+		return models.Change{}, errors.New("нечего отменять")
+	}
+	c := r.done[len(r.done)-1]
+	r.done = r.done[:len(r.done)-1]
+	st := c.target(r.state, true)
+	s.changed(r.id, r.state.diff(st))
+	r.restore(st)
+	c.info.Undone = true
+	r.undone = append(r.undone, c)
+	return c.info, nil
+}
+
+// Redo повторяет последнюю отменённую операцию активного исследования и возвращает её.
+func (s *Storage) Redo() (models.Change, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	if r.pending() {
+		return models.Change{}, errors.New("есть незаписанные изменения: дождитесь завершения импорта")
+	}
+	if len(r.undone) == 0 {
+		return models.Change{}, errors.New("нечего повторять")
+	}
+	c := r.undone[len(r.undone)-1]
+	r.undone = r.undone[:len(r.undone)-1]
+	st := c.target(r.state, false)
+	s.changed(r.id, r.state.diff(st))
+	r.restore(st)
+	c.info.Undone = false
+	r.done = append(r.done, c)
+	return c.info, nil
+}
+
+// History возвращает операции активного исследования: выполненные по порядку, затем отменённые
+// в порядке, в котором их повторит Redo.
+func (s *Storage) History() ([]models.Change, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.cur
+	out := make([]models.Change, 0, len(r.done)+len(r.undone))
+	for _, c := range r.done {
+		out = append(out, c.info)
+	}
+	for i := len(r.undone) - 1; i >= 0; i-- {
+		out = append(out, r.undone[i].info)
+	}
+	return out, nil
+}
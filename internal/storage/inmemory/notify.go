@@ -0,0 +1,122 @@
+package inmemory
+
+import (
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// subscription — подписчик на изменения данных.
+type subscription struct {
+	id       int
+	research int // 0 — все исследования
+	blocks   models.DataBlocks
+	fn       func(models.DataChange)
+}
+
+// wants сообщает, что подписчику нужно уведомление c.
+func (sub subscription) wants(c models.DataChange) bool {
+	if sub.research != 0 && c.Research != 0 && c.Research != sub.research {
+		return false
+	}
+	return sub.blocks.Has(c.Blocks)
+}
+
+// Subscribe подписывает fn на изменения частей blocks исследования research (0 — всех исследований)
+// и возвращает функцию отписки. fn вызывается после изменения в той горутине, которая его сделала,
+// когда хранилище уже разблокировано: из fn можно читать данные. Импорт пишет данные порциями,
+// поэтому уведомления идут часто — долгую работу подписчик должен откладывать и объединять сам.
+func (s *Storage) Subscribe(research int, blocks models.DataBlocks, fn func(models.DataChange)) func() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	s.nextSub++
+	id := s.nextSub
+	s.subs = append(s.subs, subscription{id: id, research: research, blocks: blocks, fn: fn})
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		for i, sub := range s.subs {
+			if sub.id == id {
+				// новый срез: notify может сейчас обходить прежний
+				s.subs = append(append([]subscription(nil), s.subs[:i]...), s.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// changed запоминает изменение частей blocks исследования research (0 — всех) и увеличивает его
+// счётчик изменений; вызывается под блокировкой записи. Уведомления уходят в unlock.
+func (s *Storage) changed(research int, blocks models.DataBlocks) {
+	if blocks == 0 {
+		return
+	}
+	for _, r := range s.researches {
+		if research == 0 || r.id == research {
+			r.revision++
+		}
+	}
+	for i := range s.changes {
+		if s.changes[i].Research == research {
+			s.changes[i].Blocks |= blocks
+			return
+		}
+	}
+	s.changes = append(s.changes, models.DataChange{Research: research, Blocks: blocks})
+}
+
+// unlock снимает блокировку записи и рассылает изменения, накопленные под ней.
+func (s *Storage) unlock() {
+	changes := s.changes
+	s.changes = nil
+	s.mu.Unlock()
+	if len(changes) == 0 {
+		return
+	}
+	s.subMu.Lock()
+	subs := s.subs
+	s.subMu.Unlock()
+	for _, c := range changes {
+		for _, sub := range subs {
+			if sub.wants(c) {
+				sub.fn(c)
+			}
+		}
+	}
+}
+
+// diff возвращает части данных, которыми st отличается от other. Строки не меняются на месте,
+// поэтому блоки сравниваются по длине и массиву.
+func (st state) diff(other state) models.DataBlocks {
+	var out models.DataBlocks
+	if !same(st.blockOne, other.blockOne) {
+		out |= models.DataBlockOne
+	}
+	if !same(st.blockTwo, other.blockTwo) {
+		out |= models.DataBlockTwo
+	}
+	if !same(st.blockThree, other.blockThree) {
+		out |= models.DataBlockThree
+	}
+	if !same(st.blockFour, other.blockFour) {
+		out |= models.DataBlockFour
+	}
+	if st.blockFive != other.blockFive {
+		out |= models.DataBlockFive
+	}
+	if st.opConfig != other.opConfig || st.filter != other.filter {
+		out |= models.DataSettings
+	}
+	if !same(st.sources, other.sources) {
+		out |= models.DataSources
+	}
+	for b := range st.origins {
+		if !same(st.origins[b], other.origins[b]) {
+			out |= models.DataSources
+		}
+	}
+	return out
+}
+
+// same сообщает, что срезы одной длины и смотрят на один массив.
+func same[T any](a, b []T) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
@@ -0,0 +1,151 @@
+package inmemory
+
+import (
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// UpdateTableOneRow заменяет строку i блока 1.
+func (s *Storage) UpdateTableOneRow(i int, row models.TableOne) error {
+	return updateRow(s, 0, func(st *state) *[]models.TableOne { return &st.blockOne }, i, row)
+}
+
+// UpdateTableTwoRow заменяет строку i блока 2.
+func (s *Storage) UpdateTableTwoRow(i int, row models.TableTwo) error {
+	return updateRow(s, 1, func(st *state) *[]models.TableTwo { return &st.blockTwo }, i, row)
+}
+
+// UpdateTableThreeRow заменяет строку i блока 3. Расчётные дебиты пересчитывает вызывающий.
+func (s *Storage) UpdateTableThreeRow(i int, row models.TableThree) error {
+	return updateRow(s, 2, func(st *state) *[]models.TableThree { return &st.blockThree }, i, row)
+}
+
+// UpdateTableFourRow заменяет строку i блока 4.
+func (s *Storage) UpdateTableFourRow(i int, row models.TableFour) error {
+	return updateRow(s, 3, func(st *state) *[]models.TableFour { return &st.blockFour }, i, row)
+}
+
+// updateRow заменяет строку i блока block (0–3), срез которого возвращает rows, и запоминает правку:
+// в историю она попадёт строкой до и после, а не копиями блока.
+func updateRow[T any](s *Storage, block int, rows func(*state) *[]T, i int, row T) error {
+	s.mu.Lock()
+	defer s.unlock()
+	r := s.cur
+	if err := r.editable(); err != nil {
+		return err
+	}
+	data := *rows(&r.state)
+	if i < 0 || i >= len(data) {
+		return errors.Newf("строки %d нет в блоке", i+1)
+	}
+	old := data[i]
+	e := rowEdit{block: block, put: func(st *state, undo bool) {
+		if undo {
+			(*rows(st))[i] = old
+		} else {
+			(*rows(st))[i] = row
+		}
+	}}
+	r.state = r.state.withEdits([]rowEdit{e}, false)
+	r.edits = append(r.edits, e)
+	s.changed(r.id, blockData[block])
+	return nil
+}
+
+// DeleteTableOneRows удаляет из блока 1 строки с номерами idx.
+func (s *Storage) DeleteTableOneRows(idx []int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	data, origins, err := dropRows(s.cur.blockOne, s.cur.origins[0], idx)
+	if err != nil || len(data) == len(s.cur.blockOne) {
+		return err
+	}
+	s.cur.blockOne, s.cur.origins[0] = data, origins
+	s.changed(s.cur.id, models.DataBlockOne)
+	return nil
+}
+
+// DeleteTableTwoRows удаляет из блока 2 строки с номерами idx.
+func (s *Storage) DeleteTableTwoRows(idx []int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	data, origins, err := dropRows(s.cur.blockTwo, s.cur.origins[1], idx)
+	if err != nil || len(data) == len(s.cur.blockTwo) {
+		return err
+	}
+	s.cur.blockTwo, s.cur.origins[1] = data, origins
+	s.changed(s.cur.id, models.DataBlockTwo)
+	return nil
+}
+
+// DeleteTableThreeRows удаляет из блока 3 строки с номерами idx.
+func (s *Storage) DeleteTableThreeRows(idx []int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	data, origins, err := dropRows(s.cur.blockThree, s.cur.origins[2], idx)
+	if err != nil || len(data) == len(s.cur.blockThree) {
+		return err
+	}
+	s.cur.blockThree, s.cur.origins[2] = data, origins
+	s.changed(s.cur.id, models.DataBlockThree)
+	return nil
+}
+
+// DeleteTableFourRows удаляет из блока 4 строки с номерами idx.
+func (s *Storage) DeleteTableFourRows(idx []int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	data, origins, err := dropRows(s.cur.blockFour, s.cur.origins[3], idx)
+	if err != nil || len(data) == len(s.cur.blockFour) {
+		return err
+	}
+	s.cur.blockFour, s.cur.origins[3] = data, origins
+	s.changed(s.cur.id, models.DataBlockFour)
+	return nil
+}
+
+// editable сообщает, можно ли править строки, очищать исследование или сменить активное: незаписанные
+// изменения — это идущий импорт, его порции пишутся в активное исследование, а откат обрезает блоки
+// по числу строк и после правки или смены исследования удалил бы не те строки.
+func (r *research) editable() error {
+	if r.pending() {
+		return errors.New("есть незаписанные изменения: дождитесь завершения импорта")
+	}
+	return nil
+}
+
+// dropRows возвращает новые срезы строк и их пометок без строк с номерами idx; исходные не меняются.
+func dropRows[T any](data []T, origins []int, idx []int) ([]T, []int, error) {
+	drop := make([]bool, len(data))
+	for _, i := range idx {
+		if i < 0 || i >= len(data) {
+			return nil, nil, errors.Newf("строки %d нет в блоке", i+1)
+		}
+		drop[i] = true
+	}
+	return withoutRows(data, drop), withoutRows(origins, drop), nil
+}
+
+// withoutRows возвращает новый срез без строк, отмеченных в drop.
+func withoutRows[T any](data []T, drop []bool) []T {
+	out := make([]T, 0, len(data))
+	for i, v := range data {
+		if !drop[i] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -2,8 +2,12 @@
 package inmemory
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/cockroachdb/errors"
+
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
 )
 
@@ -18,6 +22,9 @@ type InMemoryBlocksStorage interface {
 	PutTableThreeData(data []models.TableThree) error
 	PutTableFourData(data []models.TableFour) error
 	PutTableFiveData(data models.TableFive) error
+	PutOperationConfig(cfg models.OperationConfig) error
+	PutFilterConfig(cfg models.FilterConfig) error
+	PutDisplayUnits(units models.UnitSystem) error
 
 	// Методы для получения всех данных (возвращают копии для безопасности)
 	GetTableOneData() ([]models.TableOne, error)
@@ -25,29 +32,132 @@ type InMemoryBlocksStorage interface {
 	GetTableThreeData() ([]models.TableThree, error)
 	GetTableFourData() ([]models.TableFour, error)
 	GetTableFiveData() (models.TableFive, error)
+	// GetOperationConfig возвращает параметры гидростатики блока 1; ok=false, если они ещё не заданы
+	GetOperationConfig() (cfg models.OperationConfig, ok bool, err error)
+	// GetFilterConfig возвращает параметры очистки блоков 1 и 2; ok=false, если они ещё не заданы
+	GetFilterConfig() (cfg models.FilterConfig, ok bool, err error)
+	// GetDisplayUnits возвращает единицы отображения; пока они не выбраны — единицы хранения
+	GetDisplayUnits() (models.UnitSystem, error)
+	// GetImportSources возвращает импортированные файлы в порядке импорта
+	GetImportSources() ([]models.ImportSource, error)
+	// GetRowSources возвращает ID импорта каждой строки блоков 1–4; 0 — файл неизвестен
+	GetRowSources() ([4][]int, error)
+
+	// История изменений активного исследования. Изменения данных и настроек копятся, пока
+	// не будут записаны одной операцией: CommitImport, RemoveImportSource и OpenResearch записывают себя сами,
+	// остальное — RecordChange. Отмена, повтор и смена настроек или шапки отчёта невозможны, пока есть
+	// незаписанные изменения (идёт импорт): они попали бы в одну операцию с его строками.
+	// CommitImport привязывает строки, добавленные после последней записи, к файлу src и записывает импорт
+	CommitImport(src models.ImportSource) (models.Change, error)
+	// RemoveImportSource удаляет строки импорта id из всех блоков и сам файл из списка
+	RemoveImportSource(id int) (models.Change, error)
+	// ReplaceImport записывает повторный импорт: новые строки заменяют строки импорта id
+	ReplaceImport(id int, src models.ImportSource) (models.Change, error)
+	// RecordChange не записывает настройки и шапку отчёта, пока есть незаписанные строки импорта
+	RecordChange(kind models.ChangeKind, title string) (models.Change, error)
+	// OpenResearch заменяет данные и настройки активного исследования проектом или исследованием из БД
+	// и записывает это одной операцией
+	OpenResearch(p models.Project, title string) (models.Change, error)
+	Undo() (models.Change, error)
+	Redo() (models.Change, error)
+	// History возвращает выполненные операции по порядку, за ними — отменённые, которые можно повторить
+	History() ([]models.Change, error)
+
+	// Правка строк активного исследования: строка i заменяется или строки idx удаляются в новом
+	// срезе, снимки истории не меняются. В историю правка записывается через RecordChange
+	UpdateTableOneRow(i int, row models.TableOne) error
+	UpdateTableTwoRow(i int, row models.TableTwo) error
+	UpdateTableThreeRow(i int, row models.TableThree) error
+	UpdateTableFourRow(i int, row models.TableFour) error
+	DeleteTableOneRows(idx []int) error
+	DeleteTableTwoRows(idx []int) error
+	DeleteTableThreeRows(idx []int) error
+	DeleteTableFourRows(idx []int) error
 
-	// Метод для очистки всего хранилища
+	// Метод для очистки активного исследования
 	ClearAll() error
 
+	// Методы для отката незавершённого импорта: оставляют в блоке первые n записей
+	TruncateTableOneData(n int) error
+	TruncateTableTwoData(n int) error
+	TruncateTableThreeData(n int) error
+	TruncateTableFourData(n int) error
+
 	// Можно добавить методы для получения количества записей (опционально)
 	CountBlockOne() int
 	CountBlockTwo() int
 	CountBlockThree() int
+	CountBlockFour() int
+
+	// Рабочее пространство: несколько исследований (скважин) в памяти, методы выше работают с активным.
+	// Пока в активном есть незаписанные изменения (идёт импорт), его нельзя сменить, удалить или очистить:
+	// порции и откат импорта попали бы в другое исследование.
+	// CreateResearch создаёт пустое исследование и делает его активным; пустое name — имя по номеру
+	CreateResearch(name string) (int, error)
+	SetActiveResearch(id int) error
+	ActiveResearch() models.ResearchSummary
+	ListResearches() ([]models.ResearchSummary, error)
+	RenameResearch(id int, name string) error
+	// RemoveResearch удаляет исследование; единственное исследование удалить нельзя
+	RemoveResearch(id int) error
+	// GetResearch возвращает копию данных и настроек исследования, не меняя активное
+	GetResearch(id int) (models.Project, error)
+
+	// Subscribe подписывает fn на изменения частей blocks исследования research (0 — всех)
+	// и возвращает функцию отписки
+	Subscribe(research int, blocks models.DataBlocks, fn func(models.DataChange)) func()
 }
 
 // Storage реализует интерфейс storage.InMemoryBlocksStorage, храня данные в памяти.
+// Данные разложены по исследованиям; методы блоков работают с активным исследованием.
 type Storage struct {
 	mu         sync.RWMutex // Mutex для безопасного доступа к данным
+	researches []*research  // в порядке создания
+	cur        *research    // активное исследование, всегда одно из researches
+	nextID     int
+	units      models.UnitSystem   // единицы отображения; настройка пользователя, общая для всех исследований
+	changes    []models.DataChange // изменения под текущей блокировкой записи, уходят подписчикам в unlock
+
+	subMu   sync.Mutex // подписчики меняются отдельно: подписаться можно и из уведомления
+	subs    []subscription
+	nextSub int
+}
+
+// research — данные, настройки и история изменений одного исследования (скважины).
+type research struct {
+	id   int
+	name string
+	state
+	base       state     // состояние на момент последней записи в историю
+	edits      []rowEdit // правки строк после последней записи в историю
+	done       []change  // выполненные операции, последняя — в конце
+	undone     []change  // отменённые операции, которые можно повторить; последняя отменённая — в конце
+	nextChange int
+	nextSource int
+	revision   int // растёт при каждом изменении, о котором уходит уведомление
+}
+
+// state — данные и настройки исследования. Элементы срезов не меняются на месте: снимки
+// истории ссылаются на те же массивы, поэтому изменение строк — только через новый срез.
+type state struct {
 	blockOne   []models.TableOne
 	blockTwo   []models.TableTwo
 	blockThree []models.TableThree
 	blockFour  []models.TableFour
 	blockFive  models.TableFive
+	opConfig   *models.OperationConfig // параметры гидростатики для расчёта Рзаб на ВДП
+	filter     *models.FilterConfig    // параметры очистки блоков 1 и 2
+	sources    []models.ImportSource   // импортированные файлы
+	// origins — ID импорта каждой строки блоков 1–4; 0 — файл неизвестен (данные открыты из проекта или БД)
+	origins [4][]int
 }
 
-// NewInMemoryBlocksStorage создает новый экземпляр Storage.
-func NewInMemoryBlocksStorage() InMemoryBlocksStorage { // Возвращаем интерфейс!
-	return &Storage{
+func newResearch(id int, name string) *research {
+	return &research{id: id, name: name, state: emptyState(), nextChange: 1, nextSource: 1}
+}
+
+func emptyState() state {
+	return state{
 		blockOne:   make([]models.TableOne, 0),
 		blockTwo:   make([]models.TableTwo, 0),
 		blockThree: make([]models.TableThree, 0),
@@ -55,41 +165,263 @@ func NewInMemoryBlocksStorage() InMemoryBlocksStorage { // Возвращаем
 	}
 }
 
+// summary описывает исследование для списка.
+func (r *research) summary() models.ResearchSummary {
+	return models.ResearchSummary{
+		ID:       r.id,
+		Name:     r.name,
+		Report:   r.blockFive,
+		Rows:     [4]int{len(r.blockOne), len(r.blockTwo), len(r.blockThree), len(r.blockFour)},
+		Pending:  r.pending(),
+		Revision: r.revision,
+	}
+}
+
+// NewInMemoryBlocksStorage создает новый экземпляр Storage с одним пустым активным исследованием.
+func NewInMemoryBlocksStorage() InMemoryBlocksStorage { // Возвращаем интерфейс!
+	s := &Storage{nextID: 1}
+	s.cur = s.add("")
+	return s
+}
+
+// add создаёт исследование; вызывается под блокировкой записи.
+func (s *Storage) add(name string) *research {
+	if name == "" {
+		name = fmt.Sprintf("Исследование %d", s.nextID)
+	}
+	r := newResearch(s.nextID, name)
+	s.nextID++
+	s.researches = append(s.researches, r)
+	return r
+}
+
+// find возвращает исследование id; вызывается под блокировкой.
+func (s *Storage) find(id int) (int, *research, error) {
+	for i, r := range s.researches {
+		if r.id == id {
+			return i, r, nil
+		}
+	}
+	return -1, nil, errors.Newf("исследование %d не найдено", id)
+}
+
+// CreateResearch создаёт пустое исследование и делает его активным; пустое name — имя по номеру.
+func (s *Storage) CreateResearch(name string) (int, error) {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return 0, err
+	}
+	s.cur = s.add(strings.TrimSpace(name))
+	s.changed(s.cur.id, models.DataWorkspace)
+	return s.cur.id, nil
+}
+
+// SetActiveResearch делает исследование id активным.
+func (s *Storage) SetActiveResearch(id int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	_, r, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if r != s.cur {
+		if err := s.cur.editable(); err != nil {
+			return err
+		}
+		s.cur = r
+		s.changed(r.id, models.DataWorkspace)
+	}
+	return nil
+}
+
+// ActiveResearch возвращает активное исследование.
+func (s *Storage) ActiveResearch() models.ResearchSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sum := s.cur.summary()
+	sum.Active = true
+	return sum
+}
+
+// ListResearches возвращает исследования в порядке создания с числом строк в блоках 1–4.
+func (s *Storage) ListResearches() ([]models.ResearchSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]models.ResearchSummary, len(s.researches))
+	for i, r := range s.researches {
+		out[i] = r.summary()
+		out[i].Active = r == s.cur
+	}
+	return out, nil
+}
+
+// RenameResearch меняет название исследования id.
+func (s *Storage) RenameResearch(id int, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("название исследования не может быть пустым")
+	}
+	s.mu.Lock()
+	defer s.unlock()
+	_, r, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	r.name = name
+	s.changed(id, models.DataWorkspace)
+	return nil
+}
+
+// RemoveResearch удаляет исследование id из памяти. Последнее исследование не удаляется —
+// его можно только очистить. Если удалено активное, активным становится соседнее.
+func (s *Storage) RemoveResearch(id int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	i, r, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if len(s.researches) == 1 {
+		return errors.New("нельзя удалить единственное исследование")
+	}
+	if r == s.cur {
+		if err := r.editable(); err != nil {
+			return err
+		}
+	}
+	s.researches = append(s.researches[:i], s.researches[i+1:]...)
+	if r == s.cur {
+		s.cur = s.researches[min(i, len(s.researches)-1)]
+	}
+	s.changed(id, models.DataWorkspace)
+	return nil
+}
+
+// GetResearch возвращает копию данных и настроек исследования id, не меняя активное:
+// по ней графики и экспорт работают с несколькими исследованиями сразу.
+func (s *Storage) GetResearch(id int) (models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, r, err := s.find(id)
+	if err != nil {
+		return models.Project{}, err
+	}
+	p := models.Project{
+		One:          append([]models.TableOne(nil), r.blockOne...),
+		Two:          append([]models.TableTwo(nil), r.blockTwo...),
+		Three:        append([]models.TableThree(nil), r.blockThree...),
+		Four:         append([]models.TableFour(nil), r.blockFour...),
+		Five:         r.blockFive,
+		DisplayUnits: s.units,
+		Sources:      append([]models.ImportSource(nil), r.sources...),
+	}
+	if r.opConfig != nil {
+		cfg := *r.opConfig
+		p.OperationConfig = &cfg
+	}
+	if r.filter != nil {
+		cfg := *r.filter
+		p.FilterConfig = &cfg
+	}
+	return p, nil
+}
+
 // PutTableOneData добавляет данные TableOne в хранилище.
 func (s *Storage) PutTableOneData(data []models.TableOne) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.blockOne = append(s.blockOne, data...)
+	defer s.unlock()
+	s.cur.blockOne = append(s.cur.blockOne, data...)
+	s.cur.origins[0] = append(s.cur.origins[0], make([]int, len(data))...)
+	if len(data) > 0 {
+		s.changed(s.cur.id, models.DataBlockOne)
+	}
 	return nil // В in-memory обычно нет ошибок добавления, кроме нехватки памяти (panic)
 }
 
 // PutTableTwoData добавляет данные TableTwo в хранилище.
 func (s *Storage) PutTableTwoData(data []models.TableTwo) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.blockTwo = append(s.blockTwo, data...)
+	defer s.unlock()
+	s.cur.blockTwo = append(s.cur.blockTwo, data...)
+	s.cur.origins[1] = append(s.cur.origins[1], make([]int, len(data))...)
+	if len(data) > 0 {
+		s.changed(s.cur.id, models.DataBlockTwo)
+	}
 	return nil
 }
 
 // PutTableThreeData добавляет данные TableThree в хранилище.
 func (s *Storage) PutTableThreeData(data []models.TableThree) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.blockThree = append(s.blockThree, data...)
+	defer s.unlock()
+	s.cur.blockThree = append(s.cur.blockThree, data...)
+	s.cur.origins[2] = append(s.cur.origins[2], make([]int, len(data))...)
+	if len(data) > 0 {
+		s.changed(s.cur.id, models.DataBlockThree)
+	}
 	return nil
 }
 
 func (s *Storage) PutTableFourData(data []models.TableFour) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.blockFour = append(s.blockFour, data...)
+	defer s.unlock()
+	s.cur.blockFour = append(s.cur.blockFour, data...)
+	s.cur.origins[3] = append(s.cur.origins[3], make([]int, len(data))...)
+	if len(data) > 0 {
+		s.changed(s.cur.id, models.DataBlockFour)
+	}
 	return nil // В in-memory обычно нет ошибок добавления, кроме нехватки памяти (panic)
 }
 
 func (s *Storage) PutTableFiveData(data models.TableFive) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.blockFive = data
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	if s.cur.blockFive != data {
+		s.cur.blockFive = data
+		s.changed(s.cur.id, models.DataBlockFive)
+	}
+	return nil
+}
+
+// PutOperationConfig сохраняет параметры гидростатики блока 1, заменяя прежние.
+func (s *Storage) PutOperationConfig(cfg models.OperationConfig) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	s.cur.opConfig = &cfg
+	s.changed(s.cur.id, models.DataSettings)
+	return nil
+}
+
+// PutFilterConfig сохраняет параметры очистки блоков 1 и 2, заменяя прежние.
+func (s *Storage) PutFilterConfig(cfg models.FilterConfig) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	s.cur.filter = &cfg
+	s.changed(s.cur.id, models.DataSettings)
+	return nil
+}
+
+// PutDisplayUnits сохраняет единицы, в которых графики, формы и экспорт показывают величины.
+func (s *Storage) PutDisplayUnits(units models.UnitSystem) error {
+	if err := units.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.unlock()
+	if s.units != units {
+		s.units = units
+		s.changed(0, models.DataSettings)
+	}
 	return nil
 }
 
@@ -98,8 +430,8 @@ func (s *Storage) GetTableOneData() ([]models.TableOne, error) {
 	s.mu.RLock() // Блокировка на чтение
 	defer s.mu.RUnlock()
 	// Возвращаем копию, чтобы внешние изменения не влияли на хранилище
-	dataCopy := make([]models.TableOne, len(s.blockOne))
-	copy(dataCopy, s.blockOne)
+	dataCopy := make([]models.TableOne, len(s.cur.blockOne))
+	copy(dataCopy, s.cur.blockOne)
 	return dataCopy, nil
 }
 
@@ -107,8 +439,8 @@ func (s *Storage) GetTableOneData() ([]models.TableOne, error) {
 func (s *Storage) GetTableTwoData() ([]models.TableTwo, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	dataCopy := make([]models.TableTwo, len(s.blockTwo))
-	copy(dataCopy, s.blockTwo)
+	dataCopy := make([]models.TableTwo, len(s.cur.blockTwo))
+	copy(dataCopy, s.cur.blockTwo)
 	return dataCopy, nil
 }
 
@@ -116,8 +448,8 @@ func (s *Storage) GetTableTwoData() ([]models.TableTwo, error) {
 func (s *Storage) GetTableThreeData() ([]models.TableThree, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	dataCopy := make([]models.TableThree, len(s.blockThree))
-	copy(dataCopy, s.blockThree)
+	dataCopy := make([]models.TableThree, len(s.cur.blockThree))
+	copy(dataCopy, s.cur.blockThree)
 	return dataCopy, nil
 }
 
@@ -125,45 +457,166 @@ func (s *Storage) GetTableThreeData() ([]models.TableThree, error) {
 func (s *Storage) GetTableFourData() ([]models.TableFour, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	dataCopy := make([]models.TableFour, len(s.blockFour))
-	copy(dataCopy, s.blockFour)
+	dataCopy := make([]models.TableFour, len(s.cur.blockFour))
+	copy(dataCopy, s.cur.blockFour)
 	return dataCopy, nil
 }
 
 func (s *Storage) GetTableFiveData() (models.TableFive, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	dataCopy := s.blockFive
+	dataCopy := s.cur.blockFive
+	return dataCopy, nil
+}
+
+func (s *Storage) GetOperationConfig() (models.OperationConfig, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cur.opConfig == nil {
+		return models.OperationConfig{}, false, nil
+	}
+	return *s.cur.opConfig, true, nil
+}
+
+func (s *Storage) GetFilterConfig() (models.FilterConfig, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cur.filter == nil {
+		return models.FilterConfig{}, false, nil
+	}
+	return *s.cur.filter, true, nil
+}
+
+func (s *Storage) GetDisplayUnits() (models.UnitSystem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.units, nil
+}
+
+func (s *Storage) GetImportSources() ([]models.ImportSource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dataCopy := make([]models.ImportSource, len(s.cur.sources))
+	copy(dataCopy, s.cur.sources)
 	return dataCopy, nil
 }
 
-// ClearAll очищает все данные в хранилище.
+func (s *Storage) GetRowSources() ([4][]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out [4][]int
+	for b, origins := range s.cur.origins {
+		out[b] = append([]int(nil), origins...)
+	}
+	return out, nil
+}
+
+// ClearAll очищает данные и настройки активного исследования, включая блоки 4 и 5;
+// название и остальные исследования остаются. Очистку можно отменить через историю.
+// Во время импорта очистка невозможна: его оставшиеся порции и откат пришлись бы на очищенные блоки.
 func (s *Storage) ClearAll() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.blockOne = make([]models.TableOne, 0)
-	s.blockTwo = make([]models.TableTwo, 0)
-	s.blockThree = make([]models.TableThree, 0)
+	defer s.unlock()
+	if err := s.cur.editable(); err != nil {
+		return err
+	}
+	before := s.cur.state
+	s.cur.state = emptyState()
+	s.changed(s.cur.id, before.diff(s.cur.state))
+	return nil
+}
+
+// TruncateTableOneData оставляет в блоке 1 первые n записей.
+func (s *Storage) TruncateTableOneData(n int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if n >= 0 && n < len(s.cur.blockOne) {
+		s.changed(s.cur.id, models.DataBlockOne)
+	}
+	s.cur.blockOne = truncateTo(s.cur.blockOne, s.cur.base.blockOne, n)
+	s.cur.origins[0] = truncateTo(s.cur.origins[0], s.cur.base.origins[0], n)
+	return nil
+}
+
+// TruncateTableTwoData оставляет в блоке 2 первые n записей.
+func (s *Storage) TruncateTableTwoData(n int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if n >= 0 && n < len(s.cur.blockTwo) {
+		s.changed(s.cur.id, models.DataBlockTwo)
+	}
+	s.cur.blockTwo = truncateTo(s.cur.blockTwo, s.cur.base.blockTwo, n)
+	s.cur.origins[1] = truncateTo(s.cur.origins[1], s.cur.base.origins[1], n)
+	return nil
+}
+
+// TruncateTableThreeData оставляет в блоке 3 первые n записей.
+func (s *Storage) TruncateTableThreeData(n int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if n >= 0 && n < len(s.cur.blockThree) {
+		s.changed(s.cur.id, models.DataBlockThree)
+	}
+	s.cur.blockThree = truncateTo(s.cur.blockThree, s.cur.base.blockThree, n)
+	s.cur.origins[2] = truncateTo(s.cur.origins[2], s.cur.base.origins[2], n)
 	return nil
 }
 
+// TruncateTableFourData оставляет в блоке 4 первые n записей.
+func (s *Storage) TruncateTableFourData(n int) error {
+	s.mu.Lock()
+	defer s.unlock()
+	if n >= 0 && n < len(s.cur.blockFour) {
+		s.changed(s.cur.id, models.DataBlockFour)
+	}
+	s.cur.blockFour = truncateTo(s.cur.blockFour, s.cur.base.blockFour, n)
+	s.cur.origins[3] = truncateTo(s.cur.origins[3], s.cur.base.origins[3], n)
+	return nil
+}
+
+// truncateTo обрезает блок до n строк. Если столько строк было при последней записи в историю,
+// возвращается записанный срез: импорт только дописывает строки, и после его отката исследование
+// снова не отличается от записанного, даже если append успел перенести данные в новый массив.
+func truncateTo[T any](data, base []T, n int) []T {
+	if n == len(base) && n <= len(data) {
+		return base
+	}
+	return truncate(data, n)
+}
+
+// truncate обрезает срез до n элементов. Ёмкость тоже ограничивается: следующий append
+// перенесёт данные в новый массив, и память отменённого импорта освободится.
+func truncate[T any](data []T, n int) []T {
+	if n < 0 || n >= len(data) {
+		return data
+	}
+	return data[:n:n]
+}
+
 // CountBlockOne возвращает количество записей TableOne.
 func (s *Storage) CountBlockOne() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.blockOne)
+	return len(s.cur.blockOne)
 }
 
 // CountBlockTwo возвращает количество записей TableTwo.
 func (s *Storage) CountBlockTwo() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.blockTwo)
+	return len(s.cur.blockTwo)
 }
 
 // CountBlockThree возвращает количество записей TableThree.
 func (s *Storage) CountBlockThree() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.blockThree)
+	return len(s.cur.blockThree)
+}
+
+// CountBlockFour возвращает количество записей TableFour.
+func (s *Storage) CountBlockFour() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.cur.blockFour)
 }
@@ -2,23 +2,23 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/cockroachdb/errors"
 
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/config"
 	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/logger"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/storage/sqlstore"
 )
 
-// Postgres инкапсулирует подключение к PostgreSQL через sqlx.DB
+// Postgres инкапсулирует подключение к PostgreSQL через sqlx.DB; запросы общие с SQLite, см. sqlstore.Store
 type Postgres struct {
-	DB   *sqlx.DB
+	*sqlstore.Store
 	zLog logger.Logger
 }
 
@@ -59,14 +59,24 @@ func New(ctx context.Context, cfg config.DBConf, zLog logger.Logger) (*Postgres,
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
 	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
 
-	return &Postgres{DB: db, zLog: zLog}, nil
+	return &Postgres{Store: sqlstore.New(db, sq.Dollar, copyRows), zLog: zLog}, nil
 }
 
-func (p *Postgres) GetSqlDB() *sql.DB {
-	return p.DB.DB
-}
+// copyRows пишет n строк в table через COPY.
+func copyRows(ctx context.Context, tx *sqlx.Tx, table string, columns []string, n int, row func(i int) []any) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return errors.Wrapf(err, "preparing COPY into %s", table)
+	}
+	defer stmt.Close()
 
-// Postgres specific squirrel builder
-func psql() sq.StatementBuilderType {
-	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	for i := 0; i < n; i++ {
+		if _, err = stmt.ExecContext(ctx, row(i)...); err != nil {
+			return errors.Wrapf(err, "copying row %d into %s", i, table)
+		}
+	}
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return errors.Wrapf(err, "flushing COPY into %s", table)
+	}
+	return nil
 }
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/config"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/logger"
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/storage/sqlstore"
+)
+
+// SQLite инкапсулирует локальную базу в файле через sqlx.DB. Используется в однопользовательском
+// режиме, когда сервера PostgreSQL нет, например на ноутбуке на промысле. Запросы общие с PostgreSQL, см. sqlstore.Store.
+type SQLite struct {
+	*sqlstore.Store
+	zLog logger.Logger
+}
+
+// New открывает (или создаёт) файл базы cfg.SQLitePath и возвращает обертку SQLite
+func New(ctx context.Context, cfg config.DBConf, zLog logger.Logger) (*SQLite, error) {
+	if dir := filepath.Dir(cfg.SQLitePath); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, errors.Wrap(err, "os.MkdirAll")
+		}
+	}
+
+	// внешние ключи нужны для каскадного удаления блоков вместе с отчётом, они выключены по умолчанию
+	dsn := "file:" + cfg.SQLitePath + "?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"
+	db, err := sqlx.ConnectContext(ctx, "sqlite3", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlx.ConnectContext")
+	}
+
+	// одно соединение: база однопользовательская, а SQLite всё равно сериализует запись
+	db.SetMaxOpenConns(1)
+
+	return &SQLite{Store: sqlstore.New(db, sq.Question, insertRows), zLog: zLog}, nil
+}
+
+// insertRows пишет n строк в table одним подготовленным INSERT: COPY в SQLite нет,
+// а в одной транзакции это и так быстро для локального файла.
+func insertRows(ctx context.Context, tx *sqlx.Tx, table string, columns []string, n int, row func(i int) []any) error {
+	sqlStr, _, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Insert(table).
+		Columns(columns...).
+		Values(make([]any, len(columns))...).
+		ToSql()
+	if err != nil {
+		return errors.Wrapf(err, "building insert query for %s", table)
+	}
+	stmt, err := tx.PrepareContext(ctx, sqlStr)
+	if err != nil {
+		return errors.Wrapf(err, "preparing insert into %s", table)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < n; i++ {
+		if _, err = stmt.ExecContext(ctx, row(i)...); err != nil {
+			return errors.Wrapf(err, "inserting row %d into %s", i, table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package sqlstore
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// GetAllTableFive возвращает все записи из таблицы reports
+func (s *Store) GetAllTableFive(ctx context.Context) ([]models.TableFive, error) {
+	var reports []models.TableFive
+	qb := s.builder.
+		Select(models.TableFive{}.Columns()...).
+		From(models.TableFive{}.TableName())
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building GetAllTableFive query")
+	}
+	if err = s.DB.SelectContext(ctx, &reports, sqlStr, args...); err != nil {
+		return nil, errors.Wrap(err, "executing GetAllTableFive query")
+	}
+	return reports, nil
+}
+
+// AddBlockFive вставляет запись в таблицу reports и возвращает сгенерированный ID
+func (s *Store) AddBlockFive(ctx context.Context, data models.TableFive) (int64, error) {
+	qb := s.builder.
+		Insert(models.TableFive{}.TableName()).
+		Columns(models.TableFive{}.Columns()...).
+		SetMap(data.Map()).
+		Suffix("RETURNING id")
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "building AddBlockFive query")
+	}
+	var id int64
+	if err = s.DB.QueryRowContext(ctx, sqlStr, args...).Scan(&id); err != nil {
+		return 0, errors.Wrap(err, "executing AddBlockFive query")
+	}
+	return id, nil
+}
+
+// UpdateBlockFiveAnalysis обновляет свойства пласта и результаты интерпретации КВД в записи reports
+func (s *Store) UpdateBlockFiveAnalysis(ctx context.Context, data models.TableFive) error {
+	qb := s.builder.
+		Update(models.TableFive{}.TableName()).
+		SetMap(map[string]any{
+			"thickness":              data.Thickness,
+			"porosity":               data.Porosity,
+			"viscosity":              data.Viscosity,
+			"volume_factor":          data.VolumeFactor,
+			"total_compressibility":  data.TotalCompressibility,
+			"well_radius":            data.WellRadius,
+			"analysis_method":        data.AnalysisMethod,
+			"extrapolated_pressure":  data.ExtrapolatedPressure,
+			"permeability_thickness": data.PermeabilityThickness,
+			"permeability":           data.Permeability,
+			"skin":                   data.Skin,
+		}).
+		Where(sq.Eq{"id": data.ID})
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building UpdateBlockFiveAnalysis query")
+	}
+	res, err := s.DB.ExecContext(ctx, sqlStr, args...)
+	if err != nil {
+		return errors.Wrap(err, "executing UpdateBlockFiveAnalysis query")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Newf("report %d not found", data.ID)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// GetAllOilField возвращает все записи из таблицы oilfield
+func (s *Store) GetAllOilField(ctx context.Context) ([]models.OilField, error) {
+	var items []models.OilField
+	qb := s.builder.
+		Select(models.OilField{}.Columns()...).
+		From(models.OilField{}.TableName())
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building GetAllOilField query")
+	}
+	if err = s.DB.SelectContext(ctx, &items, sqlStr, args...); err != nil {
+		return nil, errors.Wrap(err, "executing GetAllOilField query")
+	}
+	return items, nil
+}
+
+// GetAllInstrumentType возвращает все записи из таблицы instrument_type
+func (s *Store) GetAllInstrumentType(ctx context.Context) ([]models.InstrumentType, error) {
+	var items []models.InstrumentType
+	qb := s.builder.
+		Select(models.InstrumentType{}.Columns()...).
+		From(models.InstrumentType{}.TableName())
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building GetAllInstrumentType query")
+	}
+	if err = s.DB.SelectContext(ctx, &items, sqlStr, args...); err != nil {
+		return nil, errors.Wrap(err, "executing GetAllInstrumentType query")
+	}
+	return items, nil
+}
+
+// GetAllProductiveHorizon возвращает все записи из таблицы productive_horizon
+func (s *Store) GetAllProductiveHorizon(ctx context.Context) ([]models.ProductiveHorizon, error) {
+	var items []models.ProductiveHorizon
+	qb := s.builder.
+		Select(models.ProductiveHorizon{}.Columns()...).
+		From(models.ProductiveHorizon{}.TableName())
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building GetAllProductiveHorizon query")
+	}
+	if err = s.DB.SelectContext(ctx, &items, sqlStr, args...); err != nil {
+		return nil, errors.Wrap(err, "executing GetAllProductiveHorizon query")
+	}
+	return items, nil
+}
+
+// GetAllResearchType возвращает все записи из таблицы research_type
+func (s *Store) GetAllResearchType(ctx context.Context) ([]models.ResearchType, error) {
+	var items []models.ResearchType
+	qb := s.builder.
+		Select(models.ResearchType{}.Columns()...).
+		From(models.ResearchType{}.TableName())
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building GetAllResearchType query")
+	}
+	if err = s.DB.SelectContext(ctx, &items, sqlStr, args...); err != nil {
+		return nil, errors.Wrap(err, "executing GetAllResearchType query")
+	}
+	return items, nil
+}
+
+// AddOilField вставляет записи в таблицу oilfield
+func (s *Store) AddOilField(ctx context.Context, fields []models.OilField) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "starting AddOilField transaction")
+	}
+	defer tx.Rollback()
+
+	qb := s.builder.
+		Insert(models.OilField{}.TableName()).
+		Columns(models.OilField{}.Columns()...)
+
+	for _, f := range fields {
+		qb = qb.Values(
+			f.Name,
+		)
+	}
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building AddOilField batch query")
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return errors.Wrap(err, "executing AddOilField batch query")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "committing AddOilField transaction")
+	}
+	return nil
+}
+
+// AddInstrumentType вставляет записи в таблицу instrument_type
+func (s *Store) AddInstrumentType(ctx context.Context, items []models.InstrumentType) error {
+	for _, it := range items {
+		qb := s.builder.
+			Insert(models.InstrumentType{}.TableName()).
+			SetMap(it.Map())
+
+		sqlStr, args, err := qb.ToSql()
+		if err != nil {
+			return errors.Wrap(err, "building AddInstrumentType query")
+		}
+		if _, err = s.DB.ExecContext(ctx, sqlStr, args...); err != nil {
+			return errors.Wrap(err, "executing AddInstrumentType query")
+		}
+	}
+	return nil
+}
+
+// AddProductiveHorizon вставляет записи в таблицу productive_horizon
+func (s *Store) AddProductiveHorizon(ctx context.Context, items []models.ProductiveHorizon) error {
+	for _, ph := range items {
+		qb := s.builder.
+			Insert(models.ProductiveHorizon{}.TableName()).
+			SetMap(ph.Map())
+
+		sqlStr, args, err := qb.ToSql()
+		if err != nil {
+			return errors.Wrap(err, "building AddProductiveHorizon query")
+		}
+		if _, err = s.DB.ExecContext(ctx, sqlStr, args...); err != nil {
+			return errors.Wrap(err, "executing AddProductiveHorizon query")
+		}
+	}
+	return nil
+}
+
+// AddResearchType вставляет записи в таблицу research_type
+func (s *Store) AddResearchType(ctx context.Context, items []models.ResearchType) error {
+	for _, it := range items {
+		qb := s.builder.
+			Insert(models.ResearchType{}.TableName()).
+			SetMap(it.Map())
+		sqlStr, args, err := qb.ToSql()
+		if err != nil {
+			return errors.Wrap(err, "building AddResearchType query")
+		}
+		if _, err = s.DB.ExecContext(ctx, sqlStr, args...); err != nil {
+			return errors.Wrap(err, "executing AddResearchType query")
+		}
+	}
+	return nil
+}
+
+// ListGuidebook возвращает значения справочника g по алфавиту с числом отчётов, в которых они указаны
+func (s *Store) ListGuidebook(ctx context.Context, g models.Guidebook) ([]models.GuidebookEntry, error) {
+	var items []models.GuidebookEntry
+	used := s.builder.
+		Select("COUNT(*)").
+		From("reports r").
+		Where("r." + g.ReportColumn() + " = g.name")
+	qb := s.builder.
+		Select("g.name").
+		Column(sq.Alias(used, "reports")).
+		From(g.TableName() + " g").
+		OrderBy("g.name")
+
+	sqlStr, args, err := qb.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building ListGuidebook query")
+	}
+	if err = s.DB.SelectContext(ctx, &items, sqlStr, args...); err != nil {
+		return nil, errors.Wrapf(err, "executing ListGuidebook query for %s", g)
+	}
+	return items, nil
+}
+
+// RenameGuidebookEntry переименовывает значение from справочника g в to вместе с отчётами, где оно указано
+func (s *Store) RenameGuidebookEntry(ctx context.Context, g models.Guidebook, from, to string) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "starting RenameGuidebookEntry transaction")
+	}
+	defer tx.Rollback()
+
+	sqlStr, args, err := s.builder.
+		Update(g.TableName()).
+		Set("name", to).
+		Where(sq.Eq{"name": from}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building RenameGuidebookEntry query")
+	}
+	res, err := tx.ExecContext(ctx, sqlStr, args...)
+	if err != nil {
+		return errors.Wrapf(err, "renaming %s entry %q", g, from)
+	}
+	if err = expectRows(res, errors.Newf("%s entry %q not found", g, from)); err != nil {
+		return err
+	}
+	if err = s.moveReports(ctx, tx, g, from, to); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "committing RenameGuidebookEntry transaction")
+	}
+	return nil
+}
+
+// MergeGuidebookEntries переносит отчёты со значения from справочника g на into и удаляет from
+func (s *Store) MergeGuidebookEntries(ctx context.Context, g models.Guidebook, from, into string) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "starting MergeGuidebookEntries transaction")
+	}
+	defer tx.Rollback()
+
+	sqlStr, args, err := s.builder.
+		Select("COUNT(*)").
+		From(g.TableName()).
+		Where(sq.Eq{"name": into}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building MergeGuidebookEntries check query")
+	}
+	var found int
+	if err = tx.GetContext(ctx, &found, sqlStr, args...); err != nil {
+		return errors.Wrapf(err, "checking %s entry %q", g, into)
+	}
+	if found == 0 {
+		return errors.Newf("%s entry %q not found", g, into)
+	}
+	if err = s.moveReports(ctx, tx, g, from, into); err != nil {
+		return err
+	}
+
+	sqlStr, args, err = s.builder.
+		Delete(g.TableName()).
+		Where(sq.Eq{"name": from}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building MergeGuidebookEntries delete query")
+	}
+	res, err := tx.ExecContext(ctx, sqlStr, args...)
+	if err != nil {
+		return errors.Wrapf(err, "deleting %s entry %q", g, from)
+	}
+	if err = expectRows(res, errors.Newf("%s entry %q not found", g, from)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "committing MergeGuidebookEntries transaction")
+	}
+	return nil
+}
+
+// DeleteGuidebookEntry удаляет значение name справочника g, если оно не указано ни в одном отчёте
+func (s *Store) DeleteGuidebookEntry(ctx context.Context, g models.Guidebook, name string) error {
+	sqlStr, args, err := s.builder.
+		Delete(g.TableName()).
+		Where(sq.Eq{"name": name}).
+		Where("NOT EXISTS (SELECT 1 FROM reports WHERE "+g.ReportColumn()+" = ?)", name).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building DeleteGuidebookEntry query")
+	}
+	res, err := s.DB.ExecContext(ctx, sqlStr, args...)
+	if err != nil {
+		return errors.Wrapf(err, "deleting %s entry %q", g, name)
+	}
+	if err = expectRows(res, errors.Newf("%s entry %q not found or used in reports", g, name)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// moveReports заменяет в отчётах значение from справочника g на to
+func (s *Store) moveReports(ctx context.Context, tx *sqlx.Tx, g models.Guidebook, from, to string) error {
+	sqlStr, args, err := s.builder.
+		Update("reports").
+		Set(g.ReportColumn(), to).
+		Where(sq.Eq{g.ReportColumn(): from}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building reports update query")
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return errors.Wrapf(err, "moving reports from %s entry %q", g, from)
+	}
+	return nil
+}
+
+// expectRows возвращает notFound, если запрос не затронул ни одной строки
+func expectRows(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "reading affected rows")
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cockroachdb/errors"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/lifedaemon-kill/burovichok-desktop/internal/pkg/models"
+)
+
+// Колонки, которыми строки блоков привязаны к отчёту; остальные берутся из Columns() модели.
+const (
+	reportIDColumn = "report_id"
+	seqColumn      = "seq"
+)
+
+// SaveResearchBlocks заменяет блоки 1–4 и график работы отчёта reportID. Строки пишутся способом
+// диалекта (RowWriter) в одной транзакции: при ошибке в БД остаётся прежняя версия исследования.
+func (s *Store) SaveResearchBlocks(ctx context.Context, reportID int64, r models.Research) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "starting SaveResearchBlocks transaction")
+	}
+	defer tx.Rollback()
+
+	sqlStr, args, err := s.builder.
+		Select("1").
+		From(models.TableFive{}.TableName()).
+		Where(sq.Eq{"id": reportID}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building report existence query")
+	}
+	var exists bool
+	if err = tx.QueryRowContext(ctx, sqlStr, args...).Scan(&exists); err != nil {
+		return errors.Wrap(err, "checking report existence")
+	}
+	if !exists {
+		return errors.Newf("report %d not found", reportID)
+	}
+
+	if err = s.deleteBlocks(ctx, tx, reportID); err != nil {
+		return err
+	}
+
+	if err = s.writeRows(ctx, tx, models.TableOne{}.TableName(), models.TableOne{}.Columns(), reportID, len(r.One),
+		func(i int) []any {
+			t := r.One[i]
+			return []any{t.Timestamp, t.PressureDepth, t.TemperatureDepth}
+		}); err != nil {
+		return err
+	}
+	if err = s.writeRows(ctx, tx, models.TableTwo{}.TableName(), models.TableTwo{}.Columns(), reportID, len(r.Two),
+		func(i int) []any {
+			t := r.Two[i]
+			return []any{t.TimestampTubing, t.PressureTubing, t.TimestampAnnulus, t.PressureAnnulus,
+				t.TimestampLinear, t.PressureLinear}
+		}); err != nil {
+		return err
+	}
+	if err = s.writeRows(ctx, tx, models.TableThree{}.TableName(), models.TableThree{}.Columns(), reportID, len(r.Three),
+		func(i int) []any {
+			t := r.Three[i]
+			return []any{t.Timestamp, t.LiquidFlowRate, t.WaterCut, t.GasFlowRate,
+				t.OilFlowRate, t.WaterFlowRate, t.GasFactor}
+		}); err != nil {
+		return err
+	}
+	if err = s.writeRows(ctx, tx, models.TableFour{}.TableName(), models.TableFour{}.Columns(), reportID, len(r.Four),
+		func(i int) []any {
+			t := r.Four[i]
+			return []any{t.ResearchID, t.MeasuredDepth, t.TrueVerticalDepth, t.TrueVerticalDepthSubSea,
+				t.Inclination, t.Azimuth, t.Northing, t.Easting, t.DoglegSeverity}
+		}); err != nil {
+		return err
+	}
+	if err = s.saveOperation(ctx, tx, reportID, r.Operation); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "committing SaveResearchBlocks transaction")
+	}
+	return nil
+}
+
+// GetResearch возвращает отчёт reportID вместе с блоками 1–4 в порядке сохранения и графиком работы.
+func (s *Store) GetResearch(ctx context.Context, reportID int64) (models.Research, error) {
+	var r models.Research
+
+	sqlStr, args, err := s.builder.
+		Select(models.TableFive{}.Columns()...).
+		From(models.TableFive{}.TableName()).
+		Where(sq.Eq{"id": reportID}).
+		ToSql()
+	if err != nil {
+		return r, errors.Wrap(err, "building GetResearch report query")
+	}
+	if err = s.DB.GetContext(ctx, &r.Report, sqlStr, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r, errors.Newf("report %d not found", reportID)
+		}
+		return r, errors.Wrap(err, "executing GetResearch report query")
+	}
+
+	if err = selectBlock(ctx, s, &r.One, models.TableOne{}.TableName(), models.TableOne{}.Columns(), reportID); err != nil {
+		return r, err
+	}
+	if err = selectBlock(ctx, s, &r.Two, models.TableTwo{}.TableName(), models.TableTwo{}.Columns(), reportID); err != nil {
+		return r, err
+	}
+	if err = selectBlock(ctx, s, &r.Three, models.TableThree{}.TableName(), models.TableThree{}.Columns(), reportID); err != nil {
+		return r, err
+	}
+	if err = selectBlock(ctx, s, &r.Four, models.TableFour{}.TableName(), models.TableFour{}.Columns(), reportID); err != nil {
+		return r, err
+	}
+	if r.Operation, err = s.getOperation(ctx, reportID); err != nil {
+		return r, err
+	}
+
+	// метки времени импортируются в UTC, а драйвер PostgreSQL возвращает их в поясе сессии
+	for i := range r.One {
+		r.One[i].Timestamp = r.One[i].Timestamp.UTC()
+	}
+	for i := range r.Two {
+		t := &r.Two[i]
+		t.TimestampTubing, t.TimestampAnnulus, t.TimestampLinear = t.TimestampTubing.UTC(), t.TimestampAnnulus.UTC(), t.TimestampLinear.UTC()
+	}
+	for i := range r.Three {
+		r.Three[i].Timestamp = r.Three[i].Timestamp.UTC()
+	}
+	return r, nil
+}
+
+// DeleteResearch удаляет отчёт reportID; строки блоков удаляются каскадно.
+func (s *Store) DeleteResearch(ctx context.Context, reportID int64) error {
+	sqlStr, args, err := s.builder.
+		Delete(models.TableFive{}.TableName()).
+		Where(sq.Eq{"id": reportID}).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building DeleteResearch query")
+	}
+	res, err := s.DB.ExecContext(ctx, sqlStr, args...)
+	if err != nil {
+		return errors.Wrap(err, "executing DeleteResearch query")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.Newf("report %d not found", reportID)
+	}
+	return nil
+}
+
+// deleteBlocks удаляет строки блоков 1–4 и график работы отчёта reportID.
+func (s *Store) deleteBlocks(ctx context.Context, tx *sqlx.Tx, reportID int64) error {
+	for _, table := range []string{
+		models.TableOne{}.TableName(),
+		models.TableTwo{}.TableName(),
+		models.TableThree{}.TableName(),
+		models.TableFour{}.TableName(),
+		models.OperationPeriod{}.TableName(),
+		models.OperationConfig{}.TableName(),
+	} {
+		sqlStr, args, err := s.builder.Delete(table).Where(sq.Eq{reportIDColumn: reportID}).ToSql()
+		if err != nil {
+			return errors.Wrapf(err, "building delete query for %s", table)
+		}
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return errors.Wrapf(err, "deleting rows of %s", table)
+		}
+	}
+	return nil
+}
+
+// writeRows пишет n строк в table через RowWriter диалекта. row(i) возвращает значения колонок columns
+// i-й строки; report_id и seq добавляются здесь.
+func (s *Store) writeRows(ctx context.Context, tx *sqlx.Tx, table string, columns []string, reportID int64, n int, row func(i int) []any) error {
+	if n == 0 {
+		return nil
+	}
+	return s.rows(ctx, tx, table, append([]string{reportIDColumn, seqColumn}, columns...), n,
+		func(i int) []any { return append([]any{reportID, i}, row(i)...) })
+}
+
+// selectBlock читает строки блока отчёта reportID в порядке seq.
+func selectBlock[T any](ctx context.Context, s *Store, dst *[]T, table string, columns []string, reportID int64) error {
+	sqlStr, args, err := s.builder.
+		Select(columns...).
+		From(table).
+		Where(sq.Eq{reportIDColumn: reportID}).
+		OrderBy(seqColumn).
+		ToSql()
+	if err != nil {
+		return errors.Wrapf(err, "building select query for %s", table)
+	}
+	if err = s.DB.SelectContext(ctx, dst, sqlStr, args...); err != nil {
+		return errors.Wrapf(err, "selecting rows of %s", table)
+	}
+	return nil
+}
+
+// saveOperation записывает параметры гидростатики и периоды графика работы отчёта reportID; nil — не задан.
+func (s *Store) saveOperation(ctx context.Context, tx *sqlx.Tx, reportID int64, cfg *models.OperationConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	sqlStr, args, err := s.builder.
+		Insert(models.OperationConfig{}.TableName()).
+		Columns(append([]string{reportIDColumn}, models.OperationConfig{}.Columns()...)...).
+		Values(reportID, cfg.DepthDiff, cfg.GapPolicy).
+		ToSql()
+	if err != nil {
+		return errors.Wrap(err, "building operation config insert query")
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return errors.Wrap(err, "inserting operation config")
+	}
+	return s.writeRows(ctx, tx, models.OperationPeriod{}.TableName(), models.OperationPeriod{}.Columns(), reportID, len(cfg.Periods),
+		func(i int) []any {
+			p := cfg.Periods[i]
+			return []any{p.Kind, p.Label, p.Start, p.End, p.Density}
+		})
+}
+
+// getOperation читает график работы отчёта reportID; nil, если он не был сохранён.
+func (s *Store) getOperation(ctx context.Context, reportID int64) (*models.OperationConfig, error) {
+	sqlStr, args, err := s.builder.
+		Select(models.OperationConfig{}.Columns()...).
+		From(models.OperationConfig{}.TableName()).
+		Where(sq.Eq{reportIDColumn: reportID}).
+		ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "building operation config query")
+	}
+	var cfg models.OperationConfig
+	if err = s.DB.GetContext(ctx, &cfg, sqlStr, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "selecting operation config")
+	}
+	if err = selectBlock(ctx, s, &cfg.Periods, models.OperationPeriod{}.TableName(), models.OperationPeriod{}.Columns(), reportID); err != nil {
+		return nil, err
+	}
+	for i := range cfg.Periods {
+		cfg.Periods[i].Start, cfg.Periods[i].End = cfg.Periods[i].Start.UTC(), cfg.Periods[i].End.UTC()
+	}
+	return &cfg, nil
+}
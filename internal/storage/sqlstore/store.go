@@ -0,0 +1,35 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// RowWriter пишет n строк в table в транзакции tx: так, как быстрее в диалекте БД
+// (COPY в PostgreSQL, подготовленный INSERT в SQLite). row(i) возвращает значения колонок columns i-й строки.
+type RowWriter func(ctx context.Context, tx *sqlx.Tx, table string, columns []string, n int, row func(i int) []any) error
+
+// Store — хранилище отчётов, справочников и блоков исследований поверх sqlx, общее для PostgreSQL и SQLite.
+// Диалекты различаются форматом плейсхолдеров squirrel и способом массовой записи строк.
+type Store struct {
+	DB      *sqlx.DB
+	builder sq.StatementBuilderType
+	rows    RowWriter
+}
+
+// New возвращает хранилище для подключения db с плейсхолдерами placeholder и массовой записью rows.
+func New(db *sqlx.DB, placeholder sq.PlaceholderFormat, rows RowWriter) *Store {
+	return &Store{
+		DB:      db,
+		builder: sq.StatementBuilder.PlaceholderFormat(placeholder),
+		rows:    rows,
+	}
+}
+
+// GetSqlDB возвращает соединение для миграций.
+func (s *Store) GetSqlDB() *sql.DB {
+	return s.DB.DB
+}
@@ -0,0 +1,5 @@
+-- +goose Up
+-- Раньше здесь в справочники вставлялись тестовые значения ('Test Field A', 'Horizon Alpha' и т. п.),
+-- и они попадали в рабочие базы. Версия оставлена, чтобы не менялась нумерация миграций;
+-- уже вставленные значения удаляет 20250701120000_remove_test_guidebook_data.sql.
+SELECT 1;
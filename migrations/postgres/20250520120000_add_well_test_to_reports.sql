@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE reports
+    ADD COLUMN thickness              DOUBLE PRECISION,
+    ADD COLUMN porosity               DOUBLE PRECISION,
+    ADD COLUMN viscosity              DOUBLE PRECISION,
+    ADD COLUMN volume_factor          DOUBLE PRECISION,
+    ADD COLUMN total_compressibility  DOUBLE PRECISION,
+    ADD COLUMN well_radius            DOUBLE PRECISION,
+    ADD COLUMN analysis_method        TEXT,
+    ADD COLUMN extrapolated_pressure  DOUBLE PRECISION,
+    ADD COLUMN permeability_thickness DOUBLE PRECISION,
+    ADD COLUMN permeability           DOUBLE PRECISION,
+    ADD COLUMN skin                   DOUBLE PRECISION;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE reports
+    DROP COLUMN thickness,
+    DROP COLUMN porosity,
+    DROP COLUMN viscosity,
+    DROP COLUMN volume_factor,
+    DROP COLUMN total_compressibility,
+    DROP COLUMN well_radius,
+    DROP COLUMN analysis_method,
+    DROP COLUMN extrapolated_pressure,
+    DROP COLUMN permeability_thickness,
+    DROP COLUMN permeability,
+    DROP COLUMN skin;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Импортированные блоки 1–4 исследования. Строки привязаны к отчёту (reports.id) и удаляются вместе с ним;
+-- seq хранит порядок строк файла, чтобы исследование открывалось ровно таким, каким было сохранено.
+CREATE TABLE IF NOT EXISTS table_one (
+    report_id         INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq               INTEGER NOT NULL,
+    timestamp         TIMESTAMPTZ NOT NULL,
+    pressure_depth    DOUBLE PRECISION NOT NULL,
+    temperature_depth DOUBLE PRECISION NOT NULL,
+    pressure_at_vdp   DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (report_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS table_two (
+    report_id         INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq               INTEGER NOT NULL,
+    timestamp_tubing  TIMESTAMPTZ NOT NULL,
+    pressure_tubing   DOUBLE PRECISION NOT NULL,
+    timestamp_annulus TIMESTAMPTZ NOT NULL,
+    pressure_annulus  DOUBLE PRECISION NOT NULL,
+    timestamp_linear  TIMESTAMPTZ NOT NULL,
+    pressure_linear   DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (report_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS table_three (
+    report_id       INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq             INTEGER NOT NULL,
+    timestamp       TIMESTAMPTZ NOT NULL,
+    flow_liquid     DOUBLE PRECISION NOT NULL,
+    water_cut       DOUBLE PRECISION NOT NULL,
+    flow_gas        DOUBLE PRECISION NOT NULL,
+    oil_flow_rate   DOUBLE PRECISION,
+    water_flow_rate DOUBLE PRECISION,
+    gas_oil_ratio   DOUBLE PRECISION,
+    PRIMARY KEY (report_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS table_four (
+    report_id                   INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq                         INTEGER NOT NULL,
+    research_id                 UUID NOT NULL,
+    measure_depth               DOUBLE PRECISION NOT NULL,
+    true_vertical_depth         DOUBLE PRECISION NOT NULL,
+    true_vertical_depth_sub_sea DOUBLE PRECISION NOT NULL,
+    inclination                 DOUBLE PRECISION,
+    azimuth                     DOUBLE PRECISION,
+    northing                    DOUBLE PRECISION,
+    easting                     DOUBLE PRECISION,
+    dogleg_severity             DOUBLE PRECISION,
+    PRIMARY KEY (report_id, seq)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS table_four;
+DROP TABLE IF EXISTS table_three;
+DROP TABLE IF EXISTS table_two;
+DROP TABLE IF EXISTS table_one;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Удаляет тестовые значения справочников, которые вставляла 20250421011702_insert_test_data.sql.
+-- Значения, уже указанные в отчётах, остаются: их можно переименовать или объединить в справочниках.
+DELETE FROM oilfield
+WHERE name IN ('Test Field A', 'Test Field B', 'Test Field C')
+  AND NOT EXISTS (SELECT 1 FROM reports WHERE reports.field_name = oilfield.name);
+
+DELETE FROM productive_horizon
+WHERE name IN ('Horizon Alpha', 'Horizon Beta', 'Horizon Gamma')
+  AND NOT EXISTS (SELECT 1 FROM reports WHERE reports.horizon = productive_horizon.name);
+
+DELETE FROM instrument_type
+WHERE name IN ('Seismograph', 'Well Log', 'Core Sampler')
+  AND NOT EXISTS (SELECT 1 FROM reports WHERE reports.instrument_type = instrument_type.name);
+
+DELETE FROM research_type
+WHERE name IN ('Geological Survey', 'Geophysical Analysis', 'Reservoir Simulation')
+  AND NOT EXISTS (SELECT 1 FROM reports WHERE reports.research_type = research_type.name);
+
+-- +goose Down
+-- Тестовые значения не возвращаются.
+SELECT 1;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Рзаб на ВДП не хранится: он пересчитывается по графику работы и гидростатике, которые сохраняются
+-- вместе с исследованием. Прежняя колонка всегда содержала 0 вместо расчётного значения.
+ALTER TABLE table_one DROP COLUMN IF EXISTS pressure_at_vdp;
+
+CREATE TABLE IF NOT EXISTS operation_config (
+    report_id  INTEGER PRIMARY KEY REFERENCES reports (id) ON DELETE CASCADE,
+    depth_diff DOUBLE PRECISION NOT NULL,
+    gap_policy TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS operation_periods (
+    report_id  INTEGER NOT NULL REFERENCES operation_config (report_id) ON DELETE CASCADE,
+    seq        INTEGER NOT NULL,
+    kind       TEXT NOT NULL,
+    label      TEXT NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time   TIMESTAMPTZ NOT NULL,
+    density    DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (report_id, seq)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS operation_periods;
+DROP TABLE IF EXISTS operation_config;
+ALTER TABLE table_one ADD COLUMN IF NOT EXISTS pressure_at_vdp DOUBLE PRECISION NOT NULL DEFAULT 0;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS reports (
+    id                          INTEGER PRIMARY KEY AUTOINCREMENT,
+    field_name                  TEXT    NOT NULL,
+    field_number                INTEGER NOT NULL,
+    cluster_number              INTEGER NOT NULL,
+    horizon                     TEXT    NOT NULL,
+    start_time                  TIMESTAMP NOT NULL,
+    end_time                    TIMESTAMP NOT NULL,
+    instrument_type             TEXT    NOT NULL,
+    instrument_number           INTEGER NOT NULL,
+    measure_depth               REAL NOT NULL,
+    true_vertical_depth         REAL,
+    true_vertical_depth_sub_sea REAL,
+    vdp_measured_depth          REAL NOT NULL,
+    vdp_true_vertical_depth     REAL,
+    vdp_true_vertical_depth_sea REAL,
+    diff_instrument_vdp         REAL,
+    density_oil                 REAL NOT NULL,
+    density_liquid_stopped      REAL NOT NULL,
+    density_liquid_working      REAL NOT NULL,
+    pressure_diff_stopped       REAL,
+    pressure_diff_working       REAL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS reports;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS archive_info (
+    object_name TEXT PRIMARY KEY,
+    bucket_name TEXT NOT NULL,
+    size        INTEGER NOT NULL,
+    etag        TEXT,
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS archive_info;
+-- +goose StatementEnd
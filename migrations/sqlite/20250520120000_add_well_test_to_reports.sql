@@ -0,0 +1,26 @@
+-- +goose Up
+-- SQLite добавляет по одной колонке за ALTER TABLE
+ALTER TABLE reports ADD COLUMN thickness REAL;
+ALTER TABLE reports ADD COLUMN porosity REAL;
+ALTER TABLE reports ADD COLUMN viscosity REAL;
+ALTER TABLE reports ADD COLUMN volume_factor REAL;
+ALTER TABLE reports ADD COLUMN total_compressibility REAL;
+ALTER TABLE reports ADD COLUMN well_radius REAL;
+ALTER TABLE reports ADD COLUMN analysis_method TEXT;
+ALTER TABLE reports ADD COLUMN extrapolated_pressure REAL;
+ALTER TABLE reports ADD COLUMN permeability_thickness REAL;
+ALTER TABLE reports ADD COLUMN permeability REAL;
+ALTER TABLE reports ADD COLUMN skin REAL;
+
+-- +goose Down
+ALTER TABLE reports DROP COLUMN thickness;
+ALTER TABLE reports DROP COLUMN porosity;
+ALTER TABLE reports DROP COLUMN viscosity;
+ALTER TABLE reports DROP COLUMN volume_factor;
+ALTER TABLE reports DROP COLUMN total_compressibility;
+ALTER TABLE reports DROP COLUMN well_radius;
+ALTER TABLE reports DROP COLUMN analysis_method;
+ALTER TABLE reports DROP COLUMN extrapolated_pressure;
+ALTER TABLE reports DROP COLUMN permeability_thickness;
+ALTER TABLE reports DROP COLUMN permeability;
+ALTER TABLE reports DROP COLUMN skin;
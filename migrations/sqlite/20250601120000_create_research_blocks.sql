@@ -0,0 +1,58 @@
+-- +goose Up
+-- Импортированные блоки 1–4 исследования. Строки привязаны к отчёту (reports.id) и удаляются вместе с ним;
+-- seq хранит порядок строк файла, чтобы исследование открывалось ровно таким, каким было сохранено.
+CREATE TABLE IF NOT EXISTS table_one (
+    report_id         INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq               INTEGER NOT NULL,
+    timestamp         TIMESTAMP NOT NULL,
+    pressure_depth    REAL NOT NULL,
+    temperature_depth REAL NOT NULL,
+    pressure_at_vdp   REAL NOT NULL,
+    PRIMARY KEY (report_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS table_two (
+    report_id         INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq               INTEGER NOT NULL,
+    timestamp_tubing  TIMESTAMP NOT NULL,
+    pressure_tubing   REAL NOT NULL,
+    timestamp_annulus TIMESTAMP NOT NULL,
+    pressure_annulus  REAL NOT NULL,
+    timestamp_linear  TIMESTAMP NOT NULL,
+    pressure_linear   REAL NOT NULL,
+    PRIMARY KEY (report_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS table_three (
+    report_id       INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq             INTEGER NOT NULL,
+    timestamp       TIMESTAMP NOT NULL,
+    flow_liquid     REAL NOT NULL,
+    water_cut       REAL NOT NULL,
+    flow_gas        REAL NOT NULL,
+    oil_flow_rate   REAL,
+    water_flow_rate REAL,
+    gas_oil_ratio   REAL,
+    PRIMARY KEY (report_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS table_four (
+    report_id                   INTEGER NOT NULL REFERENCES reports (id) ON DELETE CASCADE,
+    seq                         INTEGER NOT NULL,
+    research_id                 TEXT NOT NULL,
+    measure_depth               REAL NOT NULL,
+    true_vertical_depth         REAL NOT NULL,
+    true_vertical_depth_sub_sea REAL NOT NULL,
+    inclination                 REAL,
+    azimuth                     REAL,
+    northing                    REAL,
+    easting                     REAL,
+    dogleg_severity             REAL,
+    PRIMARY KEY (report_id, seq)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS table_four;
+DROP TABLE IF EXISTS table_three;
+DROP TABLE IF EXISTS table_two;
+DROP TABLE IF EXISTS table_one;
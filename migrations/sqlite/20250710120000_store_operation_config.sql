@@ -0,0 +1,26 @@
+-- +goose Up
+-- Рзаб на ВДП не хранится: он пересчитывается по графику работы и гидростатике, которые сохраняются
+-- вместе с исследованием. Прежняя колонка всегда содержала 0 вместо расчётного значения.
+ALTER TABLE table_one DROP COLUMN pressure_at_vdp;
+
+CREATE TABLE IF NOT EXISTS operation_config (
+    report_id  INTEGER PRIMARY KEY REFERENCES reports (id) ON DELETE CASCADE,
+    depth_diff REAL NOT NULL,
+    gap_policy TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS operation_periods (
+    report_id  INTEGER NOT NULL REFERENCES operation_config (report_id) ON DELETE CASCADE,
+    seq        INTEGER NOT NULL,
+    kind       TEXT NOT NULL,
+    label      TEXT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time   TIMESTAMP NOT NULL,
+    density    REAL NOT NULL,
+    PRIMARY KEY (report_id, seq)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS operation_periods;
+DROP TABLE IF EXISTS operation_config;
+ALTER TABLE table_one ADD COLUMN pressure_at_vdp REAL NOT NULL DEFAULT 0;